@@ -1,12 +1,17 @@
 package main
 
 import (
+	"log"
+	"time"
+
 	"koperasi-service/config"
 	"koperasi-service/internal/handler"
 	"koperasi-service/internal/middleware"
 	"koperasi-service/internal/model"
 	"koperasi-service/internal/repository"
+	"koperasi-service/internal/scheduler"
 	"koperasi-service/internal/service"
+	"koperasi-service/internal/storage"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -22,8 +27,19 @@ func main() {
 		panic("failed to connect db")
 	}
 
+	// Recreate the loan status check so newly added statuses are accepted
+	if db.Migrator().HasConstraint(&model.Pinjaman{}, "chk_pinjaman_status") {
+		db.Migrator().DropConstraint(&model.Pinjaman{}, "chk_pinjaman_status")
+	}
+
+	// The wallet type code is unique among types that are not deleted; drop
+	// the index that also counted deleted ones
+	if db.Migrator().HasIndex(&model.JenisSimpanan{}, "idx_jenis_simpanan_kode") {
+		db.Migrator().DropIndex(&model.JenisSimpanan{}, "idx_jenis_simpanan_kode")
+	}
+
 	// Auto migrate
-	db.AutoMigrate(&model.User{}, &model.Role{}, &model.Simpanan{}, &model.SimpananTransaction{}, &model.Pinjaman{}, &model.Angsuran{}, &model.SHUTahunan{}, &model.SHUAnggotaRecord{})
+	db.AutoMigrate(&model.User{}, &model.Role{}, &model.Simpanan{}, &model.SimpananTransaction{}, &model.Pinjaman{}, &model.Angsuran{}, &model.SHUTahunan{}, &model.SHUAnggotaRecord{}, &model.LedgerJournal{}, &model.LedgerEntry{}, &model.BungaOption{}, &model.BungaOptionVersi{}, &model.JadwalAngsuran{}, &model.AlokasiAngsuran{}, &model.AturanDenda{}, &model.RiwayatKolektibilitas{}, &model.AturanKelayakan{}, &model.AturanPelunasan{}, &model.Restrukturisasi{}, &model.JenisPinjaman{}, &model.Pencairan{}, &model.TransactionHistory{}, &model.Penjamin{}, &model.Agunan{}, &model.MandatAutoDebet{}, &model.AutoDebet{}, &model.Notifikasi{}, &model.PenarikanSimpanan{}, &model.AturanSimpananWajib{}, &model.KewajibanWajib{}, &model.AturanSimpananPokok{}, &model.AturanJasaSimpanan{}, &model.JasaSimpanan{}, &model.JenisSimpananBerjangka{}, &model.SimpananBerjangka{}, &model.AturanTransfer{}, &model.TransferSukarela{}, &model.JenisSimpanan{}, &model.Berkas{})
 
 	// Seed roles
 	seedRoles(db)
@@ -31,24 +47,122 @@ func main() {
 	// Setup dependencies
 	userRepo := repository.NewUserRepository(db)
 	simpananRepo := repository.NewSimpananRepository(db)
+	uow := repository.NewUnitOfWork(db)
 
-	authService := service.NewAuthService(userRepo, simpananRepo)
+	authService := service.NewAuthService(userRepo, uow)
 	authHandler := handler.NewAuthHandler(authService, cfg)
 
 	// Additional services
-	userService := service.NewUserService(userRepo, simpananRepo)
+	userService := service.NewUserService(userRepo, uow)
 	userHandler := handler.NewUserHandler(userService)
 
+	// Bunga Option dependencies
+	bungaOptionRepo := repository.NewBungaOptionRepository(db)
+	bungaOptionSvc := service.NewBungaOptionService(bungaOptionRepo, userRepo)
+	bungaOptionHdl := handler.NewBungaOptionHandler(bungaOptionSvc)
+
+	// Options created before rates were versioned get their first version
+	if _, err := bungaOptionSvc.BackfillVersi(); err != nil {
+		log.Println("failed to backfill bunga option versions:", err)
+	}
+
+	// Aturan Denda dependencies
+	aturanDendaRepo := repository.NewAturanRepository[model.AturanDenda](db)
+	aturanDendaSvc := service.NewAturanDendaService(aturanDendaRepo, userRepo)
+	aturanDendaHdl := handler.NewAturanDendaHandler(aturanDendaSvc)
+
+	// Aturan Kelayakan dependencies
+	aturanKelayakanRepo := repository.NewAturanRepository[model.AturanKelayakan](db)
+	aturanKelayakanSvc := service.NewAturanKelayakanService(aturanKelayakanRepo, userRepo)
+	aturanKelayakanHdl := handler.NewAturanKelayakanHandler(aturanKelayakanSvc)
+
+	// Aturan Simpanan Wajib dependencies
+	aturanSimpananWajibRepo := repository.NewAturanRepository[model.AturanSimpananWajib](db)
+	aturanSimpananWajibSvc := service.NewAturanSimpananWajibService(aturanSimpananWajibRepo, userRepo)
+	aturanSimpananWajibHdl := handler.NewAturanSimpananWajibHandler(aturanSimpananWajibSvc)
+
+	// Aturan Simpanan Pokok dependencies
+	aturanSimpananPokokRepo := repository.NewAturanRepository[model.AturanSimpananPokok](db)
+	aturanSimpananPokokSvc := service.NewAturanSimpananPokokService(aturanSimpananPokokRepo, userRepo)
+	aturanSimpananPokokHdl := handler.NewAturanSimpananPokokHandler(aturanSimpananPokokSvc)
+
+	// Aturan Transfer dependencies
+	aturanTransferRepo := repository.NewAturanRepository[model.AturanTransfer](db)
+	aturanTransferSvc := service.NewAturanTransferService(aturanTransferRepo, userRepo)
+	aturanTransferHdl := handler.NewAturanTransferHandler(aturanTransferSvc)
+
+	// Aturan Jasa Simpanan dependencies
+	aturanJasaSimpananRepo := repository.NewAturanRepository[model.AturanJasaSimpanan](db)
+	aturanJasaSimpananSvc := service.NewAturanJasaSimpananService(aturanJasaSimpananRepo, userRepo)
+	aturanJasaSimpananHdl := handler.NewAturanJasaSimpananHandler(aturanJasaSimpananSvc)
+
+	// Aturan Pelunasan dependencies
+	aturanPelunasanRepo := repository.NewAturanRepository[model.AturanPelunasan](db)
+	aturanPelunasanSvc := service.NewAturanPelunasanService(aturanPelunasanRepo, userRepo)
+	aturanPelunasanHdl := handler.NewAturanPelunasanHandler(aturanPelunasanSvc)
+
+	// Jenis Pinjaman (loan product) dependencies
+	jenisPinjamanRepo := repository.NewJenisPinjamanRepository(db)
+	jenisPinjamanSvc := service.NewJenisPinjamanService(jenisPinjamanRepo, bungaOptionRepo, aturanKelayakanRepo, userRepo)
+	jenisPinjamanHdl := handler.NewJenisPinjamanHandler(jenisPinjamanSvc)
+
+	// Jenis Simpanan Berjangka (time-deposit product) dependencies
+	jenisBerjangkaRepo := repository.NewJenisSimpananBerjangkaRepository(db)
+	jenisBerjangkaSvc := service.NewJenisSimpananBerjangkaService(jenisBerjangkaRepo, userRepo)
+	jenisBerjangkaHdl := handler.NewJenisSimpananBerjangkaHandler(jenisBerjangkaSvc)
+
+	// Jenis Simpanan (wallet type) dependencies
+	jenisSimpananRepo := repository.NewJenisSimpananRepository(db)
+	jenisSimpananSvc := service.NewJenisSimpananService(jenisSimpananRepo, simpananRepo, userRepo)
+	jenisSimpananHdl := handler.NewJenisSimpananHandler(jenisSimpananSvc)
+
+	// The built-in wallet types must exist before wallets are created
+	if err := jenisSimpananRepo.SeedBawaan(); err != nil {
+		log.Println("failed to seed jenis simpanan:", err)
+	}
+	if _, err := jenisSimpananSvc.BackfillWallets(); err != nil {
+		log.Println("failed to backfill simpanan wallets:", err)
+	}
+
 	// Pinjaman dependencies
 	pinjamanRepo := repository.NewPinjamanRepository(db)
-	pinjamanSvc := service.NewPinjamanService(pinjamanRepo, userRepo)
+	jadwalRepo := repository.NewJadwalAngsuranRepository(db)
+	kolektibilitasRepo := repository.NewKolektibilitasRepository(db)
+	restrukturisasiRepo := repository.NewRestrukturisasiRepository(db)
+	pencairanRepo := repository.NewPencairanRepository(db)
+	penjaminRepo := repository.NewPenjaminRepository(db)
+	agunanRepo := repository.NewAgunanRepository(db)
+	autoDebetRepo := repository.NewAutoDebetRepository(db)
+	pinjamanSvc := service.NewPinjamanService(pinjamanRepo, userRepo, bungaOptionRepo, jadwalRepo, aturanDendaRepo, kolektibilitasRepo, aturanKelayakanRepo, aturanPelunasanRepo, restrukturisasiRepo, jenisPinjamanRepo, pencairanRepo, penjaminRepo, agunanRepo, autoDebetRepo, uow)
 	pinjamanHdl := handler.NewPinjamanHandler(pinjamanSvc)
 
+	// Notifikasi dependencies
+	notifikasiRepo := repository.NewNotifikasiRepository(db)
+	notifikasiSvc := service.NewNotifikasiService(notifikasiRepo)
+	notifikasiHdl := handler.NewNotifikasiHandler(notifikasiSvc)
+
 	// Angsuran dependencies
 	angsuranRepo := repository.NewAngsuranRepository(db)
-	angsuranSvc := service.NewAngsuranService(angsuranRepo, pinjamanRepo, userRepo)
+	angsuranSvc := service.NewAngsuranService(angsuranRepo, pinjamanRepo, userRepo, jadwalRepo, aturanDendaRepo, uow)
 	angsuranHdl := handler.NewAngsuranHandler(angsuranSvc)
 
+	// Berkas (proof-of-transfer upload) dependencies
+	store, err := storage.New(storage.Config{
+		Driver:    cfg.StorageDriver,
+		Dir:       cfg.StorageDir,
+		Endpoint:  cfg.S3Endpoint,
+		Region:    cfg.S3Region,
+		Bucket:    cfg.S3Bucket,
+		AccessKey: cfg.S3AccessKey,
+		SecretKey: cfg.S3SecretKey,
+	})
+	if err != nil {
+		panic("failed to set up file storage: " + err.Error())
+	}
+	berkasRepo := repository.NewBerkasRepository(db)
+	berkasSvc := service.NewBerkasService(berkasRepo, uow, store, cfg.UploadMaxBytes)
+	berkasHdl := handler.NewBerkasHandler(berkasSvc)
+
 	// SHU dependencies
 	shuRepo := repository.NewSHUTahunanRepository(db)
 	shuSvc := service.NewSHUService(shuRepo)
@@ -56,14 +170,9 @@ func main() {
 
 	// SHU Anggota dependencies
 	shuAnggotaRepo := repository.NewSHUAnggotaRepository(db)
-	shuAnggotaSvc := service.NewSHUAnggotaService(shuAnggotaRepo, shuRepo)
+	shuAnggotaSvc := service.NewSHUAnggotaService(shuAnggotaRepo, shuRepo, uow)
 	shuAnggotaHdl := handler.NewSHUAnggotaHandler(shuAnggotaSvc)
 
-	// Bunga Option dependencies
-	bungaOptionRepo := repository.NewBungaOptionRepository(db)
-	bungaOptionSvc := service.NewBungaOptionService(bungaOptionRepo, userRepo)
-	bungaOptionHdl := handler.NewBungaOptionHandler(bungaOptionSvc)
-
 	// Audit Trail and Transaction History dependencies
 	auditRepo := repository.NewAuditTrailRepository(db)
 	transactionRepo := repository.NewTransactionHistoryRepository(db)
@@ -90,9 +199,59 @@ func main() {
 	r.POST("/api/forgot-password", authHandler.ForgotPassword)
 
 	// Simpanan dependencies
-	simpananSvc := service.NewSimpananService(simpananRepo)
+	ledgerRepo := repository.NewLedgerRepository(db)
+	penarikanRepo := repository.NewPenarikanRepository(db)
+	kewajibanWajibRepo := repository.NewKewajibanWajibRepository(db)
+	jasaSimpananRepo := repository.NewJasaSimpananRepository(db)
+	berjangkaRepo := repository.NewSimpananBerjangkaRepository(db)
+	transferRepo := repository.NewTransferSukarelaRepository(db)
+	simpananSvc := service.NewSimpananService(simpananRepo, ledgerRepo, penarikanRepo, kewajibanWajibRepo, jasaSimpananRepo, berjangkaRepo, transferRepo, aturanSimpananWajibRepo, aturanSimpananPokokRepo, aturanJasaSimpananRepo, jenisBerjangkaRepo, aturanTransferRepo, jenisSimpananRepo, userRepo, uow)
 	simpananHdl := handler.NewSimpananHandler(simpananSvc)
 
+	// Carry balances that predate the ledger into it
+	if err := simpananSvc.PostOpeningBalances(); err != nil {
+		log.Println("failed to post opening balances:", err)
+	}
+
+	// Daily background jobs
+	jobs := scheduler.New()
+	// Creates the month's wajib obligations on the 1st; later runs only pick up new members
+	jobs.Daily("kewajiban-wajib", 0, 15, func(now time.Time) error {
+		dibuat, err := simpananSvc.BuatKewajibanWajib(now)
+		log.Printf("kewajiban-wajib: %d obligations created", dibuat)
+		return err
+	})
+	// Credits last month's jasa on the 1st; later runs only pick up wallets that failed
+	jobs.Daily("jasa-simpanan", 0, 20, func(now time.Time) error {
+		dikredit, err := simpananSvc.PostJasaSimpanan(now)
+		log.Printf("jasa-simpanan: %d wallets credited", dikredit)
+		return err
+	})
+	// Settles deposits maturing today: interest, then payout or rollover
+	jobs.Daily("simpanan-berjangka", 0, 25, func(now time.Time) error {
+		selesai, err := simpananSvc.JatuhTempoBerjangka(now)
+		log.Printf("simpanan-berjangka: %d deposits settled", selesai)
+		return err
+	})
+	jobs.Daily("kolektibilitas", 0, 30, func(now time.Time) error {
+		changed, err := pinjamanSvc.KlasifikasiKolektibilitas(now)
+		log.Printf("kolektibilitas: %d loans changed bucket", changed)
+		return err
+	})
+	// Removes uploads nobody attached to a payment within a week
+	jobs.Daily("berkas-lepas", 1, 0, func(now time.Time) error {
+		dihapus, err := berkasSvc.HapusBerkasLepas(now)
+		log.Printf("berkas-lepas: %d unattached uploads removed", dihapus)
+		return err
+	})
+	jobs.Daily("auto-debet", 6, 0, func(now time.Time) error {
+		berhasil, gagal, err := pinjamanSvc.JalankanAutoDebet(now)
+		log.Printf("auto-debet: %d debited, %d insufficient balance", berhasil, gagal)
+		return err
+	})
+	jobs.Start()
+	defer jobs.Stop()
+
 	// Protected
 	protected := r.Group("/api")
 	protected.Use(middleware.AuthMiddleware(cfg, userRepo))
@@ -108,6 +267,35 @@ func main() {
 		protected.PUT("/simpanan/:id/adjust", simpananHdl.AdjustWallet)                     // Admin adjust wallet balance
 		protected.GET("/simpanan/transactions/pending", simpananHdl.GetPendingTransactions) // Get pending transactions (admin)
 		protected.PUT("/simpanan/transactions/:id/verify", simpananHdl.VerifyTransaction)   // Verify transaction (admin)
+		protected.GET("/simpanan/:id/balance", simpananHdl.GetWalletBalance)                // Ledger balance (?as_of=YYYY-MM-DD)
+		protected.GET("/simpanan/:id/ledger", simpananHdl.GetWalletLedger)                  // Ledger entries of a wallet
+		protected.POST("/simpanan/ledger/:id/reverse", simpananHdl.ReverseJournal)          // Reverse a ledger journal (admin)
+		protected.POST("/simpanan/penarikan", simpananHdl.AjukanPenarikan)                  // Request a sukarela withdrawal
+		protected.GET("/simpanan/penarikan", simpananHdl.ListPenarikan)                     // List withdrawals (?status=)
+		protected.GET("/simpanan/penarikan/:id", simpananHdl.GetPenarikan)                  // Get a withdrawal
+		protected.PUT("/simpanan/penarikan/:id/approve", simpananHdl.SetujuiPenarikan)      // Approve a withdrawal (admin)
+		protected.PUT("/simpanan/penarikan/:id/reject", simpananHdl.TolakPenarikan)         // Reject a withdrawal (admin)
+		protected.PUT("/simpanan/penarikan/:id/pay", simpananHdl.BayarPenarikan)            // Record the transfer of a withdrawal (admin)
+		protected.GET("/simpanan/wajib/kewajiban", simpananHdl.GetKewajibanWajib)           // Monthly wajib obligations (?user_id= for admin)
+		protected.GET("/simpanan/wajib/tunggakan", simpananHdl.GetTunggakanWajib)           // Overdue wajib of a member (?user_id= for admin)
+		protected.GET("/simpanan/wajib/rekap", simpananHdl.GetRekapTunggakanWajib)          // Overdue wajib of all members (admin)
+		protected.POST("/simpanan/wajib/run", simpananHdl.RunKewajibanWajib)                // Create this month's obligations now (admin)
+		protected.GET("/simpanan/jasa", simpananHdl.ListJasaSimpanan)                       // Posted jasa simpanan (?user_id= for admin, ?periode=)
+		protected.GET("/simpanan/jasa/preview", simpananHdl.PreviewJasaSimpanan)            // Dry run of a month's jasa (admin, ?periode=YYYY-MM)
+		protected.POST("/simpanan/jasa/run", simpananHdl.RunJasaSimpanan)                   // Post an ended month's jasa now (admin)
+		protected.POST("/simpanan/berjangka", simpananHdl.BukaBerjangka)                    // Open a time deposit
+		protected.GET("/simpanan/berjangka", simpananHdl.ListBerjangka)                     // List time deposits (?user_id= for admin, ?status=)
+		protected.GET("/simpanan/berjangka/:id", simpananHdl.GetBerjangka)                  // Get a time deposit
+		protected.PUT("/simpanan/berjangka/:id", simpananHdl.UbahInstruksiBerjangka)        // Change the maturity instruction
+		protected.PUT("/simpanan/berjangka/:id/cairkan", simpananHdl.CairkanDiniBerjangka)  // Break a time deposit early (penalty applies)
+		protected.POST("/simpanan/berjangka/run", simpananHdl.RunJatuhTempoBerjangka)       // Settle matured deposits now (admin)
+		protected.POST("/simpanan/transfer", simpananHdl.BuatTransfer)                      // Create a sukarela transfer to another member
+		protected.GET("/simpanan/transfer", simpananHdl.ListTransfer)                       // List transfers sent or received (?user_id= for admin, ?status=)
+		protected.GET("/simpanan/transfer/:id", simpananHdl.GetTransfer)                    // Get a transfer
+		protected.PUT("/simpanan/transfer/:id/confirm", simpananHdl.KonfirmasiTransfer)     // Confirm and send a transfer
+		protected.PUT("/simpanan/transfer/:id/cancel", simpananHdl.BatalkanTransfer)        // Cancel a transfer not sent yet
+		protected.PUT("/simpanan/transfer/:id/approve", simpananHdl.SetujuiTransfer)        // Approve a transfer above the threshold (admin)
+		protected.PUT("/simpanan/transfer/:id/reject", simpananHdl.TolakTransfer)           // Reject a transfer above the threshold (admin)
 
 		// User CRUD
 		protected.GET("/users", userHandler.List)
@@ -115,13 +303,53 @@ func main() {
 		protected.POST("/users", userHandler.Create)
 		protected.PUT("/users/:id", userHandler.Update)
 		protected.DELETE("/users/:id", userHandler.Delete)
+		protected.POST("/users/:id/keluar", simpananHdl.KeluarAnggota) // Member resignation, refunds simpanan (admin)
 
 		// Pinjaman CRUD
 		protected.GET("/pinjaman", pinjamanHdl.List)
+		protected.POST("/pinjaman/kelayakan", pinjamanHdl.Kelayakan)                               // Eligibility pre-check, nothing is saved
+		protected.GET("/pinjaman/aging", pinjamanHdl.Aging)                                        // Aging report by kolektibilitas (admin)
+		protected.POST("/pinjaman/kolektibilitas/run", pinjamanHdl.RunKolektibilitas)              // Run classification now (super admin)
+		protected.POST("/pinjaman/auto-debet/run", pinjamanHdl.RunAutoDebet)                       // Run the auto-debit job now (super admin)
+		protected.GET("/pinjaman/restrukturisasi", pinjamanHdl.PendingRestrukturisasi)             // Restructuring requests awaiting a decision (admin)
+		protected.PUT("/pinjaman/restrukturisasi/:id/approve", pinjamanHdl.SetujuiRestrukturisasi) // Apply a restructuring (admin)
+		protected.PUT("/pinjaman/restrukturisasi/:id/reject", pinjamanHdl.TolakRestrukturisasi)    // Reject a restructuring (admin)
 		protected.GET("/pinjaman/:id", pinjamanHdl.Detail)
 		protected.POST("/pinjaman", pinjamanHdl.Create)
 		protected.PUT("/pinjaman/:id", pinjamanHdl.Update)
 		protected.DELETE("/pinjaman/:id", pinjamanHdl.Delete)
+		protected.GET("/pinjaman/:id/jadwal", pinjamanHdl.Jadwal)                          // Amortization schedule (?versi=N for a replaced version)
+		protected.GET("/pinjaman/:id/denda", pinjamanHdl.Denda)                            // Denda preview (?tanggal=YYYY-MM-DD)
+		protected.GET("/pinjaman/:id/kolektibilitas", pinjamanHdl.Kolektibilitas)          // Kolektibilitas history
+		protected.PUT("/pinjaman/:id/approve", pinjamanHdl.Approve)                        // proses -> disetujui (admin)
+		protected.PUT("/pinjaman/:id/reject", pinjamanHdl.Reject)                          // proses -> ditolak (admin)
+		protected.PUT("/pinjaman/:id/disburse", pinjamanHdl.Disburse)                      // disetujui -> dicairkan (admin)
+		protected.GET("/pinjaman/:id/pencairan", pinjamanHdl.GetPencairan)                 // Disbursement record with fees and net amount
+		protected.PUT("/pinjaman/:id/write-off", pinjamanHdl.WriteOff)                     // dicairkan -> macet (super admin)
+		protected.GET("/pinjaman/:id/pelunasan", pinjamanHdl.Pelunasan)                    // Early-settlement quote (?tanggal=YYYY-MM-DD)
+		protected.POST("/pinjaman/:id/pelunasan", pinjamanHdl.Lunasi)                      // Execute early settlement (admin)
+		protected.GET("/pinjaman/:id/restrukturisasi", pinjamanHdl.ListRestrukturisasi)    // Restructuring history
+		protected.POST("/pinjaman/:id/restrukturisasi", pinjamanHdl.AjukanRestrukturisasi) // Request a restructuring
+		protected.GET("/pinjaman/:id/penjamin", pinjamanHdl.ListPenjamin)                  // Guarantors
+		protected.POST("/pinjaman/:id/penjamin", pinjamanHdl.TambahPenjamin)               // Add a guarantor (before disbursement)
+		protected.DELETE("/pinjaman/:id/penjamin/:penjaminId", pinjamanHdl.HapusPenjamin)  // Remove a guarantor (before disbursement)
+		protected.GET("/pinjaman/:id/agunan", pinjamanHdl.ListAgunan)                      // Collateral items
+		protected.POST("/pinjaman/:id/agunan", pinjamanHdl.TambahAgunan)                   // Add collateral (before disbursement)
+		protected.DELETE("/pinjaman/:id/agunan/:agunanId", pinjamanHdl.HapusAgunan)        // Remove collateral (before disbursement)
+		protected.GET("/pinjaman/:id/auto-debet", pinjamanHdl.AutoDebet)                   // Auto-debit mandate and attempts
+		protected.POST("/pinjaman/:id/auto-debet", pinjamanHdl.AktifkanAutoDebet)          // Opt in to auto-debit from sukarela
+		protected.DELETE("/pinjaman/:id/auto-debet", pinjamanHdl.CabutAutoDebet)           // Revoke the auto-debit mandate
+
+		// Notifikasi of the logged-in user
+		protected.GET("/notifikasi", notifikasiHdl.List) // ?unread=true for unread only
+		protected.PUT("/notifikasi/:id/read", notifikasiHdl.MarkRead)
+
+		// Berkas (proof-of-transfer files); attach with bukti_transfer_id on angsuran, top-up and pencairan
+		protected.POST("/berkas", berkasHdl.Upload)               // Upload (multipart field "file"; JPEG, PNG or PDF)
+		protected.GET("/berkas", berkasHdl.List)                  // Own uploads (?lepas=true for unattached only)
+		protected.GET("/berkas/:id", berkasHdl.Detail)            // File details
+		protected.GET("/berkas/:id/download", berkasHdl.Download) // File content (?thumbnail=true for the preview)
+		protected.DELETE("/berkas/:id", berkasHdl.Delete)         // Delete an unattached upload
 
 		// Angsuran CRUD
 		protected.GET("/angsuran", angsuranHdl.List)
@@ -151,15 +379,104 @@ func main() {
 		protected.GET("/shu-anggota", shuAnggotaHdl.List)                   // Admin only
 		protected.GET("/shu-anggota/shu/:shu_id", shuAnggotaHdl.GetBySHUID) // Admin only
 		protected.DELETE("/shu-anggota/:id", shuAnggotaHdl.Delete)          // Admin only
+		protected.POST("/shu-anggota/:id/kredit", shuAnggotaHdl.Kreditkan)  // Admin only, pays the SHU into the sukarela wallet
 
 		// Bunga Options (Interest Rate Options) - Admin only
 		protected.POST("/bunga-options", bungaOptionHdl.Create)              // Create new interest rate option
 		protected.GET("/bunga-options", bungaOptionHdl.List)                 // List all options (?active=true for active only)
 		protected.GET("/bunga-options/:id", bungaOptionHdl.Detail)           // Get specific option
+		protected.GET("/bunga-options/:id/versi", bungaOptionHdl.Versi)      // Rate history of an option
 		protected.PUT("/bunga-options/:id", bungaOptionHdl.Update)           // Update option
 		protected.DELETE("/bunga-options/:id", bungaOptionHdl.Delete)        // Delete option
 		protected.PUT("/bunga-options/:id/status", bungaOptionHdl.SetActive) // Activate/deactivate option
 
+		// Aturan Denda (Late-payment Penalty Rules) - Admin only
+		protected.POST("/aturan-denda", aturanDendaHdl.Create)              // Create new rule (inactive)
+		protected.GET("/aturan-denda", aturanDendaHdl.List)                 // List all rules
+		protected.GET("/aturan-denda/active", aturanDendaHdl.Active)        // Get the rule in effect
+		protected.GET("/aturan-denda/:id", aturanDendaHdl.Detail)           // Get specific rule
+		protected.PUT("/aturan-denda/:id", aturanDendaHdl.Update)           // Update rule
+		protected.DELETE("/aturan-denda/:id", aturanDendaHdl.Delete)        // Delete rule
+		protected.PUT("/aturan-denda/:id/status", aturanDendaHdl.SetActive) // Activate (replaces current) / deactivate
+
+		// Aturan Kelayakan (Loan Eligibility Rules) - Admin only
+		protected.POST("/aturan-kelayakan", aturanKelayakanHdl.Create)              // Create new rule (inactive)
+		protected.GET("/aturan-kelayakan", aturanKelayakanHdl.List)                 // List all rules
+		protected.GET("/aturan-kelayakan/active", aturanKelayakanHdl.Active)        // Get the rule in effect
+		protected.GET("/aturan-kelayakan/:id", aturanKelayakanHdl.Detail)           // Get specific rule
+		protected.PUT("/aturan-kelayakan/:id", aturanKelayakanHdl.Update)           // Update rule
+		protected.DELETE("/aturan-kelayakan/:id", aturanKelayakanHdl.Delete)        // Delete rule
+		protected.PUT("/aturan-kelayakan/:id/status", aturanKelayakanHdl.SetActive) // Activate (replaces current) / deactivate
+
+		// Aturan Simpanan Wajib (monthly wajib amount) Management
+		protected.POST("/aturan-simpanan-wajib", aturanSimpananWajibHdl.Create)              // Create new rule (inactive)
+		protected.GET("/aturan-simpanan-wajib", aturanSimpananWajibHdl.List)                 // List all rules
+		protected.GET("/aturan-simpanan-wajib/active", aturanSimpananWajibHdl.Active)        // Get the rule in effect
+		protected.GET("/aturan-simpanan-wajib/:id", aturanSimpananWajibHdl.Detail)           // Get specific rule
+		protected.PUT("/aturan-simpanan-wajib/:id", aturanSimpananWajibHdl.Update)           // Update rule
+		protected.DELETE("/aturan-simpanan-wajib/:id", aturanSimpananWajibHdl.Delete)        // Delete rule
+		protected.PUT("/aturan-simpanan-wajib/:id/status", aturanSimpananWajibHdl.SetActive) // Activate (replaces current) / deactivate
+
+		// Aturan Jasa Simpanan (return on sukarela balances) Management
+		protected.POST("/aturan-jasa-simpanan", aturanJasaSimpananHdl.Create)              // Create new rule (inactive)
+		protected.GET("/aturan-jasa-simpanan", aturanJasaSimpananHdl.List)                 // List all rules
+		protected.GET("/aturan-jasa-simpanan/active", aturanJasaSimpananHdl.Active)        // Get the rule in effect
+		protected.GET("/aturan-jasa-simpanan/:id", aturanJasaSimpananHdl.Detail)           // Get specific rule
+		protected.PUT("/aturan-jasa-simpanan/:id", aturanJasaSimpananHdl.Update)           // Update rule
+		protected.DELETE("/aturan-jasa-simpanan/:id", aturanJasaSimpananHdl.Delete)        // Delete rule
+		protected.PUT("/aturan-jasa-simpanan/:id/status", aturanJasaSimpananHdl.SetActive) // Activate (replaces current) / deactivate
+
+		// Aturan Simpanan Pokok (one-time membership fee) Management
+		protected.POST("/aturan-simpanan-pokok", aturanSimpananPokokHdl.Create)              // Create new rule (inactive)
+		protected.GET("/aturan-simpanan-pokok", aturanSimpananPokokHdl.List)                 // List all rules
+		protected.GET("/aturan-simpanan-pokok/active", aturanSimpananPokokHdl.Active)        // Get the rule in effect
+		protected.GET("/aturan-simpanan-pokok/:id", aturanSimpananPokokHdl.Detail)           // Get specific rule
+		protected.PUT("/aturan-simpanan-pokok/:id", aturanSimpananPokokHdl.Update)           // Update rule
+		protected.DELETE("/aturan-simpanan-pokok/:id", aturanSimpananPokokHdl.Delete)        // Delete rule
+		protected.PUT("/aturan-simpanan-pokok/:id/status", aturanSimpananPokokHdl.SetActive) // Activate (replaces current) / deactivate
+
+		// Aturan Transfer (Sukarela Transfer Limits) - Admin only
+		protected.POST("/aturan-transfer", aturanTransferHdl.Create)              // Create new rule (inactive)
+		protected.GET("/aturan-transfer", aturanTransferHdl.List)                 // List all rules
+		protected.GET("/aturan-transfer/active", aturanTransferHdl.Active)        // Get the rule in effect
+		protected.GET("/aturan-transfer/:id", aturanTransferHdl.Detail)           // Get specific rule
+		protected.PUT("/aturan-transfer/:id", aturanTransferHdl.Update)           // Update rule
+		protected.DELETE("/aturan-transfer/:id", aturanTransferHdl.Delete)        // Delete rule
+		protected.PUT("/aturan-transfer/:id/status", aturanTransferHdl.SetActive) // Activate (replaces current) / deactivate
+
+		// Aturan Pelunasan (Early-settlement Rules) - Admin only
+		protected.POST("/aturan-pelunasan", aturanPelunasanHdl.Create)              // Create new rule (inactive)
+		protected.GET("/aturan-pelunasan", aturanPelunasanHdl.List)                 // List all rules
+		protected.GET("/aturan-pelunasan/active", aturanPelunasanHdl.Active)        // Get the rule in effect
+		protected.GET("/aturan-pelunasan/:id", aturanPelunasanHdl.Detail)           // Get specific rule
+		protected.PUT("/aturan-pelunasan/:id", aturanPelunasanHdl.Update)           // Update rule
+		protected.DELETE("/aturan-pelunasan/:id", aturanPelunasanHdl.Delete)        // Delete rule
+		protected.PUT("/aturan-pelunasan/:id/status", aturanPelunasanHdl.SetActive) // Activate (replaces current) / deactivate
+
+		// Jenis Pinjaman (Loan Products) - Admin only, except listing
+		protected.POST("/jenis-pinjaman", jenisPinjamanHdl.Create)              // Create new product (active)
+		protected.GET("/jenis-pinjaman", jenisPinjamanHdl.List)                 // List products (?active=true for active only)
+		protected.GET("/jenis-pinjaman/:id", jenisPinjamanHdl.Detail)           // Get specific product
+		protected.PUT("/jenis-pinjaman/:id", jenisPinjamanHdl.Update)           // Update product
+		protected.DELETE("/jenis-pinjaman/:id", jenisPinjamanHdl.Delete)        // Delete product
+		protected.PUT("/jenis-pinjaman/:id/status", jenisPinjamanHdl.SetActive) // Activate/deactivate product
+
+		// Jenis Simpanan Berjangka (Time-deposit Products) - Admin only, except listing
+		protected.POST("/jenis-simpanan-berjangka", jenisBerjangkaHdl.Create)              // Create new product (active)
+		protected.GET("/jenis-simpanan-berjangka", jenisBerjangkaHdl.List)                 // List products (?active=true for active only)
+		protected.GET("/jenis-simpanan-berjangka/:id", jenisBerjangkaHdl.Detail)           // Get specific product
+		protected.PUT("/jenis-simpanan-berjangka/:id", jenisBerjangkaHdl.Update)           // Update product
+		protected.DELETE("/jenis-simpanan-berjangka/:id", jenisBerjangkaHdl.Delete)        // Delete product
+		protected.PUT("/jenis-simpanan-berjangka/:id/status", jenisBerjangkaHdl.SetActive) // Activate/deactivate product
+
+		// Jenis Simpanan (Wallet Types) - Admin only, except listing
+		protected.POST("/jenis-simpanan", jenisSimpananHdl.Create)              // Create new type (active, every member gets a wallet)
+		protected.GET("/jenis-simpanan", jenisSimpananHdl.List)                 // List types (?active=true for active only)
+		protected.GET("/jenis-simpanan/:id", jenisSimpananHdl.Detail)           // Get specific type
+		protected.PUT("/jenis-simpanan/:id", jenisSimpananHdl.Update)           // Update type (kode cannot change)
+		protected.DELETE("/jenis-simpanan/:id", jenisSimpananHdl.Delete)        // Delete type (not built-in, no balances)
+		protected.PUT("/jenis-simpanan/:id/status", jenisSimpananHdl.SetActive) // Activate/deactivate type (built-in stay active)
+
 		// Audit Trail - Admin/Super Admin only
 		protected.GET("/audit-trails", auditHdl.GetAuditTrails)                // List audit trails with filters
 		protected.GET("/audit-trails/:id", auditHdl.GetAuditTrailDetail)       // Get specific audit trail
@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
@@ -14,6 +15,16 @@ type Config struct {
 	DBPass    string
 	DBName    string
 	JWTSecret string
+
+	// Uploaded proof-of-transfer files
+	StorageDriver  string // local (default) or s3
+	StorageDir     string // Root directory of the local driver
+	S3Endpoint     string
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	UploadMaxBytes int64 // 0 uses the service default
 }
 
 func LoadConfig() *Config {
@@ -27,5 +38,23 @@ func LoadConfig() *Config {
 		DBPass:    os.Getenv("DB_PASS"),
 		DBName:    os.Getenv("DB_NAME"),
 		JWTSecret: os.Getenv("JWT_SECRET"),
+
+		StorageDriver:  os.Getenv("STORAGE_DRIVER"),
+		StorageDir:     os.Getenv("STORAGE_DIR"),
+		S3Endpoint:     os.Getenv("S3_ENDPOINT"),
+		S3Region:       os.Getenv("S3_REGION"),
+		S3Bucket:       os.Getenv("S3_BUCKET"),
+		S3AccessKey:    os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:    os.Getenv("S3_SECRET_KEY"),
+		UploadMaxBytes: envInt64("UPLOAD_MAX_BYTES"),
+	}
+}
+
+// envInt64 reads a whole number from the environment, 0 when unset or invalid
+func envInt64(key string) int64 {
+	v, err := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if err != nil {
+		return 0
 	}
+	return v
 }
@@ -1,11 +1,16 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"koperasi-service/internal/model"
 	"koperasi-service/internal/service"
+	"koperasi-service/pkg/money"
 	"koperasi-service/pkg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -27,13 +32,14 @@ func (h *AngsuranHandler) Create(c *gin.Context) {
 	role := c.GetString("role")
 
 	var input struct {
-		PinjamanID uint    `json:"pinjaman_id" binding:"required"`
-		AngsuranKe int     `json:"angsuran_ke"` // Made optional - will be auto-generated if not provided
-		Pokok      float64 `json:"pokok" binding:"required,gt=0"`
-		Bunga      float64 `json:"bunga" binding:"required,gte=0"`
-		Denda      float64 `json:"denda"`
-		TotalBayar float64 `json:"total_bayar"`
-		UserID     uint    `json:"user_id"`
+		PinjamanID uint        `json:"pinjaman_id" binding:"required"`
+		AngsuranKe int         `json:"angsuran_ke"` // Made optional - will be auto-generated if not provided
+		Pokok      money.Money `json:"pokok" binding:"gte=0"`
+		Bunga      money.Money `json:"bunga" binding:"gte=0"`
+		TotalBayar money.Money `json:"total_bayar" binding:"gte=0"` // Defaults to pokok + bunga + computed denda
+		UserID     uint        `json:"user_id"`
+		// Uploaded receipt from POST /berkas
+		BuktiTransferID *uint `json:"bukti_transfer_id"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -42,21 +48,23 @@ func (h *AngsuranHandler) Create(c *gin.Context) {
 	}
 
 	a := &model.Angsuran{
-		PinjamanID: input.PinjamanID,
-		AngsuranKe: input.AngsuranKe,
-		Pokok:      input.Pokok,
-		Bunga:      input.Bunga,
-		Denda:      input.Denda,
-		TotalBayar: input.TotalBayar,
-		UserID:     input.UserID,
+		PinjamanID:      input.PinjamanID,
+		AngsuranKe:      input.AngsuranKe,
+		Pokok:           input.Pokok,
+		Bunga:           input.Bunga,
+		TotalBayar:      input.TotalBayar,
+		UserID:          input.UserID,
+		BuktiTransferID: input.BuktiTransferID,
 	}
 
 	if err := h.service.Create(userID, role, a); err != nil {
 		status := http.StatusInternalServerError
 		if err.Error() == "forbidden" {
 			status = http.StatusForbidden
-		} else if err.Error() == "pinjaman not found" {
+		} else if err.Error() == "pinjaman not found" || err.Error() == "total bayar must be greater than 0" || strings.HasPrefix(err.Error(), "bukti transfer") {
 			status = http.StatusBadRequest
+		} else if err.Error() == "pinjaman has not been disbursed" {
+			status = http.StatusConflict
 		}
 		c.JSON(status, utils.ResponseError(err.Error()))
 		return
@@ -125,11 +133,9 @@ func (h *AngsuranHandler) Update(c *gin.Context) {
 	}
 
 	var input struct {
-		Pokok      float64 `json:"pokok"`
-		Bunga      float64 `json:"bunga"`
-		Denda      float64 `json:"denda"`
-		TotalBayar float64 `json:"total_bayar"`
-		Status     string  `json:"status"`
+		Pokok      money.Money `json:"pokok"`
+		Bunga      money.Money `json:"bunga"`
+		TotalBayar money.Money `json:"total_bayar"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -137,21 +143,10 @@ func (h *AngsuranHandler) Update(c *gin.Context) {
 		return
 	}
 
-	// Validate status if provided
-	if input.Status != "" {
-		validStatuses := map[string]bool{"proses": true, "verified": true, "kurang": true, "lebih": true}
-		if !validStatuses[input.Status] {
-			c.JSON(http.StatusBadRequest, utils.ResponseError("invalid status"))
-			return
-		}
-	}
-
 	payload := &model.Angsuran{
 		Pokok:      input.Pokok,
 		Bunga:      input.Bunga,
-		Denda:      input.Denda,
 		TotalBayar: input.TotalBayar,
-		Status:     input.Status,
 	}
 
 	updated, err := h.service.Update(userID, role, uint(id64), payload)
@@ -159,6 +154,8 @@ func (h *AngsuranHandler) Update(c *gin.Context) {
 		status := http.StatusInternalServerError
 		if err.Error() == "forbidden" {
 			status = http.StatusForbidden
+		} else if err.Error() == "payment already verified" {
+			status = http.StatusConflict
 		}
 		c.JSON(status, utils.ResponseError(err.Error()))
 		return
@@ -183,6 +180,8 @@ func (h *AngsuranHandler) Delete(c *gin.Context) {
 		status := http.StatusInternalServerError
 		if err.Error() == "forbidden" {
 			status = http.StatusForbidden
+		} else if err.Error() == "payment already verified" {
+			status = http.StatusConflict
 		}
 		c.JSON(status, utils.ResponseError(err.Error()))
 		return
@@ -203,21 +202,37 @@ func (h *AngsuranHandler) Verify(c *gin.Context) {
 		return
 	}
 
+	// The body is optional: without it the full total_bayar is allocated
+	// and any overpayment rolls into the next installments
 	var input struct {
-		Status string `json:"status" binding:"required,oneof=verified kurang lebih"`
+		JumlahDiterima  *money.Money `json:"jumlah_diterima"`
+		TanggalDiterima string       `json:"tanggal_diterima"` // YYYY-MM-DD; defaults to tanggal_bayar
+		Kelebihan       string       `json:"kelebihan" binding:"omitempty,oneof=lanjut kembalikan"`
 	}
 
-	if err := c.ShouldBindJSON(&input); err != nil {
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
 		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
 		return
 	}
 
-	verified, err := h.service.VerifyPayment(userID, role, uint(id64), input.Status)
+	var tanggalDiterima *time.Time
+	if input.TanggalDiterima != "" {
+		t, err := time.ParseInLocation("2006-01-02", input.TanggalDiterima, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ResponseError("invalid tanggal_diterima, use YYYY-MM-DD"))
+			return
+		}
+		tanggalDiterima = &t
+	}
+
+	verified, err := h.service.VerifyPayment(userID, role, uint(id64), input.JumlahDiterima, tanggalDiterima, input.Kelebihan == "kembalikan")
 	if err != nil {
 		status := http.StatusInternalServerError
 		if err.Error() == "forbidden" {
 			status = http.StatusForbidden
-		} else if err.Error() == "invalid status for verification" {
+		} else if err.Error() == "payment already verified" || err.Error() == "pinjaman has not been disbursed" {
+			status = http.StatusConflict
+		} else if err.Error() == "jumlah diterima must be greater than 0" || err.Error() == "tanggal diterima must not be in the future" {
 			status = http.StatusBadRequest
 		}
 		c.JSON(status, utils.ResponseError(err.Error()))
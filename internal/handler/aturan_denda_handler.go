@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/service"
+	"koperasi-service/pkg/money"
+)
+
+// NewAturanDendaHandler serves the /aturan-denda endpoints
+func NewAturanDendaHandler(svc service.AturanService[model.AturanDenda]) *AturanHandler[model.AturanDenda, AturanDendaRequest] {
+	return newAturanHandler[model.AturanDenda, AturanDendaRequest](svc, "denda")
+}
+
+type AturanDendaRequest struct {
+	Nama             string      `json:"nama" binding:"required"`
+	PersenPerHari    float64     `json:"persen_per_hari"`
+	BiayaTetap       money.Money `json:"biaya_tetap"`
+	MasaTenggangHari int         `json:"masa_tenggang_hari"`
+	MaksimalDenda    money.Money `json:"maksimal_denda"`
+	Deskripsi        string      `json:"deskripsi"`
+}
+
+func (r AturanDendaRequest) toModel() *model.AturanDenda {
+	return &model.AturanDenda{
+		Nama:             r.Nama,
+		PersenPerHari:    r.PersenPerHari,
+		BiayaTetap:       r.BiayaTetap,
+		MasaTenggangHari: r.MasaTenggangHari,
+		MaksimalDenda:    r.MaksimalDenda,
+		Deskripsi:        r.Deskripsi,
+	}
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"koperasi-service/internal/service"
+	"koperasi-service/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// aturanRequest is the create and update body of one kind of rule
+type aturanRequest[T any] interface {
+	toModel() *T
+}
+
+// AturanHandler serves the endpoints of one kind of admin-configurable rule;
+// R is the request body
+type AturanHandler[T any, R aturanRequest[T]] struct {
+	service service.AturanService[T]
+	jenis   string // Kind of rule in messages, e.g. "denda"
+}
+
+func newAturanHandler[T any, R aturanRequest[T]](svc service.AturanService[T], jenis string) *AturanHandler[T, R] {
+	return &AturanHandler[T, R]{service: svc, jenis: jenis}
+}
+
+func (h *AturanHandler[T, R]) Create(c *gin.Context) {
+	var req R
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ResponseError("User not authenticated"))
+		return
+	}
+
+	aturan, err := h.service.Create(userID.(uint), req.toModel())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Aturan " + h.jenis + " created successfully",
+		"data":    aturan,
+	})
+}
+
+func (h *AturanHandler[T, R]) List(c *gin.Context) {
+	list, err := h.service.GetAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Aturan " + h.jenis + " retrieved successfully",
+		"data":    list,
+	})
+}
+
+func (h *AturanHandler[T, R]) Active(c *gin.Context) {
+	aturan, err := h.service.GetActive()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Active aturan " + h.jenis + " retrieved successfully",
+		"data":    aturan,
+	})
+}
+
+func (h *AturanHandler[T, R]) Detail(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+
+	aturan, err := h.service.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, utils.ResponseError("Aturan "+h.jenis+" not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Aturan " + h.jenis + " retrieved successfully",
+		"data":    aturan,
+	})
+}
+
+func (h *AturanHandler[T, R]) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+
+	var req R
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ResponseError("User not authenticated"))
+		return
+	}
+
+	aturan, err := h.service.Update(uint(id), userID.(uint), req.toModel())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Aturan " + h.jenis + " updated successfully",
+		"data":    aturan,
+	})
+}
+
+func (h *AturanHandler[T, R]) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ResponseError("User not authenticated"))
+		return
+	}
+
+	err = h.service.Delete(uint(id), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Aturan "+h.jenis+" deleted successfully"))
+}
+
+func (h *AturanHandler[T, R]) SetActive(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+
+	var req SetActiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ResponseError("User not authenticated"))
+		return
+	}
+
+	err = h.service.SetActive(uint(id), userID.(uint), req.IsActive)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	status := "deactivated"
+	if req.IsActive {
+		status = "activated"
+	}
+
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Aturan "+h.jenis+" "+status+" successfully"))
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/service"
+	"koperasi-service/pkg/money"
+)
+
+// NewAturanJasaSimpananHandler serves the /aturan-jasa-simpanan endpoints
+func NewAturanJasaSimpananHandler(svc service.AturanService[model.AturanJasaSimpanan]) *AturanHandler[model.AturanJasaSimpanan, AturanJasaSimpananRequest] {
+	return newAturanHandler[model.AturanJasaSimpanan, AturanJasaSimpananRequest](svc, "jasa simpanan")
+}
+
+type AturanJasaSimpananRequest struct {
+	Nama             string      `json:"nama" binding:"required"`
+	PersenTahunan    float64     `json:"persen_tahunan" binding:"required"`
+	DasarPerhitungan string      `json:"dasar_perhitungan"` // Defaults to saldo_rata_rata
+	PersenPajak      float64     `json:"persen_pajak"`
+	BatasBebasPajak  money.Money `json:"batas_bebas_pajak"`
+	Deskripsi        string      `json:"deskripsi"`
+}
+
+func (r AturanJasaSimpananRequest) toModel() *model.AturanJasaSimpanan {
+	if r.DasarPerhitungan == "" {
+		r.DasarPerhitungan = model.DasarSaldoRataRata
+	}
+	return &model.AturanJasaSimpanan{
+		Nama:             r.Nama,
+		PersenTahunan:    r.PersenTahunan,
+		DasarPerhitungan: r.DasarPerhitungan,
+		PersenPajak:      r.PersenPajak,
+		BatasBebasPajak:  r.BatasBebasPajak,
+		Deskripsi:        r.Deskripsi,
+	}
+}
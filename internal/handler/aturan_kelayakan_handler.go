@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/service"
+)
+
+// NewAturanKelayakanHandler serves the /aturan-kelayakan endpoints
+func NewAturanKelayakanHandler(svc service.AturanService[model.AturanKelayakan]) *AturanHandler[model.AturanKelayakan, AturanKelayakanRequest] {
+	return newAturanHandler[model.AturanKelayakan, AturanKelayakanRequest](svc, "kelayakan")
+}
+
+type AturanKelayakanRequest struct {
+	Nama                    string  `json:"nama" binding:"required"`
+	KelipatanSimpanan       float64 `json:"kelipatan_simpanan"`
+	MaksimalPinjamanAktif   int     `json:"maksimal_pinjaman_aktif"`
+	MinimalBulanKeanggotaan int     `json:"minimal_bulan_keanggotaan"`
+	MaksimalRasioAngsuran   float64 `json:"maksimal_rasio_angsuran"`
+	KelipatanPenjaminan     float64 `json:"kelipatan_penjaminan"`
+	WajibLancar             bool    `json:"wajib_lancar"`
+	Deskripsi               string  `json:"deskripsi"`
+}
+
+func (r AturanKelayakanRequest) toModel() *model.AturanKelayakan {
+	return &model.AturanKelayakan{
+		Nama:                    r.Nama,
+		KelipatanSimpanan:       r.KelipatanSimpanan,
+		MaksimalPinjamanAktif:   r.MaksimalPinjamanAktif,
+		MinimalBulanKeanggotaan: r.MinimalBulanKeanggotaan,
+		MaksimalRasioAngsuran:   r.MaksimalRasioAngsuran,
+		KelipatanPenjaminan:     r.KelipatanPenjaminan,
+		WajibLancar:             r.WajibLancar,
+		Deskripsi:               r.Deskripsi,
+	}
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/service"
+	"koperasi-service/pkg/money"
+)
+
+// NewAturanPelunasanHandler serves the /aturan-pelunasan endpoints
+func NewAturanPelunasanHandler(svc service.AturanService[model.AturanPelunasan]) *AturanHandler[model.AturanPelunasan, AturanPelunasanRequest] {
+	return newAturanHandler[model.AturanPelunasan, AturanPelunasanRequest](svc, "pelunasan")
+}
+
+type AturanPelunasanRequest struct {
+	Nama           string      `json:"nama" binding:"required"`
+	KebijakanBunga string      `json:"kebijakan_bunga"`
+	PersenRebate   float64     `json:"persen_rebate"`
+	BiayaPersen    float64     `json:"biaya_persen"`
+	BiayaTetap     money.Money `json:"biaya_tetap"`
+	Deskripsi      string      `json:"deskripsi"`
+}
+
+func (r AturanPelunasanRequest) toModel() *model.AturanPelunasan {
+	return &model.AturanPelunasan{
+		Nama:           r.Nama,
+		KebijakanBunga: r.KebijakanBunga,
+		PersenRebate:   r.PersenRebate,
+		BiayaPersen:    r.BiayaPersen,
+		BiayaTetap:     r.BiayaTetap,
+		Deskripsi:      r.Deskripsi,
+	}
+}
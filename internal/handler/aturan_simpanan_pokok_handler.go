@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/service"
+	"koperasi-service/pkg/money"
+)
+
+// NewAturanSimpananPokokHandler serves the /aturan-simpanan-pokok endpoints
+func NewAturanSimpananPokokHandler(svc service.AturanService[model.AturanSimpananPokok]) *AturanHandler[model.AturanSimpananPokok, AturanSimpananPokokRequest] {
+	return newAturanHandler[model.AturanSimpananPokok, AturanSimpananPokokRequest](svc, "simpanan pokok")
+}
+
+type AturanSimpananPokokRequest struct {
+	Nama      string      `json:"nama" binding:"required"`
+	Nominal   money.Money `json:"nominal" binding:"required"`
+	Deskripsi string      `json:"deskripsi"`
+}
+
+func (r AturanSimpananPokokRequest) toModel() *model.AturanSimpananPokok {
+	return &model.AturanSimpananPokok{
+		Nama:      r.Nama,
+		Nominal:   r.Nominal,
+		Deskripsi: r.Deskripsi,
+	}
+}
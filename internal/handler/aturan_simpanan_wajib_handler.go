@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/service"
+	"koperasi-service/pkg/money"
+)
+
+// NewAturanSimpananWajibHandler serves the /aturan-simpanan-wajib endpoints
+func NewAturanSimpananWajibHandler(svc service.AturanService[model.AturanSimpananWajib]) *AturanHandler[model.AturanSimpananWajib, AturanSimpananWajibRequest] {
+	return newAturanHandler[model.AturanSimpananWajib, AturanSimpananWajibRequest](svc, "simpanan wajib")
+}
+
+type AturanSimpananWajibRequest struct {
+	Nama              string      `json:"nama" binding:"required"`
+	Nominal           money.Money `json:"nominal" binding:"required"`
+	TanggalJatuhTempo int         `json:"tanggal_jatuh_tempo"` // Defaults to 10
+	Deskripsi         string      `json:"deskripsi"`
+}
+
+func (r AturanSimpananWajibRequest) toModel() *model.AturanSimpananWajib {
+	if r.TanggalJatuhTempo == 0 {
+		r.TanggalJatuhTempo = 10
+	}
+	return &model.AturanSimpananWajib{
+		Nama:              r.Nama,
+		Nominal:           r.Nominal,
+		TanggalJatuhTempo: r.TanggalJatuhTempo,
+		Deskripsi:         r.Deskripsi,
+	}
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/service"
+	"koperasi-service/pkg/money"
+)
+
+// NewAturanTransferHandler serves the /aturan-transfer endpoints
+func NewAturanTransferHandler(svc service.AturanService[model.AturanTransfer]) *AturanHandler[model.AturanTransfer, AturanTransferRequest] {
+	return newAturanHandler[model.AturanTransfer, AturanTransferRequest](svc, "transfer")
+}
+
+type AturanTransferRequest struct {
+	Nama              string      `json:"nama" binding:"required"`
+	MinimalTransfer   money.Money `json:"minimal_transfer"`
+	BatasPerTransaksi money.Money `json:"batas_per_transaksi"`
+	BatasHarian       money.Money `json:"batas_harian"`
+	BatasPersetujuan  money.Money `json:"batas_persetujuan"`
+	Deskripsi         string      `json:"deskripsi"`
+}
+
+func (r AturanTransferRequest) toModel() *model.AturanTransfer {
+	return &model.AturanTransfer{
+		Nama:              r.Nama,
+		MinimalTransfer:   r.MinimalTransfer,
+		BatasPerTransaksi: r.BatasPerTransaksi,
+		BatasHarian:       r.BatasHarian,
+		BatasPersetujuan:  r.BatasPersetujuan,
+		Deskripsi:         r.Deskripsi,
+	}
+}
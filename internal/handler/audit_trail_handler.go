@@ -7,6 +7,7 @@ import (
 
 	"koperasi-service/internal/repository"
 	"koperasi-service/internal/service"
+	"koperasi-service/pkg/money"
 	"koperasi-service/pkg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -301,13 +302,13 @@ func (h *AuditTrailHandler) GetTransactionHistory(c *gin.Context) {
 	}
 
 	if minAmountStr := c.Query("min_amount"); minAmountStr != "" {
-		if minAmount, err := strconv.ParseFloat(minAmountStr, 64); err == nil {
+		if minAmount, err := money.Parse(minAmountStr); err == nil {
 			filters.MinAmount = &minAmount
 		}
 	}
 
 	if maxAmountStr := c.Query("max_amount"); maxAmountStr != "" {
-		if maxAmount, err := strconv.ParseFloat(maxAmountStr, 64); err == nil {
+		if maxAmount, err := money.Parse(maxAmountStr); err == nil {
 			filters.MaxAmount = &maxAmount
 		}
 	}
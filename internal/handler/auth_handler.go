@@ -81,13 +81,14 @@ func (h *AuthHandler) Me(c *gin.Context) {
 		return
 	}
 	response := gin.H{
-		"id":           user.ID,
-		"email":        user.Email,
-		"name":         user.Name,
-		"address":      user.Address,
-		"phone_number": user.PhoneNumber,
-		"nik":          user.NIK,
-		"role":         gin.H{"id": user.Role.ID, "name": user.Role.Name},
+		"id":                 user.ID,
+		"email":              user.Email,
+		"name":               user.Name,
+		"address":            user.Address,
+		"phone_number":       user.PhoneNumber,
+		"nik":                user.NIK,
+		"role":               gin.H{"id": user.Role.ID, "name": user.Role.Name},
+		"status_keanggotaan": user.StatusKeanggotaan,
 	}
 	if user.AdminID != nil {
 		response["admin_id"] = *user.AdminID
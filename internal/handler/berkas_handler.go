@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"koperasi-service/internal/service"
+	"koperasi-service/internal/storage"
+	"koperasi-service/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BerkasHandler exposes proof-of-transfer upload and download endpoints
+type BerkasHandler struct {
+	service *service.BerkasService
+}
+
+// NewBerkasHandler creates a new handler instance
+func NewBerkasHandler(s *service.BerkasService) *BerkasHandler {
+	return &BerkasHandler{service: s}
+}
+
+// berkasErrorStatus maps upload and download errors to HTTP status codes
+func berkasErrorStatus(err error) int {
+	switch {
+	case err.Error() == "forbidden":
+		return http.StatusForbidden
+	case err.Error() == "berkas not found", err.Error() == "berkas has no thumbnail",
+		errors.Is(err, storage.ErrNotFound):
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "file must not be larger"):
+		return http.StatusRequestEntityTooLarge
+	case strings.HasPrefix(err.Error(), "file type"):
+		return http.StatusUnsupportedMediaType
+	case err.Error() == "file is empty", strings.HasPrefix(err.Error(), "image "):
+		return http.StatusBadRequest
+	case err.Error() == "berkas is attached and cannot be deleted":
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// Upload stores a proof-of-transfer file sent as multipart field "file"
+func (h *BerkasHandler) Upload(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	// Leave room for the multipart envelope; the service checks the file itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.service.MaksUkuran()+1<<20)
+	fh, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, utils.ResponseError(fmt.Sprintf("file must not be larger than %d bytes", h.service.MaksUkuran())))
+			return
+		}
+		c.JSON(http.StatusBadRequest, utils.ResponseError("file is required"))
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+	defer f.Close()
+
+	b, err := h.service.Unggah(userID, fh.Filename, f)
+	if err != nil {
+		c.JSON(berkasErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "File uploaded",
+		"data":    b,
+	})
+}
+
+// List returns the caller's own uploads
+func (h *BerkasHandler) List(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	list, err := h.service.ListMilikSaya(userID, c.Query("lepas") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// Detail returns the details of an uploaded file
+func (h *BerkasHandler) Detail(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	b, err := h.service.Get(userID, role, uint(id64))
+	if err != nil {
+		c.JSON(berkasErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": b})
+}
+
+// Download streams an uploaded file, or its thumbnail with ?thumbnail=true
+func (h *BerkasHandler) Download(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+	thumbnail := c.Query("thumbnail") == "true"
+
+	b, rc, err := h.service.Buka(userID, role, uint(id64), thumbnail)
+	if err != nil {
+		c.JSON(berkasErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+	defer rc.Close()
+
+	contentType, size, name := b.ContentType, b.Ukuran, b.NamaAsli
+	if thumbnail {
+		contentType, size, name = "image/jpeg", b.UkuranThumbnail, "thumbnail-"+b.Hash[:12]+".jpg"
+	}
+	if name == "" || name == "." {
+		name = b.Hash[:12]
+	}
+	c.DataFromReader(http.StatusOK, size, contentType, rc, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("inline", map[string]string{"filename": name}),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, max-age=86400",
+		"ETag":                   `"` + b.Hash + `"`,
+	})
+}
+
+// Delete removes an upload that is not attached to a record
+func (h *BerkasHandler) Delete(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	if err := h.service.Hapus(userID, role, uint(id64)); err != nil {
+		c.JSON(berkasErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.ResponseSuccess("File deleted"))
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"koperasi-service/internal/service"
 	"koperasi-service/pkg/utils"
@@ -19,21 +20,47 @@ func NewBungaOptionHandler(bungaOptionService service.BungaOptionService) *Bunga
 }
 
 type CreateBungaOptionRequest struct {
-	Nama      string  `json:"nama" binding:"required"`
-	Persen    float64 `json:"persen" binding:"required"`
-	Deskripsi string  `json:"deskripsi"`
+	Nama          string  `json:"nama" binding:"required"`
+	Persen        float64 `json:"persen" binding:"required"`
+	MetodeBunga   string  `json:"metode_bunga"`
+	Deskripsi     string  `json:"deskripsi"`
+	EffectiveFrom string  `json:"effective_from"` // YYYY-MM-DD, default now
+	EffectiveTo   string  `json:"effective_to"`   // YYYY-MM-DD, default open-ended
 }
 
 type UpdateBungaOptionRequest struct {
-	Nama      string  `json:"nama" binding:"required"`
-	Persen    float64 `json:"persen" binding:"required"`
-	Deskripsi string  `json:"deskripsi"`
+	Nama          string  `json:"nama" binding:"required"`
+	Persen        float64 `json:"persen" binding:"required"`
+	MetodeBunga   string  `json:"metode_bunga"`
+	Deskripsi     string  `json:"deskripsi"`
+	EffectiveFrom string  `json:"effective_from"` // YYYY-MM-DD, default now
+	EffectiveTo   string  `json:"effective_to"`   // YYYY-MM-DD, default open-ended
 }
 
 type SetActiveRequest struct {
 	IsActive bool `json:"is_active"`
 }
 
+// parsePeriode reads the optional YYYY-MM-DD period of a rate version
+func parsePeriode(from, to string) (service.VersiPeriode, error) {
+	var periode service.VersiPeriode
+	if from != "" {
+		t, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			return periode, err
+		}
+		periode.From = &t
+	}
+	if to != "" {
+		t, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			return periode, err
+		}
+		periode.To = &t
+	}
+	return periode, nil
+}
+
 func (h *BungaOptionHandler) Create(c *gin.Context) {
 	var req CreateBungaOptionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -47,7 +74,13 @@ func (h *BungaOptionHandler) Create(c *gin.Context) {
 		return
 	}
 
-	bungaOption, err := h.bungaOptionService.CreateBungaOption(userID.(uint), req.Nama, req.Persen, req.Deskripsi)
+	periode, err := parsePeriode(req.EffectiveFrom, req.EffectiveTo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid effective date, use YYYY-MM-DD"))
+		return
+	}
+
+	bungaOption, err := h.bungaOptionService.CreateBungaOption(userID.(uint), req.Nama, req.Persen, req.MetodeBunga, req.Deskripsi, periode)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
 		return
@@ -103,6 +136,27 @@ func (h *BungaOptionHandler) Detail(c *gin.Context) {
 	})
 }
 
+// Versi returns the rate history of an option
+func (h *BungaOptionHandler) Versi(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+
+	history, err := h.bungaOptionService.GetBungaOptionVersi(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, utils.ResponseError("Bunga option not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Bunga option rate history retrieved successfully",
+		"data":    history,
+	})
+}
+
 func (h *BungaOptionHandler) Update(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -123,7 +177,13 @@ func (h *BungaOptionHandler) Update(c *gin.Context) {
 		return
 	}
 
-	err = h.bungaOptionService.UpdateBungaOption(uint(id), userID.(uint), req.Nama, req.Persen, req.Deskripsi)
+	periode, err := parsePeriode(req.EffectiveFrom, req.EffectiveTo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid effective date, use YYYY-MM-DD"))
+		return
+	}
+
+	err = h.bungaOptionService.UpdateBungaOption(uint(id), userID.(uint), req.Nama, req.Persen, req.MetodeBunga, req.Deskripsi, periode)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
 		return
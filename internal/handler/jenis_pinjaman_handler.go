@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/service"
+	"koperasi-service/pkg/money"
+	"koperasi-service/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JenisPinjamanHandler struct {
+	jenisPinjamanService service.JenisPinjamanService
+}
+
+func NewJenisPinjamanHandler(jenisPinjamanService service.JenisPinjamanService) *JenisPinjamanHandler {
+	return &JenisPinjamanHandler{jenisPinjamanService: jenisPinjamanService}
+}
+
+type JenisPinjamanRequest struct {
+	Nama               string      `json:"nama" binding:"required"`
+	Deskripsi          string      `json:"deskripsi"`
+	MinimalJumlah      money.Money `json:"minimal_jumlah"`
+	MaksimalJumlah     money.Money `json:"maksimal_jumlah"`
+	MinimalBulan       int         `json:"minimal_bulan"`
+	MaksimalBulan      int         `json:"maksimal_bulan"`
+	BungaOptionID      *uint       `json:"bunga_option_id"`
+	BungaPersen        float64     `json:"bunga_persen"`
+	MetodeBunga        string      `json:"metode_bunga"`
+	BiayaAdmin         money.Money `json:"biaya_admin"`
+	BiayaProvisiPersen float64     `json:"biaya_provisi_persen"`
+	WajibPenjamin      bool        `json:"wajib_penjamin"`
+	WajibAgunan        bool        `json:"wajib_agunan"`
+	MinimalNilaiAgunan float64     `json:"minimal_nilai_agunan"`
+	AturanKelayakanID  *uint       `json:"aturan_kelayakan_id"`
+}
+
+func (r JenisPinjamanRequest) toModel() *model.JenisPinjaman {
+	return &model.JenisPinjaman{
+		Nama:               r.Nama,
+		Deskripsi:          r.Deskripsi,
+		MinimalJumlah:      r.MinimalJumlah,
+		MaksimalJumlah:     r.MaksimalJumlah,
+		MinimalBulan:       r.MinimalBulan,
+		MaksimalBulan:      r.MaksimalBulan,
+		BungaOptionID:      r.BungaOptionID,
+		BungaPersen:        r.BungaPersen,
+		MetodeBunga:        r.MetodeBunga,
+		BiayaAdmin:         r.BiayaAdmin,
+		BiayaProvisiPersen: r.BiayaProvisiPersen,
+		WajibPenjamin:      r.WajibPenjamin,
+		WajibAgunan:        r.WajibAgunan,
+		MinimalNilaiAgunan: r.MinimalNilaiAgunan,
+		AturanKelayakanID:  r.AturanKelayakanID,
+	}
+}
+
+func (h *JenisPinjamanHandler) Create(c *gin.Context) {
+	var req JenisPinjamanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ResponseError("User not authenticated"))
+		return
+	}
+
+	jenis, err := h.jenisPinjamanService.CreateJenisPinjaman(userID.(uint), req.toModel())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Jenis pinjaman created successfully",
+		"data":    jenis,
+	})
+}
+
+func (h *JenisPinjamanHandler) List(c *gin.Context) {
+	var list []model.JenisPinjaman
+	var err error
+	if c.Query("active") == "true" {
+		list, err = h.jenisPinjamanService.GetActiveJenisPinjaman()
+	} else {
+		list, err = h.jenisPinjamanService.GetAllJenisPinjaman()
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Jenis pinjaman retrieved successfully",
+		"data":    list,
+	})
+}
+
+func (h *JenisPinjamanHandler) Detail(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+
+	jenis, err := h.jenisPinjamanService.GetJenisPinjamanByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, utils.ResponseError("Jenis pinjaman not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Jenis pinjaman retrieved successfully",
+		"data":    jenis,
+	})
+}
+
+func (h *JenisPinjamanHandler) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+
+	var req JenisPinjamanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ResponseError("User not authenticated"))
+		return
+	}
+
+	jenis, err := h.jenisPinjamanService.UpdateJenisPinjaman(uint(id), userID.(uint), req.toModel())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Jenis pinjaman updated successfully",
+		"data":    jenis,
+	})
+}
+
+func (h *JenisPinjamanHandler) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ResponseError("User not authenticated"))
+		return
+	}
+
+	err = h.jenisPinjamanService.DeleteJenisPinjaman(uint(id), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Jenis pinjaman deleted successfully"))
+}
+
+func (h *JenisPinjamanHandler) SetActive(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+
+	var req SetActiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ResponseError("User not authenticated"))
+		return
+	}
+
+	err = h.jenisPinjamanService.SetJenisPinjamanActive(uint(id), userID.(uint), req.IsActive)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	status := "deactivated"
+	if req.IsActive {
+		status = "activated"
+	}
+
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Jenis pinjaman "+status+" successfully"))
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/service"
+	"koperasi-service/pkg/money"
+	"koperasi-service/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JenisSimpananBerjangkaHandler struct {
+	jenisService service.JenisSimpananBerjangkaService
+}
+
+func NewJenisSimpananBerjangkaHandler(jenisService service.JenisSimpananBerjangkaService) *JenisSimpananBerjangkaHandler {
+	return &JenisSimpananBerjangkaHandler{jenisService: jenisService}
+}
+
+type JenisSimpananBerjangkaRequest struct {
+	Nama           string      `json:"nama" binding:"required"`
+	Deskripsi      string      `json:"deskripsi"`
+	TenorBulan     int         `json:"tenor_bulan" binding:"required"`
+	PersenTahunan  float64     `json:"persen_tahunan" binding:"required"`
+	MinimalNominal money.Money `json:"minimal_nominal"`
+	PersenPenalti  float64     `json:"persen_penalti"`
+}
+
+func (r JenisSimpananBerjangkaRequest) toModel() *model.JenisSimpananBerjangka {
+	return &model.JenisSimpananBerjangka{
+		Nama:           r.Nama,
+		Deskripsi:      r.Deskripsi,
+		TenorBulan:     r.TenorBulan,
+		PersenTahunan:  r.PersenTahunan,
+		MinimalNominal: r.MinimalNominal,
+		PersenPenalti:  r.PersenPenalti,
+	}
+}
+
+func (h *JenisSimpananBerjangkaHandler) Create(c *gin.Context) {
+	var req JenisSimpananBerjangkaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ResponseError("User not authenticated"))
+		return
+	}
+
+	jenis, err := h.jenisService.CreateJenisSimpananBerjangka(userID.(uint), req.toModel())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Jenis simpanan berjangka created successfully",
+		"data":    jenis,
+	})
+}
+
+func (h *JenisSimpananBerjangkaHandler) List(c *gin.Context) {
+	var list []model.JenisSimpananBerjangka
+	var err error
+	if c.Query("active") == "true" {
+		list, err = h.jenisService.GetActiveJenisSimpananBerjangka()
+	} else {
+		list, err = h.jenisService.GetAllJenisSimpananBerjangka()
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Jenis simpanan berjangka retrieved successfully",
+		"data":    list,
+	})
+}
+
+func (h *JenisSimpananBerjangkaHandler) Detail(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+
+	jenis, err := h.jenisService.GetJenisSimpananBerjangkaByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, utils.ResponseError("Jenis simpanan berjangka not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Jenis simpanan berjangka retrieved successfully",
+		"data":    jenis,
+	})
+}
+
+func (h *JenisSimpananBerjangkaHandler) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+
+	var req JenisSimpananBerjangkaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ResponseError("User not authenticated"))
+		return
+	}
+
+	jenis, err := h.jenisService.UpdateJenisSimpananBerjangka(uint(id), userID.(uint), req.toModel())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Jenis simpanan berjangka updated successfully",
+		"data":    jenis,
+	})
+}
+
+func (h *JenisSimpananBerjangkaHandler) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ResponseError("User not authenticated"))
+		return
+	}
+
+	err = h.jenisService.DeleteJenisSimpananBerjangka(uint(id), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Jenis simpanan berjangka deleted successfully"))
+}
+
+func (h *JenisSimpananBerjangkaHandler) SetActive(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+
+	var req SetActiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ResponseError("User not authenticated"))
+		return
+	}
+
+	err = h.jenisService.SetJenisSimpananBerjangkaActive(uint(id), userID.(uint), req.IsActive)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	status := "deactivated"
+	if req.IsActive {
+		status = "activated"
+	}
+
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Jenis simpanan berjangka "+status+" successfully"))
+}
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/service"
+	"koperasi-service/pkg/money"
+	"koperasi-service/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JenisSimpananHandler struct {
+	jenisSimpananService service.JenisSimpananService
+}
+
+func NewJenisSimpananHandler(jenisSimpananService service.JenisSimpananService) *JenisSimpananHandler {
+	return &JenisSimpananHandler{jenisSimpananService: jenisSimpananService}
+}
+
+type JenisSimpananRequest struct {
+	Kode           string      `json:"kode"` // Only used on create; the kode cannot change
+	Nama           string      `json:"nama" binding:"required"`
+	Deskripsi      string      `json:"deskripsi"`
+	BisaDitarik    bool        `json:"bisa_ditarik"`
+	Wajib          bool        `json:"wajib"`
+	SaldoMinimal   money.Money `json:"saldo_minimal"`
+	NominalDefault money.Money `json:"nominal_default"`
+}
+
+func (r JenisSimpananRequest) toModel() *model.JenisSimpanan {
+	return &model.JenisSimpanan{
+		Kode:           r.Kode,
+		Nama:           r.Nama,
+		Deskripsi:      r.Deskripsi,
+		BisaDitarik:    r.BisaDitarik,
+		Wajib:          r.Wajib,
+		SaldoMinimal:   r.SaldoMinimal,
+		NominalDefault: r.NominalDefault,
+	}
+}
+
+func (h *JenisSimpananHandler) Create(c *gin.Context) {
+	var req JenisSimpananRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ResponseError("User not authenticated"))
+		return
+	}
+
+	jenis, err := h.jenisSimpananService.CreateJenisSimpanan(userID.(uint), req.toModel())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Jenis simpanan created successfully",
+		"data":    jenis,
+	})
+}
+
+func (h *JenisSimpananHandler) List(c *gin.Context) {
+	var list []model.JenisSimpanan
+	var err error
+	if c.Query("active") == "true" {
+		list, err = h.jenisSimpananService.GetActiveJenisSimpanan()
+	} else {
+		list, err = h.jenisSimpananService.GetAllJenisSimpanan()
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Jenis simpanan retrieved successfully",
+		"data":    list,
+	})
+}
+
+func (h *JenisSimpananHandler) Detail(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+
+	jenis, err := h.jenisSimpananService.GetJenisSimpananByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, utils.ResponseError("Jenis simpanan not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Jenis simpanan retrieved successfully",
+		"data":    jenis,
+	})
+}
+
+func (h *JenisSimpananHandler) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+
+	var req JenisSimpananRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ResponseError("User not authenticated"))
+		return
+	}
+
+	jenis, err := h.jenisSimpananService.UpdateJenisSimpanan(uint(id), userID.(uint), req.toModel())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Jenis simpanan updated successfully",
+		"data":    jenis,
+	})
+}
+
+func (h *JenisSimpananHandler) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ResponseError("User not authenticated"))
+		return
+	}
+
+	err = h.jenisSimpananService.DeleteJenisSimpanan(uint(id), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Jenis simpanan deleted successfully"))
+}
+
+func (h *JenisSimpananHandler) SetActive(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("Invalid ID"))
+		return
+	}
+
+	var req SetActiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ResponseError("User not authenticated"))
+		return
+	}
+
+	err = h.jenisSimpananService.SetJenisSimpananActive(uint(id), userID.(uint), req.IsActive)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	status := "deactivated"
+	if req.IsActive {
+		status = "activated"
+	}
+
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Jenis simpanan "+status+" successfully"))
+}
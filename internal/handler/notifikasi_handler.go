@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"koperasi-service/internal/service"
+	"koperasi-service/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NotifikasiHandler exposes the requestor's notifications
+type NotifikasiHandler struct {
+	service *service.NotifikasiService
+}
+
+// NewNotifikasiHandler returns a new NotifikasiHandler
+func NewNotifikasiHandler(s *service.NotifikasiService) *NotifikasiHandler {
+	return &NotifikasiHandler{service: s}
+}
+
+// List returns the requestor's notifications (?unread=true for unread only)
+func (h *NotifikasiHandler) List(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	list, err := h.service.List(userID, c.Query("unread") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// MarkRead marks a notification as read
+func (h *NotifikasiHandler) MarkRead(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	if err := h.service.MarkRead(userID, uint(id64)); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Notifikasi marked as read"))
+}
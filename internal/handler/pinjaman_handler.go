@@ -1,14 +1,20 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"koperasi-service/internal/model"
 	"koperasi-service/internal/service"
+	"koperasi-service/pkg/money"
 	"koperasi-service/pkg/utils"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // PinjamanHandler exposes pinjaman CRUD endpoints
@@ -27,13 +33,17 @@ func (h *PinjamanHandler) Create(c *gin.Context) {
 	role := c.GetString("role")
 
 	var input struct {
-		KodePinjaman   string  `json:"kode_pinjaman"`
-		UserID         uint    `json:"user_id"`
-		JumlahPinjaman float64 `json:"jumlah_pinjaman" binding:"required,gt=0"`
-		BungaPersen    float64 `json:"bunga_persen" binding:"required,gte=0"`
-		LamaBulan      int     `json:"lama_bulan" binding:"required,gt=0"`
-		JumlahAngsuran float64 `json:"jumlah_angsuran" binding:"required,gt=0"`
-		Status         string  `json:"status"`
+		KodePinjaman        string      `json:"kode_pinjaman"`
+		UserID              uint        `json:"user_id"`
+		JenisPinjamanID     *uint       `json:"jenis_pinjaman_id"`
+		JumlahPinjaman      money.Money `json:"jumlah_pinjaman" binding:"required,gt=0"`
+		BungaOptionID       *uint       `json:"bunga_option_id"`
+		BungaPersen         float64     `json:"bunga_persen" binding:"gte=0"`
+		MetodeBunga         string      `json:"metode_bunga"`
+		LamaBulan           int         `json:"lama_bulan" binding:"required,gt=0"`
+		NoRekeningPencairan string      `json:"no_rekening_pencairan"`
+		BankName            string      `json:"bank_name"`
+		PenghasilanBulanan  money.Money `json:"penghasilan_bulanan" binding:"gte=0"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -41,23 +51,131 @@ func (h *PinjamanHandler) Create(c *gin.Context) {
 		return
 	}
 
+	// The rate comes from the product or option when one is selected
+	if input.JenisPinjamanID == nil && input.BungaOptionID == nil && input.BungaPersen == 0 {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("jenis_pinjaman_id, bunga_option_id or bunga_persen is required"))
+		return
+	}
+	if input.MetodeBunga != "" && !model.ValidMetodeBunga[input.MetodeBunga] {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid metode_bunga"))
+		return
+	}
+
 	// If UserID not specified, default to requestor's ID
 	if input.UserID == 0 {
 		input.UserID = userID
 	}
 
 	p := &model.Pinjaman{
-		KodePinjaman:   input.KodePinjaman,
-		UserID:         input.UserID,
-		JumlahPinjaman: input.JumlahPinjaman,
-		BungaPersen:    input.BungaPersen,
-		LamaBulan:      input.LamaBulan,
-		JumlahAngsuran: input.JumlahAngsuran,
-		Status:         input.Status,
+		KodePinjaman:        input.KodePinjaman,
+		UserID:              input.UserID,
+		JenisPinjamanID:     input.JenisPinjamanID,
+		JumlahPinjaman:      input.JumlahPinjaman,
+		BungaOptionID:       input.BungaOptionID,
+		BungaPersen:         input.BungaPersen,
+		MetodeBunga:         input.MetodeBunga,
+		LamaBulan:           input.LamaBulan,
+		NoRekeningPencairan: input.NoRekeningPencairan,
+		BankName:            input.BankName,
+		PenghasilanBulanan:  input.PenghasilanBulanan,
 	}
 
 	if err := h.service.Create(userID, role, p); err != nil {
-		status := http.StatusInternalServerError
+		if respondKelayakanError(c, err) {
+			return
+		}
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, utils.ResponseSuccess("Pinjaman created"))
+}
+
+// Kelayakan checks a loan application against the eligibility rule without saving it
+func (h *PinjamanHandler) Kelayakan(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	var input struct {
+		UserID             uint        `json:"user_id"`
+		JenisPinjamanID    *uint       `json:"jenis_pinjaman_id"`
+		JumlahPinjaman     money.Money `json:"jumlah_pinjaman" binding:"required,gt=0"`
+		BungaOptionID      *uint       `json:"bunga_option_id"`
+		BungaPersen        float64     `json:"bunga_persen" binding:"gte=0"`
+		MetodeBunga        string      `json:"metode_bunga"`
+		LamaBulan          int         `json:"lama_bulan" binding:"required,gt=0"`
+		PenghasilanBulanan money.Money `json:"penghasilan_bulanan" binding:"gte=0"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+	if input.MetodeBunga != "" && !model.ValidMetodeBunga[input.MetodeBunga] {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid metode_bunga"))
+		return
+	}
+	if input.UserID == 0 {
+		input.UserID = userID
+	}
+
+	hasil, err := h.service.CekKelayakan(userID, role, &model.Pinjaman{
+		UserID:             input.UserID,
+		JenisPinjamanID:    input.JenisPinjamanID,
+		JumlahPinjaman:     input.JumlahPinjaman,
+		BungaOptionID:      input.BungaOptionID,
+		BungaPersen:        input.BungaPersen,
+		MetodeBunga:        input.MetodeBunga,
+		LamaBulan:          input.LamaBulan,
+		PenghasilanBulanan: input.PenghasilanBulanan,
+	})
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": hasil})
+}
+
+// respondKelayakanError writes the reasons of a failed eligibility check and
+// reports whether err was one
+func respondKelayakanError(c *gin.Context, err error) bool {
+	var kelayakanErr *service.KelayakanError
+	if !errors.As(err, &kelayakanErr) {
+		return false
+	}
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"error":  err.Error(),
+		"alasan": kelayakanErr.Hasil.Alasan,
+		"data":   kelayakanErr.Hasil,
+	})
+	return true
+}
+
+// Jadwal returns the amortization schedule of a loan
+func (h *PinjamanHandler) Jadwal(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+	idParam := c.Param("id")
+
+	id64, err := strconv.ParseUint(idParam, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	versi := 0
+	if v := c.Query("versi"); v != "" {
+		versi, err = strconv.Atoi(v)
+		if err != nil || versi <= 0 {
+			c.JSON(http.StatusBadRequest, utils.ResponseError("invalid versi"))
+			return
+		}
+	}
+
+	jadwal, err := h.service.GetJadwal(userID, role, uint(id64), versi)
+	if err != nil {
+		status := http.StatusNotFound
 		if err.Error() == "forbidden" {
 			status = http.StatusForbidden
 		}
@@ -65,7 +183,7 @@ func (h *PinjamanHandler) Create(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, utils.ResponseSuccess("Pinjaman created"))
+	c.JSON(http.StatusOK, gin.H{"data": jadwal})
 }
 
 // List returns filtered list of loans based on role
@@ -120,12 +238,11 @@ func (h *PinjamanHandler) Update(c *gin.Context) {
 	}
 
 	var input struct {
-		JumlahPinjaman float64 `json:"jumlah_pinjaman"`
-		BungaPersen    float64 `json:"bunga_persen"`
-		LamaBulan      int     `json:"lama_bulan"`
-		JumlahAngsuran float64 `json:"jumlah_angsuran"`
-		SisaAngsuran   int     `json:"sisa_angsuran"`
-		Status         string  `json:"status"`
+		JumlahPinjaman     money.Money `json:"jumlah_pinjaman"`
+		BungaPersen        float64     `json:"bunga_persen"`
+		LamaBulan          int         `json:"lama_bulan"`
+		MetodeBunga        string      `json:"metode_bunga"`
+		PenghasilanBulanan money.Money `json:"penghasilan_bulanan"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -133,37 +250,738 @@ func (h *PinjamanHandler) Update(c *gin.Context) {
 		return
 	}
 
-	// Validate status if provided
-	if input.Status != "" {
-		validStatuses := map[string]bool{"proses": true, "disetujui": true, "lunas": true, "macet": true}
-		if !validStatuses[input.Status] {
-			c.JSON(http.StatusBadRequest, utils.ResponseError("invalid status"))
-			return
-		}
+	if input.MetodeBunga != "" && !model.ValidMetodeBunga[input.MetodeBunga] {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid metode_bunga"))
+		return
 	}
 
 	payload := &model.Pinjaman{
-		JumlahPinjaman: input.JumlahPinjaman,
-		BungaPersen:    input.BungaPersen,
-		LamaBulan:      input.LamaBulan,
-		JumlahAngsuran: input.JumlahAngsuran,
-		SisaAngsuran:   input.SisaAngsuran,
-		Status:         input.Status,
+		JumlahPinjaman:     input.JumlahPinjaman,
+		BungaPersen:        input.BungaPersen,
+		LamaBulan:          input.LamaBulan,
+		MetodeBunga:        input.MetodeBunga,
+		PenghasilanBulanan: input.PenghasilanBulanan,
 	}
 
 	updated, err := h.service.Update(userID, role, uint(id64), payload)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err.Error() == "forbidden" {
-			status = http.StatusForbidden
+		if respondKelayakanError(c, err) {
+			return
 		}
-		c.JSON(status, utils.ResponseError(err.Error()))
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": updated})
 }
 
+// Denda previews the late-payment penalty of a loan (?tanggal=YYYY-MM-DD, default today)
+func (h *PinjamanHandler) Denda(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	tanggal := time.Now()
+	if param := c.Query("tanggal"); param != "" {
+		tanggal, err = time.ParseInLocation("2006-01-02", param, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ResponseError("invalid tanggal, use YYYY-MM-DD"))
+			return
+		}
+	}
+
+	preview, err := h.service.PreviewDenda(userID, role, uint(id64), tanggal)
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": preview})
+}
+
+// Pelunasan quotes the early-settlement amount of a loan (?tanggal=YYYY-MM-DD, default today)
+func (h *PinjamanHandler) Pelunasan(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	tanggal := time.Now()
+	if param := c.Query("tanggal"); param != "" {
+		tanggal, err = time.ParseInLocation("2006-01-02", param, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ResponseError("invalid tanggal, use YYYY-MM-DD"))
+			return
+		}
+	}
+
+	quote, err := h.service.QuotePelunasan(userID, role, uint(id64), tanggal)
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": quote})
+}
+
+// Lunasi executes the early settlement of a loan (admin only)
+func (h *PinjamanHandler) Lunasi(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	var input struct {
+		Tanggal            string       `json:"tanggal"` // YYYY-MM-DD, default today
+		Total              *money.Money `json:"total"`   // Quoted total to confirm
+		NoRekening         string       `json:"no_rekening"`
+		BankName           string       `json:"bank_name"`
+		ImageBuktiTransfer string       `json:"image_bukti_transfer"`
+		BuktiTransferID    *uint        `json:"bukti_transfer_id"` // Uploaded receipt from POST /berkas
+	}
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	tanggal := time.Now()
+	if input.Tanggal != "" {
+		tanggal, err = time.ParseInLocation("2006-01-02", input.Tanggal, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ResponseError("invalid tanggal, use YYYY-MM-DD"))
+			return
+		}
+	}
+
+	a, err := h.service.Lunasi(userID, role, uint(id64), service.PelunasanInput{
+		Tanggal:            tanggal,
+		Total:              input.Total,
+		NoRekening:         input.NoRekening,
+		BankName:           input.BankName,
+		ImageBuktiTransfer: input.ImageBuktiTransfer,
+		BuktiTransferID:    input.BuktiTransferID,
+	})
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Pinjaman lunas",
+		"data":    a,
+	})
+}
+
+// AjukanRestrukturisasi submits a restructuring request for a disbursed loan
+func (h *PinjamanHandler) AjukanRestrukturisasi(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	var input struct {
+		LamaBulan             int     `json:"lama_bulan" binding:"required"`
+		BungaPersen           float64 `json:"bunga_persen"`
+		MetodeBunga           string  `json:"metode_bunga"`
+		MasaTenggangBulan     int     `json:"masa_tenggang_bulan"`
+		KapitalisasiTunggakan bool    `json:"kapitalisasi_tunggakan"`
+		Alasan                string  `json:"alasan" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	rs, err := h.service.AjukanRestrukturisasi(userID, role, uint(id64), service.RestrukturisasiInput{
+		LamaBulan:             input.LamaBulan,
+		BungaPersen:           input.BungaPersen,
+		MetodeBunga:           input.MetodeBunga,
+		MasaTenggangBulan:     input.MasaTenggangBulan,
+		KapitalisasiTunggakan: input.KapitalisasiTunggakan,
+		Alasan:                input.Alasan,
+	})
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Restrukturisasi submitted",
+		"data":    rs,
+	})
+}
+
+// ListRestrukturisasi returns the restructuring requests of a loan
+func (h *PinjamanHandler) ListRestrukturisasi(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	list, err := h.service.ListRestrukturisasi(userID, role, uint(id64))
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// PendingRestrukturisasi returns restructuring requests awaiting a decision (admin only)
+func (h *PinjamanHandler) PendingRestrukturisasi(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	list, err := h.service.GetPendingRestrukturisasi(userID, role)
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// SetujuiRestrukturisasi approves a restructuring request and applies the new schedule
+func (h *PinjamanHandler) SetujuiRestrukturisasi(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	rs, err := h.service.SetujuiRestrukturisasi(userID, role, uint(id64))
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Restrukturisasi approved",
+		"data":    rs,
+	})
+}
+
+// TolakRestrukturisasi rejects a restructuring request
+func (h *PinjamanHandler) TolakRestrukturisasi(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	var input struct {
+		Alasan string `json:"alasan" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	rs, err := h.service.TolakRestrukturisasi(userID, role, uint(id64), input.Alasan)
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Restrukturisasi rejected",
+		"data":    rs,
+	})
+}
+
+// Aging returns active loans grouped by collectibility bucket (admin only)
+func (h *PinjamanHandler) Aging(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	report, err := h.service.GetAging(userID, role)
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// Kolektibilitas returns the collectibility history of a loan
+func (h *PinjamanHandler) Kolektibilitas(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	riwayat, err := h.service.GetRiwayatKolektibilitas(userID, role, uint(id64))
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": riwayat})
+}
+
+// RunKolektibilitas runs the daily collectibility classification now (super admin only)
+func (h *PinjamanHandler) RunKolektibilitas(c *gin.Context) {
+	role := c.GetString("role")
+
+	changed, err := h.service.RunKlasifikasiKolektibilitas(role)
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Kolektibilitas classification finished",
+		"data":    gin.H{"changed": changed},
+	})
+}
+
+// Approve moves a loan from proses to disetujui
+func (h *PinjamanHandler) Approve(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	p, err := h.service.Approve(userID, role, uint(id64))
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": p})
+}
+
+// Reject moves a loan from proses to ditolak
+func (h *PinjamanHandler) Reject(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	var input struct {
+		Alasan string `json:"alasan" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	p, err := h.service.Reject(userID, role, uint(id64), input.Alasan)
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": p})
+}
+
+// Disburse records the disbursement of an approved loan
+func (h *PinjamanHandler) Disburse(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	var input struct {
+		ReferensiPencairan  string      `json:"referensi_pencairan" binding:"required"`
+		NoRekeningPencairan string      `json:"no_rekening_pencairan"`
+		BankName            string      `json:"bank_name"`
+		ImageBuktiTransfer  string      `json:"image_bukti_transfer"`
+		BuktiTransferID     *uint       `json:"bukti_transfer_id"` // Uploaded receipt from POST /berkas
+		PremiAsuransi       money.Money `json:"premi_asuransi"`
+		LunasiPinjamanID    *uint       `json:"lunasi_pinjaman_id"` // Previous loan paid off from the disbursement
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	p, pc, err := h.service.Disburse(userID, role, uint(id64), service.PencairanInput{
+		ReferensiTransfer:  input.ReferensiPencairan,
+		NoRekening:         input.NoRekeningPencairan,
+		BankName:           input.BankName,
+		ImageBuktiTransfer: input.ImageBuktiTransfer,
+		BuktiTransferID:    input.BuktiTransferID,
+		PremiAsuransi:      input.PremiAsuransi,
+		LunasiPinjamanID:   input.LunasiPinjamanID,
+	})
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": p, "pencairan": pc})
+}
+
+// GetPencairan returns the disbursement record of a loan
+func (h *PinjamanHandler) GetPencairan(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	pc, err := h.service.GetPencairan(userID, role, uint(id64))
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": pc})
+}
+
+// WriteOff marks a disbursed loan as macet (super admin only)
+func (h *PinjamanHandler) WriteOff(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	var input struct {
+		Alasan string `json:"alasan" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	p, err := h.service.WriteOff(userID, role, uint(id64), input.Alasan)
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": p})
+}
+
+// ListPenjamin returns the guarantors of a loan
+func (h *PinjamanHandler) ListPenjamin(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	list, err := h.service.ListPenjamin(userID, role, uint(id64))
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// TambahPenjamin adds a co-member as guarantor of a loan
+func (h *PinjamanHandler) TambahPenjamin(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	var input struct {
+		UserID     uint   `json:"user_id" binding:"required"`
+		Hubungan   string `json:"hubungan"`
+		Keterangan string `json:"keterangan"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	pj, err := h.service.TambahPenjamin(userID, role, uint(id64), &model.Penjamin{
+		UserID:     input.UserID,
+		Hubungan:   input.Hubungan,
+		Keterangan: input.Keterangan,
+	})
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": pj})
+}
+
+// HapusPenjamin removes a guarantor from a loan
+func (h *PinjamanHandler) HapusPenjamin(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+	penjaminID, err := strconv.ParseUint(c.Param("penjaminId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid penjamin id"))
+		return
+	}
+
+	if err := h.service.HapusPenjamin(userID, role, uint(id64), uint(penjaminID)); err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Penjamin removed"))
+}
+
+// ListAgunan returns the collateral of a loan
+func (h *PinjamanHandler) ListAgunan(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	list, err := h.service.ListAgunan(userID, role, uint(id64))
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// TambahAgunan records a collateral item for a loan
+func (h *PinjamanHandler) TambahAgunan(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	var input struct {
+		Jenis         string      `json:"jenis" binding:"required"`
+		NomorDokumen  string      `json:"nomor_dokumen"`
+		AtasNama      string      `json:"atas_nama"`
+		Deskripsi     string      `json:"deskripsi"`
+		NilaiTaksiran money.Money `json:"nilai_taksiran"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	a, err := h.service.TambahAgunan(userID, role, uint(id64), &model.Agunan{
+		Jenis:         input.Jenis,
+		NomorDokumen:  input.NomorDokumen,
+		AtasNama:      input.AtasNama,
+		Deskripsi:     input.Deskripsi,
+		NilaiTaksiran: input.NilaiTaksiran,
+	})
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": a})
+}
+
+// HapusAgunan removes a collateral item from a loan
+func (h *PinjamanHandler) HapusAgunan(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+	agunanID, err := strconv.ParseUint(c.Param("agunanId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid agunan id"))
+		return
+	}
+
+	if err := h.service.HapusAgunan(userID, role, uint(id64), uint(agunanID)); err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.ResponseSuccess("Agunan removed"))
+}
+
+// AutoDebet returns the auto-debit mandate of a loan and its attempts
+func (h *PinjamanHandler) AutoDebet(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	info, err := h.service.GetAutoDebet(userID, role, uint(id64))
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": info})
+}
+
+// AktifkanAutoDebet opts a loan into auto-debit from the sukarela wallet
+func (h *PinjamanHandler) AktifkanAutoDebet(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	m, err := h.service.AktifkanAutoDebet(userID, role, uint(id64))
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": m})
+}
+
+// CabutAutoDebet revokes the auto-debit mandate of a loan
+func (h *PinjamanHandler) CabutAutoDebet(c *gin.Context) {
+	userID := c.GetUint("userID")
+	role := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	m, err := h.service.CabutAutoDebet(userID, role, uint(id64))
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": m})
+}
+
+// RunAutoDebet runs the daily auto-debit job now (super admin only)
+func (h *PinjamanHandler) RunAutoDebet(c *gin.Context) {
+	role := c.GetString("role")
+
+	berhasil, gagal, err := h.service.RunAutoDebet(role)
+	if err != nil {
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Auto debet finished",
+		"data":    gin.H{"berhasil": berhasil, "gagal": gagal},
+	})
+}
+
+// pinjamanErrorStatus maps loan service errors to HTTP status codes
+func pinjamanErrorStatus(err error) int {
+	switch {
+	case err.Error() == "forbidden":
+		return http.StatusForbidden
+	case errors.Is(err, gorm.ErrRecordNotFound),
+		strings.HasSuffix(err.Error(), "does not belong to this pinjaman"):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidStatusTransition),
+		errors.Is(err, service.ErrPelunasanBerubah):
+		return http.StatusConflict
+	case strings.HasPrefix(err.Error(), "pinjaman can only be edited"),
+		strings.HasPrefix(err.Error(), "only pinjaman in proses"),
+		strings.HasPrefix(err.Error(), "only dicairkan or macet"),
+		strings.HasPrefix(err.Error(), "pinjaman already has a pending"),
+		strings.HasPrefix(err.Error(), "pinjaman has a pending angsuran"),
+		strings.HasPrefix(err.Error(), "pinjaman has no remaining pokok"),
+		strings.HasPrefix(err.Error(), "restrukturisasi has already been decided"),
+		strings.HasPrefix(err.Error(), "pinjaman requires"),
+		strings.HasPrefix(err.Error(), "only running pinjaman"):
+		return http.StatusConflict
+	case strings.HasPrefix(err.Error(), "penjamin "):
+		return http.StatusUnprocessableEntity
+	case strings.HasSuffix(err.Error(), "must be positive"),
+		strings.HasSuffix(err.Error(), "must not be negative"),
+		err.Error() == "invalid metode bunga",
+		err.Error() == "invalid jenis agunan":
+		return http.StatusBadRequest
+	case strings.HasPrefix(err.Error(), "jenis pinjaman"),
+		strings.HasPrefix(err.Error(), "bunga option"),
+		strings.Contains(err.Error(), "the jenis pinjaman"),
+		strings.HasPrefix(err.Error(), "the pelunasan pinjaman"):
+		return http.StatusBadRequest
+	case strings.HasPrefix(err.Error(), "tanggal pelunasan"),
+		strings.HasPrefix(err.Error(), "bukti transfer"):
+		return http.StatusBadRequest
+	case strings.HasSuffix(err.Error(), "is required"):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
 // Delete removes a loan
 func (h *PinjamanHandler) Delete(c *gin.Context) {
 	userID := c.GetUint("userID")
@@ -177,11 +995,7 @@ func (h *PinjamanHandler) Delete(c *gin.Context) {
 	}
 
 	if err := h.service.Delete(userID, role, uint(id64)); err != nil {
-		status := http.StatusInternalServerError
-		if err.Error() == "forbidden" {
-			status = http.StatusForbidden
-		}
-		c.JSON(status, utils.ResponseError(err.Error()))
+		c.JSON(pinjamanErrorStatus(err), utils.ResponseError(err.Error()))
 		return
 	}
 
@@ -186,6 +186,8 @@ func (h *SHUAnggotaHandler) Delete(c *gin.Context) {
 			status = http.StatusForbidden
 		} else if err.Error() == "SHU Anggota record not found" {
 			status = http.StatusNotFound
+		} else if err.Error() == "SHU Anggota record already credited" {
+			status = http.StatusConflict
 		}
 		c.JSON(status, utils.ResponseError(err.Error()))
 		return
@@ -193,3 +195,37 @@ func (h *SHUAnggotaHandler) Delete(c *gin.Context) {
 
 	c.JSON(http.StatusOK, utils.ResponseSuccess("SHU Anggota record deleted"))
 }
+
+// Kreditkan credits a saved SHU record to the member's sukarela wallet (admin only)
+func (h *SHUAnggotaHandler) Kreditkan(c *gin.Context) {
+	role := c.GetString("role")
+	requestorUserID := c.GetUint("userID")
+	idParam := c.Param("id")
+
+	id64, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	shuAnggota, err := h.service.Kreditkan(role, requestorUserID, uint(id64))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "forbidden" {
+			status = http.StatusForbidden
+		} else if err.Error() == "SHU Anggota record not found" {
+			status = http.StatusNotFound
+		} else if err.Error() == "SHU Anggota record already credited" || err.Error() == "SHU is not final yet" || err.Error() == "member has no sukarela wallet" {
+			status = http.StatusConflict
+		} else if err.Error() == "shu diterima must be greater than 0" {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "SHU credited to the sukarela wallet",
+		"data":    shuAnggota,
+	})
+}
@@ -6,6 +6,7 @@ import (
 
 	"koperasi-service/internal/model"
 	"koperasi-service/internal/service"
+	"koperasi-service/pkg/money"
 	"koperasi-service/pkg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -26,8 +27,8 @@ func (h *SHUHandler) GenerateReport(c *gin.Context) {
 	role := c.GetString("role")
 
 	var input struct {
-		Tahun            int     `json:"tahun" binding:"required,min=2000,max=2100"`
-		TotalSHUKoperasi float64 `json:"total_shu_koperasi" binding:"required,gt=0"`
+		Tahun            int         `json:"tahun" binding:"required,min=2000,max=2100"`
+		TotalSHUKoperasi money.Money `json:"total_shu_koperasi" binding:"required,gt=0"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -56,9 +57,9 @@ func (h *SHUHandler) SaveSHU(c *gin.Context) {
 	role := c.GetString("role")
 
 	var input struct {
-		Tahun    int     `json:"tahun" binding:"required,min=2000,max=2100"`
-		TotalSHU float64 `json:"total_shu" binding:"required,gt=0"`
-		Status   string  `json:"status"`
+		Tahun    int         `json:"tahun" binding:"required,min=2000,max=2100"`
+		TotalSHU money.Money `json:"total_shu" binding:"required,gt=0"`
+		Status   string      `json:"status"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -137,8 +138,8 @@ func (h *SHUHandler) Update(c *gin.Context) {
 	}
 
 	var input struct {
-		TotalSHU float64 `json:"total_shu"`
-		Status   string  `json:"status"`
+		TotalSHU money.Money `json:"total_shu"`
+		Status   string      `json:"status"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -265,10 +266,10 @@ func (h *SHUHandler) GenerateReportWithExpenses(c *gin.Context) {
 	role := c.GetString("role")
 
 	var input struct {
-		Tahun               int     `json:"tahun" binding:"required,min=2000,max=2100"`
-		BebanOperasional    float64 `json:"beban_operasional" binding:"min=0"`
-		BebanNonOperasional float64 `json:"beban_non_operasional" binding:"min=0"`
-		BebanPajak          float64 `json:"beban_pajak" binding:"min=0"`
+		Tahun               int         `json:"tahun" binding:"required,min=2000,max=2100"`
+		BebanOperasional    money.Money `json:"beban_operasional" binding:"min=0"`
+		BebanNonOperasional money.Money `json:"beban_non_operasional" binding:"min=0"`
+		BebanPajak          money.Money `json:"beban_pajak" binding:"min=0"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -297,14 +298,14 @@ func (h *SHUHandler) SaveSHUWithExpenses(c *gin.Context) {
 	role := c.GetString("role")
 
 	var input struct {
-		Tahun                    int     `json:"tahun" binding:"required,min=2000,max=2100"`
-		PendapatanOperasional    float64 `json:"pendapatan_operasional" binding:"min=0"`
-		PendapatanNonOperasional float64 `json:"pendapatan_non_operasional" binding:"min=0"`
-		BebanOperasional         float64 `json:"beban_operasional" binding:"min=0"`
-		BebanNonOperasional      float64 `json:"beban_non_operasional" binding:"min=0"`
-		BebanPajak               float64 `json:"beban_pajak" binding:"min=0"`
-		TotalSHU                 float64 `json:"total_shu" binding:"required,gt=0"`
-		Status                   string  `json:"status"`
+		Tahun                    int         `json:"tahun" binding:"required,min=2000,max=2100"`
+		PendapatanOperasional    money.Money `json:"pendapatan_operasional" binding:"min=0"`
+		PendapatanNonOperasional money.Money `json:"pendapatan_non_operasional" binding:"min=0"`
+		BebanOperasional         money.Money `json:"beban_operasional" binding:"min=0"`
+		BebanNonOperasional      money.Money `json:"beban_non_operasional" binding:"min=0"`
+		BebanPajak               money.Money `json:"beban_pajak" binding:"min=0"`
+		TotalSHU                 money.Money `json:"total_shu" binding:"required,gt=0"`
+		Status                   string      `json:"status"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
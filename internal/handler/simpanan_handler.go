@@ -1,13 +1,20 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"koperasi-service/internal/model"
 	"koperasi-service/internal/service"
+	"koperasi-service/pkg/money"
 	"koperasi-service/pkg/utils"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type SimpananHandler struct {
@@ -18,7 +25,7 @@ func NewSimpananHandler(svc *service.SimpananService) *SimpananHandler {
 	return &SimpananHandler{service: svc}
 }
 
-// GetWallets returns user wallets (all 3 types)
+// GetWallets returns user wallets (one per jenis simpanan)
 func (h *SimpananHandler) GetWallets(c *gin.Context) {
 	requestorID := c.GetUint("userID")
 	requestorRole := c.GetString("role")
@@ -73,9 +80,10 @@ func (h *SimpananHandler) TopupWallet(c *gin.Context) {
 	userID := c.GetUint("userID")
 
 	var input struct {
-		Type        string  `json:"type" binding:"required,oneof=pokok wajib sukarela"`
-		Amount      float64 `json:"amount" binding:"required,gt=0"`
-		Description string  `json:"description"`
+		Type            string      `json:"type" binding:"required"` // Kode of an active jenis simpanan
+		Amount          money.Money `json:"amount" binding:"gte=0"`  // Omitted or 0 uses the type's nominal default
+		Description     string      `json:"description"`
+		BuktiTransferID *uint       `json:"bukti_transfer_id"` // Uploaded receipt from POST /berkas
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -83,8 +91,19 @@ func (h *SimpananHandler) TopupWallet(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.TopupWallet(userID, input.Type, input.Amount, input.Description); err != nil {
-		c.JSON(http.StatusInternalServerError, utils.ResponseError(err.Error()))
+	if err := h.service.TopupWallet(userID, input.Type, input.Amount, input.Description, input.BuktiTransferID); err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "simpanan pokok has already been paid" || err.Error() == "a simpanan pokok top-up is already pending" || err.Error() == "membership has ended" {
+			status = http.StatusConflict
+		} else if strings.HasPrefix(err.Error(), "simpanan pokok must be") || strings.Contains(err.Error(), "top-up must be at least") ||
+			err.Error() == "invalid wallet type" || err.Error() == "amount must be positive" {
+			status = http.StatusBadRequest
+		} else if err.Error() == "wallet not found" {
+			status = http.StatusNotFound
+		} else if strings.HasPrefix(err.Error(), "bukti transfer") {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, utils.ResponseError(err.Error()))
 		return
 	}
 
@@ -166,6 +185,8 @@ func (h *SimpananHandler) VerifyTransaction(c *gin.Context) {
 		status := http.StatusInternalServerError
 		if err.Error() == "forbidden" {
 			status = http.StatusForbidden
+		} else if err.Error() == "simpanan pokok has already been paid" {
+			status = http.StatusConflict
 		}
 		c.JSON(status, utils.ResponseError(err.Error()))
 		return
@@ -191,8 +212,8 @@ func (h *SimpananHandler) AdjustWallet(c *gin.Context) {
 	}
 
 	var input struct {
-		Amount      float64 `json:"amount" binding:"required"`
-		Description string  `json:"description" binding:"required"`
+		Amount      money.Money `json:"amount" binding:"required"`
+		Description string      `json:"description" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -204,6 +225,8 @@ func (h *SimpananHandler) AdjustWallet(c *gin.Context) {
 		status := http.StatusInternalServerError
 		if err.Error() == "forbidden" {
 			status = http.StatusForbidden
+		} else if err.Error() == "insufficient balance" || err.Error() == "simpanan pokok can only be refunded through resignation" {
+			status = http.StatusBadRequest
 		}
 		c.JSON(status, utils.ResponseError(err.Error()))
 		return
@@ -228,3 +251,889 @@ func (h *SimpananHandler) GetPendingTransactions(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"data": transactions})
 }
+
+// GetWalletBalance returns a wallet's ledger balance, optionally as of a date
+func (h *SimpananHandler) GetWalletBalance(c *gin.Context) {
+	requestorID := c.GetUint("userID")
+	requestorRole := c.GetString("role")
+
+	idParam := c.Param("id")
+	id64, err := strconv.ParseUint(idParam, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid wallet id"))
+		return
+	}
+
+	// as_of is a date; the balance includes everything posted on that day
+	var asOf *time.Time
+	if asOfStr := c.Query("as_of"); asOfStr != "" {
+		date, err := time.ParseInLocation("2006-01-02", asOfStr, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ResponseError("invalid as_of, use YYYY-MM-DD"))
+			return
+		}
+		endOfDay := date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		asOf = &endOfDay
+	}
+
+	balance, err := h.service.GetWalletBalance(uint(id64), asOf, requestorID, requestorRole)
+	if err != nil {
+		status := http.StatusNotFound
+		if err.Error() == "forbidden" {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": balance})
+}
+
+// GetWalletLedger returns the ledger entries of a wallet
+func (h *SimpananHandler) GetWalletLedger(c *gin.Context) {
+	requestorID := c.GetUint("userID")
+	requestorRole := c.GetString("role")
+
+	idParam := c.Param("id")
+	id64, err := strconv.ParseUint(idParam, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid wallet id"))
+		return
+	}
+
+	entries, err := h.service.GetWalletLedger(uint(id64), requestorID, requestorRole)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "forbidden" {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": entries})
+}
+
+// ReverseJournal corrects a posted ledger journal by reversing it (admin only)
+func (h *SimpananHandler) ReverseJournal(c *gin.Context) {
+	adminID := c.GetUint("userID")
+	adminRole := c.GetString("role")
+
+	idParam := c.Param("id")
+	id64, err := strconv.ParseUint(idParam, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid journal id"))
+		return
+	}
+
+	var input struct {
+		Description string `json:"description" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	reversal, err := h.service.ReverseJournal(uint(id64), input.Description, adminID, adminRole)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "forbidden" {
+			status = http.StatusForbidden
+		} else if err.Error() == "journal already reversed" || strings.HasSuffix(err.Error(), "cannot be reversed") || err.Error() == "insufficient balance" || err.Error() == "simpanan pokok can only be refunded through resignation" {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Journal reversed",
+		"data":    reversal,
+	})
+}
+
+// penarikanErrorStatus maps withdrawal errors to HTTP status codes
+func penarikanErrorStatus(err error) int {
+	switch {
+	case err.Error() == "forbidden":
+		return http.StatusForbidden
+	case errors.Is(err, gorm.ErrRecordNotFound), err.Error() == "wallet not found":
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "penarikan can only be"):
+		return http.StatusConflict
+	case strings.HasSuffix(err.Error(), "cannot be withdrawn"),
+		err.Error() == "invalid wallet type",
+		err.Error() == "insufficient balance",
+		strings.HasSuffix(err.Error(), "must be positive"),
+		strings.HasSuffix(err.Error(), "is required"):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// AjukanPenarikan requests a withdrawal from one of the member's withdrawable wallets
+func (h *SimpananHandler) AjukanPenarikan(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var input struct {
+		WalletType string      `json:"wallet_type"` // Defaults to sukarela; the type must be withdrawable
+		Jumlah     money.Money `json:"jumlah" binding:"required,gt=0"`
+		NoRekening string      `json:"no_rekening" binding:"required"`
+		BankName   string      `json:"bank_name" binding:"required"`
+		AtasNama   string      `json:"atas_nama"`
+		Keterangan string      `json:"keterangan"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	p, err := h.service.AjukanPenarikan(userID, service.PenarikanInput{
+		WalletType: input.WalletType,
+		Jumlah:     input.Jumlah,
+		NoRekening: input.NoRekening,
+		BankName:   input.BankName,
+		AtasNama:   input.AtasNama,
+		Keterangan: input.Keterangan,
+	})
+	if err != nil {
+		c.JSON(penarikanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Withdrawal request created, waiting for admin approval",
+		"data":    p,
+	})
+}
+
+// ListPenarikan returns withdrawal requests; members only see their own
+func (h *SimpananHandler) ListPenarikan(c *gin.Context) {
+	requestorID := c.GetUint("userID")
+	requestorRole := c.GetString("role")
+
+	list, err := h.service.ListPenarikan(requestorID, requestorRole, c.Query("status"))
+	if err != nil {
+		c.JSON(penarikanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// GetPenarikan returns one withdrawal request
+func (h *SimpananHandler) GetPenarikan(c *gin.Context) {
+	requestorID := c.GetUint("userID")
+	requestorRole := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	p, err := h.service.GetPenarikan(requestorID, requestorRole, uint(id64))
+	if err != nil {
+		c.JSON(penarikanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": p})
+}
+
+// SetujuiPenarikan approves a pending withdrawal (admin only)
+func (h *SimpananHandler) SetujuiPenarikan(c *gin.Context) {
+	adminID := c.GetUint("userID")
+	adminRole := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	p, err := h.service.SetujuiPenarikan(adminID, adminRole, uint(id64))
+	if err != nil {
+		c.JSON(penarikanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": p})
+}
+
+// TolakPenarikan rejects a withdrawal and releases the held amount (admin only)
+func (h *SimpananHandler) TolakPenarikan(c *gin.Context) {
+	adminID := c.GetUint("userID")
+	adminRole := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	var input struct {
+		AlasanPenolakan string `json:"alasan_penolakan" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	p, err := h.service.TolakPenarikan(adminID, adminRole, uint(id64), input.AlasanPenolakan)
+	if err != nil {
+		c.JSON(penarikanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": p})
+}
+
+// BayarPenarikan records the transfer of an approved withdrawal (admin only)
+func (h *SimpananHandler) BayarPenarikan(c *gin.Context) {
+	adminID := c.GetUint("userID")
+	adminRole := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	var input struct {
+		ReferensiTransfer  string `json:"referensi_transfer"`
+		ImageBuktiTransfer string `json:"image_bukti_transfer" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	p, err := h.service.BayarPenarikan(adminID, adminRole, uint(id64), service.PembayaranPenarikanInput{
+		ReferensiTransfer:  input.ReferensiTransfer,
+		ImageBuktiTransfer: input.ImageBuktiTransfer,
+	})
+	if err != nil {
+		c.JSON(penarikanErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": p})
+}
+
+// queryUserID returns the user_id query parameter, defaulting to the requestor
+func queryUserID(c *gin.Context) (uint, bool) {
+	userIDParam := c.Query("user_id")
+	if userIDParam == "" {
+		return c.GetUint("userID"), true
+	}
+	id64, err := strconv.ParseUint(userIDParam, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid user_id"))
+		return 0, false
+	}
+	return uint(id64), true
+}
+
+// GetKewajibanWajib returns a member's monthly simpanan wajib obligations
+func (h *SimpananHandler) GetKewajibanWajib(c *gin.Context) {
+	requestorID := c.GetUint("userID")
+	requestorRole := c.GetString("role")
+
+	userID, ok := queryUserID(c)
+	if !ok {
+		return
+	}
+
+	list, err := h.service.GetKewajibanWajib(userID, requestorID, requestorRole)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "forbidden" {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// GetTunggakanWajib returns a member's overdue simpanan wajib
+func (h *SimpananHandler) GetTunggakanWajib(c *gin.Context) {
+	requestorID := c.GetUint("userID")
+	requestorRole := c.GetString("role")
+
+	userID, ok := queryUserID(c)
+	if !ok {
+		return
+	}
+
+	tunggakan, err := h.service.GetTunggakanWajib(userID, requestorID, requestorRole)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "forbidden" {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tunggakan})
+}
+
+// GetRekapTunggakanWajib returns the overdue simpanan wajib of all members (admin only)
+func (h *SimpananHandler) GetRekapTunggakanWajib(c *gin.Context) {
+	requestorRole := c.GetString("role")
+
+	rekap, err := h.service.GetRekapTunggakanWajib(requestorRole)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "forbidden" {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rekap})
+}
+
+// RunKewajibanWajib creates this month's simpanan wajib obligations now (admin only)
+func (h *SimpananHandler) RunKewajibanWajib(c *gin.Context) {
+	requestorRole := c.GetString("role")
+
+	dibuat, err := h.service.RunKewajibanWajib(requestorRole)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "forbidden" {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Kewajiban simpanan wajib created",
+		"data":    gin.H{"dibuat": dibuat},
+	})
+}
+
+// queryPeriode reads ?periode=YYYY-MM as the first day of that month, or def when absent
+func queryPeriode(c *gin.Context, def time.Time) (time.Time, bool) {
+	param := c.Query("periode")
+	if param == "" {
+		return def, true
+	}
+	periode, err := time.ParseInLocation("2006-01", param, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid periode, use YYYY-MM"))
+		return time.Time{}, false
+	}
+	return periode, true
+}
+
+func jasaErrorStatus(err error) int {
+	switch {
+	case err.Error() == "forbidden":
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrTanpaAturanJasa):
+		return http.StatusNotFound
+	case err.Error() == "periode must not be in the future",
+		err.Error() == "jasa simpanan can only be posted after the month has ended":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// PreviewJasaSimpanan computes the month's jasa simpanan without posting it
+// (?periode=YYYY-MM, default the current month; admin only)
+func (h *SimpananHandler) PreviewJasaSimpanan(c *gin.Context) {
+	requestorRole := c.GetString("role")
+
+	periode, ok := queryPeriode(c, time.Now())
+	if !ok {
+		return
+	}
+
+	rekap, err := h.service.PreviewJasaSimpanan(requestorRole, periode)
+	if err != nil {
+		c.JSON(jasaErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rekap})
+}
+
+// RunJasaSimpanan credits the jasa simpanan of an ended month now (admin only)
+func (h *SimpananHandler) RunJasaSimpanan(c *gin.Context) {
+	adminID := c.GetUint("userID")
+	adminRole := c.GetString("role")
+
+	var input struct {
+		Periode string `json:"periode"` // YYYY-MM, default last month
+	}
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+	periode := time.Now().AddDate(0, -1, 0)
+	if input.Periode != "" {
+		var err error
+		periode, err = time.ParseInLocation("2006-01", input.Periode, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ResponseError("invalid periode, use YYYY-MM"))
+			return
+		}
+	}
+
+	rekap, err := h.service.RunJasaSimpanan(adminID, adminRole, periode)
+	if err != nil {
+		c.JSON(jasaErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Jasa simpanan posted",
+		"data":    rekap,
+	})
+}
+
+// ListJasaSimpanan returns posted jasa simpanan (?user_id= for admin, ?periode=YYYY-MM)
+func (h *SimpananHandler) ListJasaSimpanan(c *gin.Context) {
+	requestorID := c.GetUint("userID")
+	requestorRole := c.GetString("role")
+
+	var userID uint
+	if param := c.Query("user_id"); param != "" {
+		id64, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ResponseError("invalid user_id"))
+			return
+		}
+		userID = uint(id64)
+	}
+	var periode *time.Time
+	if c.Query("periode") != "" {
+		p, ok := queryPeriode(c, time.Time{})
+		if !ok {
+			return
+		}
+		periode = &p
+	}
+
+	list, err := h.service.ListJasaSimpanan(requestorID, requestorRole, userID, periode)
+	if err != nil {
+		c.JSON(jasaErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// berjangkaErrorStatus maps simpanan berjangka errors to HTTP status codes
+func berjangkaErrorStatus(err error) int {
+	switch {
+	case err.Error() == "forbidden",
+		err.Error() == "only an active anggota can open a simpanan berjangka":
+		return http.StatusForbidden
+	case errors.Is(err, gorm.ErrRecordNotFound),
+		err.Error() == "wallet not found",
+		err.Error() == "user not found",
+		err.Error() == "jenis simpanan berjangka not found":
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "simpanan berjangka can only be"),
+		err.Error() == "simpanan berjangka has already matured":
+		return http.StatusConflict
+	case err.Error() == "insufficient balance",
+		err.Error() == "jenis simpanan berjangka is not active",
+		strings.HasPrefix(err.Error(), "nominal must be"),
+		strings.HasPrefix(err.Error(), "sumber dana must be"),
+		strings.HasPrefix(err.Error(), "instruksi jatuh tempo must be"):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// BukaBerjangka opens a simpanan berjangka for the member
+func (h *SimpananHandler) BukaBerjangka(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var input struct {
+		JenisID             uint        `json:"jenis_id" binding:"required"`
+		Nominal             money.Money `json:"nominal" binding:"required,gt=0"`
+		SumberDana          string      `json:"sumber_dana"`           // sukarela (default) or transfer
+		InstruksiJatuhTempo string      `json:"instruksi_jatuh_tempo"` // cair (default), perpanjang or perpanjang_pokok
+		Keterangan          string      `json:"keterangan"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	b, err := h.service.BukaBerjangka(userID, service.BukaBerjangkaInput{
+		JenisID:             input.JenisID,
+		Nominal:             input.Nominal,
+		SumberDana:          input.SumberDana,
+		InstruksiJatuhTempo: input.InstruksiJatuhTempo,
+		Keterangan:          input.Keterangan,
+	})
+	if err != nil {
+		c.JSON(berjangkaErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	message := "Simpanan berjangka opened"
+	if b.Status == model.BerjangkaMenunggu {
+		message = "Simpanan berjangka created, waiting for transfer verification"
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message": message,
+		"data":    b,
+	})
+}
+
+// ListBerjangka returns simpanan berjangka (?user_id= for admin, ?status=)
+func (h *SimpananHandler) ListBerjangka(c *gin.Context) {
+	requestorID := c.GetUint("userID")
+	requestorRole := c.GetString("role")
+
+	var userID uint
+	if param := c.Query("user_id"); param != "" {
+		id64, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ResponseError("invalid user_id"))
+			return
+		}
+		userID = uint(id64)
+	}
+
+	list, err := h.service.ListBerjangka(requestorID, requestorRole, userID, c.Query("status"))
+	if err != nil {
+		c.JSON(berjangkaErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// GetBerjangka returns one simpanan berjangka
+func (h *SimpananHandler) GetBerjangka(c *gin.Context) {
+	requestorID := c.GetUint("userID")
+	requestorRole := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	b, err := h.service.GetBerjangka(requestorID, requestorRole, uint(id64))
+	if err != nil {
+		c.JSON(berjangkaErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": b})
+}
+
+// UbahInstruksiBerjangka changes what happens to a simpanan berjangka at maturity
+func (h *SimpananHandler) UbahInstruksiBerjangka(c *gin.Context) {
+	requestorID := c.GetUint("userID")
+	requestorRole := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	var input struct {
+		InstruksiJatuhTempo string `json:"instruksi_jatuh_tempo" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	b, err := h.service.UbahInstruksiBerjangka(requestorID, requestorRole, uint(id64), input.InstruksiJatuhTempo)
+	if err != nil {
+		c.JSON(berjangkaErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": b})
+}
+
+// CairkanDiniBerjangka breaks a simpanan berjangka before maturity, with the early-break penalty
+func (h *SimpananHandler) CairkanDiniBerjangka(c *gin.Context) {
+	requestorID := c.GetUint("userID")
+	requestorRole := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	b, err := h.service.CairkanDiniBerjangka(requestorID, requestorRole, uint(id64))
+	if err != nil {
+		c.JSON(berjangkaErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Simpanan berjangka broken early",
+		"data":    b,
+	})
+}
+
+// RunJatuhTempoBerjangka settles matured simpanan berjangka now (admin only)
+func (h *SimpananHandler) RunJatuhTempoBerjangka(c *gin.Context) {
+	requestorRole := c.GetString("role")
+
+	selesai, err := h.service.RunJatuhTempoBerjangka(requestorRole)
+	if err != nil {
+		c.JSON(berjangkaErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Matured simpanan berjangka settled",
+		"data":    gin.H{"selesai": selesai},
+	})
+}
+
+// transferErrorStatus maps sukarela transfer errors to HTTP status codes
+func transferErrorStatus(err error) int {
+	switch {
+	case err.Error() == "forbidden",
+		err.Error() == "only an active anggota can transfer":
+		return http.StatusForbidden
+	case errors.Is(err, gorm.ErrRecordNotFound),
+		err.Error() == "wallet not found",
+		err.Error() == "user not found",
+		err.Error() == "penerima not found":
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "transfer can only be"),
+		err.Error() == "transfer confirmation has expired":
+		return http.StatusConflict
+	case err.Error() == "insufficient balance",
+		err.Error() == "cannot transfer to yourself",
+		err.Error() == "penerima is not an active anggota",
+		strings.HasPrefix(err.Error(), "jumlah must"),
+		strings.HasPrefix(err.Error(), "daily transfer limit"),
+		strings.HasSuffix(err.Error(), "is required"):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// BuatTransfer creates a sukarela transfer to another member, to be confirmed
+func (h *SimpananHandler) BuatTransfer(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var input struct {
+		EmailPenerima string      `json:"email_penerima" binding:"required"`
+		Jumlah        money.Money `json:"jumlah" binding:"required,gt=0"`
+		Keterangan    string      `json:"keterangan"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	t, err := h.service.BuatTransfer(userID, service.TransferInput{
+		EmailPenerima: input.EmailPenerima,
+		Jumlah:        input.Jumlah,
+		Keterangan:    input.Keterangan,
+	})
+	if err != nil {
+		c.JSON(transferErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Transfer created, confirm it to send",
+		"data":    t,
+	})
+}
+
+// KonfirmasiTransfer confirms a transfer the member created
+func (h *SimpananHandler) KonfirmasiTransfer(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	t, err := h.service.KonfirmasiTransfer(userID, uint(id64))
+	if err != nil {
+		c.JSON(transferErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	message := "Transfer sent"
+	if t.Status == model.TransferMenungguPersetujuan {
+		message = "Transfer confirmed, waiting for admin approval"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    t,
+	})
+}
+
+// BatalkanTransfer cancels a transfer that has not been sent yet
+func (h *SimpananHandler) BatalkanTransfer(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	t, err := h.service.BatalkanTransfer(userID, uint(id64))
+	if err != nil {
+		c.JSON(transferErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": t})
+}
+
+// ListTransfer returns sukarela transfers (?user_id= for admin, ?status=)
+func (h *SimpananHandler) ListTransfer(c *gin.Context) {
+	requestorID := c.GetUint("userID")
+	requestorRole := c.GetString("role")
+
+	var userID uint
+	if param := c.Query("user_id"); param != "" {
+		id64, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ResponseError("invalid user_id"))
+			return
+		}
+		userID = uint(id64)
+	}
+
+	list, err := h.service.ListTransfer(requestorID, requestorRole, userID, c.Query("status"))
+	if err != nil {
+		c.JSON(transferErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// GetTransfer returns one sukarela transfer
+func (h *SimpananHandler) GetTransfer(c *gin.Context) {
+	requestorID := c.GetUint("userID")
+	requestorRole := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	t, err := h.service.GetTransfer(requestorID, requestorRole, uint(id64))
+	if err != nil {
+		c.JSON(transferErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": t})
+}
+
+// SetujuiTransfer approves a transfer waiting for an admin (admin only)
+func (h *SimpananHandler) SetujuiTransfer(c *gin.Context) {
+	adminID := c.GetUint("userID")
+	adminRole := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	t, err := h.service.SetujuiTransfer(adminID, adminRole, uint(id64))
+	if err != nil {
+		c.JSON(transferErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": t})
+}
+
+// TolakTransfer rejects a transfer waiting for an admin and releases the held amount (admin only)
+func (h *SimpananHandler) TolakTransfer(c *gin.Context) {
+	adminID := c.GetUint("userID")
+	adminRole := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	var input struct {
+		AlasanPenolakan string `json:"alasan_penolakan" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	t, err := h.service.TolakTransfer(adminID, adminRole, uint(id64), input.AlasanPenolakan)
+	if err != nil {
+		c.JSON(transferErrorStatus(err), utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": t})
+}
+
+// KeluarAnggota processes a member's resignation and refunds their simpanan (admin only)
+func (h *SimpananHandler) KeluarAnggota(c *gin.Context) {
+	adminID := c.GetUint("userID")
+	adminRole := c.GetString("role")
+
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError("invalid id"))
+		return
+	}
+
+	var input struct {
+		AlasanKeluar string `json:"alasan_keluar" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ResponseError(err.Error()))
+		return
+	}
+
+	pengembalian, err := h.service.KeluarAnggota(adminID, adminRole, uint(id64), input.AlasanKeluar)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case err.Error() == "forbidden":
+			status = http.StatusForbidden
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			status = http.StatusNotFound
+		case strings.HasPrefix(err.Error(), "member "):
+			status = http.StatusConflict
+		case strings.HasSuffix(err.Error(), "is required"):
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, utils.ResponseError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Member resigned, simpanan refunded",
+		"data":    pengembalian,
+	})
+}
@@ -35,13 +35,14 @@ func (h *UserHandler) List(c *gin.Context) {
 	resp := make([]gin.H, 0, len(users))
 	for _, u := range users {
 		user := gin.H{
-			"id":           u.ID,
-			"email":        u.Email,
-			"name":         u.Name,
-			"address":      u.Address,
-			"phone_number": u.PhoneNumber,
-			"nik":          u.NIK,
-			"role_id":      u.RoleID,
+			"id":                 u.ID,
+			"email":              u.Email,
+			"name":               u.Name,
+			"address":            u.Address,
+			"phone_number":       u.PhoneNumber,
+			"nik":                u.NIK,
+			"role_id":            u.RoleID,
+			"status_keanggotaan": u.StatusKeanggotaan,
 		}
 		if u.AdminID != nil {
 			user["admin_id"] = *u.AdminID
@@ -70,13 +71,14 @@ func (h *UserHandler) Detail(c *gin.Context) {
 	}
 
 	response := gin.H{
-		"id":           user.ID,
-		"email":        user.Email,
-		"name":         user.Name,
-		"address":      user.Address,
-		"phone_number": user.PhoneNumber,
-		"nik":          user.NIK,
-		"role_id":      user.RoleID,
+		"id":                 user.ID,
+		"email":              user.Email,
+		"name":               user.Name,
+		"address":            user.Address,
+		"phone_number":       user.PhoneNumber,
+		"nik":                user.NIK,
+		"role_id":            user.RoleID,
+		"status_keanggotaan": user.StatusKeanggotaan,
 	}
 	if user.AdminID != nil {
 		response["admin_id"] = *user.AdminID
@@ -119,13 +121,14 @@ func (h *UserHandler) Create(c *gin.Context) {
 		return
 	}
 	response := gin.H{
-		"id":           u.ID,
-		"email":        u.Email,
-		"name":         u.Name,
-		"address":      u.Address,
-		"phone_number": u.PhoneNumber,
-		"nik":          u.NIK,
-		"role_id":      u.RoleID,
+		"id":                 u.ID,
+		"email":              u.Email,
+		"name":               u.Name,
+		"address":            u.Address,
+		"phone_number":       u.PhoneNumber,
+		"nik":                u.NIK,
+		"role_id":            u.RoleID,
+		"status_keanggotaan": u.StatusKeanggotaan,
 	}
 	if u.AdminID != nil {
 		response["admin_id"] = *u.AdminID
@@ -166,13 +169,14 @@ func (h *UserHandler) Update(c *gin.Context) {
 		return
 	}
 	response := gin.H{
-		"id":           u.ID,
-		"email":        u.Email,
-		"name":         u.Name,
-		"address":      u.Address,
-		"phone_number": u.PhoneNumber,
-		"nik":          u.NIK,
-		"role_id":      u.RoleID,
+		"id":                 u.ID,
+		"email":              u.Email,
+		"name":               u.Name,
+		"address":            u.Address,
+		"phone_number":       u.PhoneNumber,
+		"nik":                u.NIK,
+		"role_id":            u.RoleID,
+		"status_keanggotaan": u.StatusKeanggotaan,
 	}
 	if u.AdminID != nil {
 		response["admin_id"] = *u.AdminID
@@ -37,6 +37,8 @@ func AuthMiddleware(cfg *config.Config, userRepo *repository.UserRepository) gin
 
 		userID := uint(userIDFloat)
 		c.Set("user_id", userID)
+		// Most handlers read "userID"; without it VerifiedByID etc. ended up as 0
+		c.Set("userID", userID)
 
 		// load user role
 		if user, err := userRepo.FindByIDWithRole(userID); err == nil {
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"koperasi-service/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Types of collateral
+const (
+	JenisAgunanBPKB       = "bpkb"       // Vehicle ownership book
+	JenisAgunanSertifikat = "sertifikat" // Land or building certificate
+	JenisAgunanSuratGaji  = "surat_gaji" // Payroll letter / salary deduction authorization
+	JenisAgunanLainnya    = "lainnya"
+)
+
+// ValidJenisAgunan lists the supported collateral types
+var ValidJenisAgunan = map[string]bool{
+	JenisAgunanBPKB:       true,
+	JenisAgunanSertifikat: true,
+	JenisAgunanSuratGaji:  true,
+	JenisAgunanLainnya:    true,
+}
+
+// Statuses of a collateral item
+const (
+	StatusAgunanDitahan      = "ditahan"      // Held by the koperasi
+	StatusAgunanDikembalikan = "dikembalikan" // Returned to the member
+)
+
+// Agunan is a collateral item pledged for a Pinjaman. It is returned when the
+// loan is lunas or ditolak.
+type Agunan struct {
+	gorm.Model
+	PinjamanID          uint        `gorm:"not null;index" json:"pinjaman_id"`
+	Jenis               string      `gorm:"type:varchar(20);not null" json:"jenis"`
+	NomorDokumen        string      `gorm:"type:varchar(100)" json:"nomor_dokumen"`
+	AtasNama            string      `gorm:"type:varchar(100)" json:"atas_nama"` // Name on the document
+	Deskripsi           string      `gorm:"type:text" json:"deskripsi"`
+	NilaiTaksiran       money.Money `gorm:"type:decimal(15,2);default:0" json:"nilai_taksiran"` // Estimated value
+	Status              string      `gorm:"type:varchar(20);default:'ditahan';index" json:"status"`
+	TanggalDikembalikan *time.Time  `json:"tanggal_dikembalikan"`
+	DikembalikanOleh    *uint       `json:"dikembalikan_oleh"`
+	CreatedBy           uint        `gorm:"not null" json:"created_by"`
+}
+
+// TableName specifies the table name for Agunan model
+func (Agunan) TableName() string {
+	return "agunan"
+}
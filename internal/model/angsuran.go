@@ -1,26 +1,59 @@
 package model
 
 import (
+	"koperasi-service/pkg/money"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// Kinds of Angsuran payments
+const (
+	JenisAngsuranRutin     = "angsuran"  // Regular installment payment
+	JenisAngsuranPelunasan = "pelunasan" // Early settlement closing the loan
+)
+
 // Angsuran represents an installment payment record in the system
 type Angsuran struct {
 	gorm.Model
-	PinjamanID         uint      `gorm:"not null;index" json:"pinjaman_id"` // References pinjaman table
-	AngsuranKe         int       `gorm:"not null" json:"angsuran_ke"`
-	TanggalBayar       time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"tanggal_bayar"`
-	Pokok              float64   `gorm:"type:decimal(15,2);not null" json:"pokok"`
-	Bunga              float64   `gorm:"type:decimal(15,2);not null" json:"bunga"`
-	Denda              float64   `gorm:"type:decimal(15,2);default:0" json:"denda"`
-	TotalBayar         float64   `gorm:"type:decimal(15,2);not null" json:"total_bayar"`
-	UserID             uint      `gorm:"not null" json:"user_id"` // References users table
-	Status             string    `gorm:"type:varchar(20);check:status IN ('proses', 'verified', 'kurang', 'lebih')" json:"status"`
-	ImageBuktiTransfer string    `gorm:"type:varchar(255)" json:"image_bukti_transfer"` // Path/URL to transfer receipt image
-	NoRekening         string    `gorm:"type:varchar(50)" json:"no_rekening"`           // Account number used for payment
-	BankName           string    `gorm:"type:varchar(100)" json:"bank_name"`            // Bank name used for payment
-	Pinjaman           Pinjaman  `gorm:"foreignKey:PinjamanID" json:"pinjaman,omitempty"`
-	User               User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	PinjamanID         uint              `gorm:"not null;index" json:"pinjaman_id"` // References pinjaman table
+	AngsuranKe         int               `gorm:"not null" json:"angsuran_ke"`
+	TanggalBayar       time.Time         `gorm:"default:CURRENT_TIMESTAMP" json:"tanggal_bayar"`
+	Pokok              money.Money       `gorm:"type:decimal(15,2);not null" json:"pokok"`
+	Bunga              money.Money       `gorm:"type:decimal(15,2);not null" json:"bunga"`
+	Denda              money.Money       `gorm:"type:decimal(15,2);default:0" json:"denda"`
+	Biaya              money.Money       `gorm:"type:decimal(15,2);default:0" json:"biaya"` // Early-settlement fee, only on pelunasan
+	TotalBayar         money.Money       `gorm:"type:decimal(15,2);not null" json:"total_bayar"`
+	UserID             uint              `gorm:"not null" json:"user_id"` // References users table
+	Status             string            `gorm:"type:varchar(20);check:status IN ('proses', 'verified', 'kurang', 'lebih')" json:"status"`
+	Jenis              string            `gorm:"type:varchar(20);default:'angsuran'" json:"jenis"` // angsuran or pelunasan
+	ImageBuktiTransfer string            `gorm:"type:varchar(255)" json:"image_bukti_transfer"`    // Path/URL to transfer receipt image; the download path when BuktiTransferID is set
+	BuktiTransferID    *uint             `gorm:"index" json:"bukti_transfer_id"`                   // Uploaded receipt (Berkas)
+	NoRekening         string            `gorm:"type:varchar(50)" json:"no_rekening"`              // Account number used for payment
+	BankName           string            `gorm:"type:varchar(100)" json:"bank_name"`               // Bank name used for payment
+	Kelebihan          money.Money       `gorm:"type:decimal(15,2);default:0" json:"kelebihan"`    // Overpayment not applied to the schedule
+	KelebihanKembali   bool              `gorm:"default:false" json:"kelebihan_dikembalikan"`      // Overpayment is to be refunded instead of carried over
+	DiverifikasiOleh   *uint             `json:"diverifikasi_oleh"`
+	TanggalVerifikasi  *time.Time        `json:"tanggal_verifikasi"`
+	TanggalDiterima    *time.Time        `json:"tanggal_diterima"` // Date the money arrived, confirmed by the admin on verification; denda and allocation are as of this date
+	Pinjaman           Pinjaman          `gorm:"foreignKey:PinjamanID" json:"pinjaman,omitempty"`
+	User               User              `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Alokasi            []AlokasiAngsuran `gorm:"foreignKey:AngsuranID" json:"alokasi,omitempty"`
+}
+
+// AlokasiAngsuran records how much of a verified payment went to one schedule row
+type AlokasiAngsuran struct {
+	ID               uint        `gorm:"primaryKey" json:"id"`
+	AngsuranID       uint        `gorm:"not null;index" json:"angsuran_id"`
+	JadwalAngsuranID uint        `gorm:"not null;index" json:"jadwal_angsuran_id"`
+	AngsuranKe       int         `gorm:"not null" json:"angsuran_ke"` // Installment number of the schedule row
+	Denda            money.Money `gorm:"type:decimal(15,2);not null" json:"denda"`
+	Bunga            money.Money `gorm:"type:decimal(15,2);not null" json:"bunga"`
+	Pokok            money.Money `gorm:"type:decimal(15,2);not null" json:"pokok"`
+	CreatedAt        time.Time   `json:"created_at"`
+}
+
+// TableName specifies the table name for AlokasiAngsuran model
+func (AlokasiAngsuran) TableName() string {
+	return "alokasi_angsuran"
 }
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"koperasi-service/pkg/money"
+
+	"gorm.io/gorm"
+)
+
+// AturanDenda is an admin-configurable late-payment penalty rule. At most one
+// rule is active at a time; without an active rule no denda is charged.
+type AturanDenda struct {
+	gorm.Model
+	Nama             string      `gorm:"type:varchar(50);not null" json:"nama"`
+	PersenPerHari    float64     `gorm:"type:decimal(5,2);default:0" json:"persen_per_hari"` // Percent of the overdue installment per day late
+	BiayaTetap       money.Money `gorm:"type:decimal(15,2);default:0" json:"biaya_tetap"`    // Flat fee per overdue installment
+	MasaTenggangHari int         `gorm:"default:0" json:"masa_tenggang_hari"`                // Days after the due date without denda
+	MaksimalDenda    money.Money `gorm:"type:decimal(15,2);default:0" json:"maksimal_denda"` // Cap per installment, 0 means no cap
+	Deskripsi        string      `gorm:"type:text" json:"deskripsi"`
+	IsActive         bool        `gorm:"default:false" json:"is_active"`
+	CreatedBy        uint        `gorm:"not null" json:"created_by"` // Admin who created this rule
+	CreatedByUser    User        `gorm:"foreignKey:CreatedBy" json:"created_by_user,omitempty"`
+}
+
+// TableName specifies the table name for AturanDenda model
+func (AturanDenda) TableName() string {
+	return "aturan_denda"
+}
+
+// SetCreatedBy records the admin who created the rule
+func (a *AturanDenda) SetCreatedBy(userID uint) {
+	a.CreatedBy = userID
+}
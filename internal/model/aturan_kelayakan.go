@@ -0,0 +1,31 @@
+package model
+
+import "gorm.io/gorm"
+
+// AturanKelayakan is an admin-configurable loan eligibility rule checked before
+// a Pinjaman is accepted. At most one rule is active at a time; a zero limit
+// disables that check. Loans are always refused while the member has a macet loan.
+type AturanKelayakan struct {
+	gorm.Model
+	Nama                    string  `gorm:"type:varchar(50);not null" json:"nama"`
+	KelipatanSimpanan       float64 `gorm:"type:decimal(5,2);default:0" json:"kelipatan_simpanan"`      // Plafon = simpanan balances × this multiple
+	MaksimalPinjamanAktif   int     `gorm:"default:0" json:"maksimal_pinjaman_aktif"`                   // Loans in proses, disetujui or dicairkan at the same time
+	MinimalBulanKeanggotaan int     `gorm:"default:0" json:"minimal_bulan_keanggotaan"`                 // Months since the member registered
+	MaksimalRasioAngsuran   float64 `gorm:"type:decimal(5,2);default:0" json:"maksimal_rasio_angsuran"` // Max percent of monthly income spent on installments (DSR)
+	KelipatanPenjaminan     float64 `gorm:"type:decimal(5,2);default:0" json:"kelipatan_penjaminan"`    // A guarantor's own and guaranteed principal ≤ their simpanan × this multiple
+	WajibLancar             bool    `gorm:"default:false" json:"wajib_lancar"`                          // Refuse members with an overdue simpanan wajib month
+	Deskripsi               string  `gorm:"type:text" json:"deskripsi"`
+	IsActive                bool    `gorm:"default:false" json:"is_active"`
+	CreatedBy               uint    `gorm:"not null" json:"created_by"` // Admin who created this rule
+	CreatedByUser           User    `gorm:"foreignKey:CreatedBy" json:"created_by_user,omitempty"`
+}
+
+// TableName specifies the table name for AturanKelayakan model
+func (AturanKelayakan) TableName() string {
+	return "aturan_kelayakan"
+}
+
+// SetCreatedBy records the admin who created the rule
+func (a *AturanKelayakan) SetCreatedBy(userID uint) {
+	a.CreatedBy = userID
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"koperasi-service/pkg/money"
+
+	"gorm.io/gorm"
+)
+
+// Interest charged on early settlement (pelunasan dipercepat)
+const (
+	BungaPelunasanAkrual        = "akrual"         // Current period's interest pro rata to the settlement date, later interest waived
+	BungaPelunasanBulanBerjalan = "bulan_berjalan" // Full interest of the current period, later interest waived
+	BungaPelunasanPenuh         = "penuh"          // All remaining interest, less PersenRebate of the interest after the current period
+)
+
+// ValidBungaPelunasan lists the supported early-settlement interest policies
+var ValidBungaPelunasan = map[string]bool{
+	BungaPelunasanAkrual:        true,
+	BungaPelunasanBulanBerjalan: true,
+	BungaPelunasanPenuh:         true,
+}
+
+// AturanPelunasan is an admin-configurable early-settlement rule. At most one
+// rule is active at a time; without one interest is accrued to the settlement
+// date and no fee is charged.
+type AturanPelunasan struct {
+	gorm.Model
+	Nama           string      `gorm:"type:varchar(50);not null" json:"nama"`
+	KebijakanBunga string      `gorm:"type:varchar(20);default:'akrual'" json:"kebijakan_bunga"` // akrual, bulan_berjalan, penuh
+	PersenRebate   float64     `gorm:"type:decimal(5,2);default:0" json:"persen_rebate"`         // Percent of later interest waived under penuh
+	BiayaPersen    float64     `gorm:"type:decimal(5,2);default:0" json:"biaya_persen"`          // Fee as percent of the remaining principal
+	BiayaTetap     money.Money `gorm:"type:decimal(15,2);default:0" json:"biaya_tetap"`          // Flat fee per settlement
+	Deskripsi      string      `gorm:"type:text" json:"deskripsi"`
+	IsActive       bool        `gorm:"default:false" json:"is_active"`
+	CreatedBy      uint        `gorm:"not null" json:"created_by"` // Admin who created this rule
+	CreatedByUser  User        `gorm:"foreignKey:CreatedBy" json:"created_by_user,omitempty"`
+}
+
+// TableName specifies the table name for AturanPelunasan model
+func (AturanPelunasan) TableName() string {
+	return "aturan_pelunasan"
+}
+
+// SetCreatedBy records the admin who created the rule
+func (a *AturanPelunasan) SetCreatedBy(userID uint) {
+	a.CreatedBy = userID
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"koperasi-service/pkg/money"
+
+	"gorm.io/gorm"
+)
+
+// AturanSimpananPokok is the admin-configurable one-time simpanan pokok a new
+// member pays to become an active anggota. At most one rule is active at a
+// time; without an active rule any positive amount is accepted.
+type AturanSimpananPokok struct {
+	gorm.Model
+	Nama          string      `gorm:"type:varchar(50);not null" json:"nama"`
+	Nominal       money.Money `gorm:"type:decimal(15,2);not null" json:"nominal"`
+	Deskripsi     string      `gorm:"type:text" json:"deskripsi"`
+	IsActive      bool        `gorm:"default:false" json:"is_active"`
+	CreatedBy     uint        `gorm:"not null" json:"created_by"` // Admin who created this rule
+	CreatedByUser User        `gorm:"foreignKey:CreatedBy" json:"created_by_user,omitempty"`
+}
+
+// TableName specifies the table name for AturanSimpananPokok model
+func (AturanSimpananPokok) TableName() string {
+	return "aturan_simpanan_pokok"
+}
+
+// SetCreatedBy records the admin who created the rule
+func (a *AturanSimpananPokok) SetCreatedBy(userID uint) {
+	a.CreatedBy = userID
+}
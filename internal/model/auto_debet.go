@@ -0,0 +1,53 @@
+package model
+
+import (
+	"koperasi-service/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MandatAutoDebet is a member's opt-in to have the installments of a loan
+// debited from their sukarela wallet on each due date
+type MandatAutoDebet struct {
+	gorm.Model
+	PinjamanID  uint       `gorm:"not null;uniqueIndex" json:"pinjaman_id"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	SimpananID  uint       `gorm:"not null" json:"simpanan_id"` // The borrower's sukarela wallet
+	IsActive    bool       `gorm:"default:true" json:"is_active"`
+	CreatedBy   uint       `gorm:"not null" json:"created_by"`
+	DicabutOleh *uint      `json:"dicabut_oleh"` // Who revoked the mandate
+	DicabutPada *time.Time `json:"dicabut_pada"`
+}
+
+// TableName specifies the table name for MandatAutoDebet model
+func (MandatAutoDebet) TableName() string {
+	return "mandat_auto_debet"
+}
+
+// Outcomes of an auto-debit attempt
+const (
+	AutoDebetBerhasil = "berhasil" // The wallet was debited and the payment verified
+	AutoDebetGagal    = "gagal"    // Insufficient balance, the installments became overdue
+)
+
+// AutoDebet records one auto-debit attempt. An attempt is made once per
+// schedule row falling due and covers everything due on the loan at that time.
+type AutoDebet struct {
+	gorm.Model
+	MandatID          uint        `gorm:"not null;index" json:"mandat_id"`
+	PinjamanID        uint        `gorm:"not null;index" json:"pinjaman_id"`
+	JadwalAngsuranID  uint        `gorm:"not null;uniqueIndex" json:"jadwal_angsuran_id"` // The row whose due date triggered the attempt
+	TanggalJatuhTempo time.Time   `gorm:"not null" json:"tanggal_jatuh_tempo"`
+	Tagihan           money.Money `gorm:"type:decimal(15,2);not null" json:"tagihan"` // Everything due, arrears and denda included
+	Saldo             money.Money `gorm:"type:decimal(15,2);not null" json:"saldo"`   // Wallet balance before the attempt
+	Status            string      `gorm:"type:varchar(20);not null;index" json:"status"`
+	AngsuranID        *uint       `json:"angsuran_id"`       // Verified payment created on success
+	LedgerJournalID   *uint       `json:"ledger_journal_id"` // Wallet debit posted on success
+	TanggalProses     time.Time   `gorm:"not null" json:"tanggal_proses"`
+}
+
+// TableName specifies the table name for AutoDebet model
+func (AutoDebet) TableName() string {
+	return "auto_debet"
+}
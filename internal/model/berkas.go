@@ -0,0 +1,45 @@
+package model
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Records an uploaded file can be attached to, by table name
+const (
+	BerkasAngsuran            = "angsuran"
+	BerkasSimpananTransaction = "simpanan_transactions" // Top-ups
+	BerkasPencairan           = "pencairan"
+)
+
+// Berkas is an uploaded proof-of-transfer file. The content is stored under a
+// name derived from its SHA-256, so the same file uploaded twice is stored
+// once. A file is attached to at most one record; until then only the
+// uploader and admins can see it.
+type Berkas struct {
+	gorm.Model
+	Hash            string `gorm:"type:varchar(64);not null;index" json:"hash"` // SHA-256 of the content, hex
+	StorageKey      string `gorm:"type:varchar(255);not null" json:"-"`
+	ThumbnailKey    string `gorm:"type:varchar(255)" json:"-"` // Empty for files without a preview, such as PDFs
+	ContentType     string `gorm:"type:varchar(100);not null" json:"content_type"`
+	Ukuran          int64  `gorm:"not null" json:"ukuran"`             // Bytes
+	UkuranThumbnail int64  `json:"ukuran_thumbnail"`                   // 0 when there is no thumbnail
+	NamaAsli        string `gorm:"type:varchar(255)" json:"nama_asli"` // File name given by the client
+	Lebar           int    `json:"lebar"`                              // Pixels, images only
+	Tinggi          int    `json:"tinggi"`
+	DiunggahOleh    uint   `gorm:"not null;index" json:"diunggah_oleh"`
+	ReferenceTable  string `gorm:"type:varchar(50);index:idx_berkas_reference" json:"reference_table"` // Set when attached
+	ReferenceID     *uint  `gorm:"index:idx_berkas_reference" json:"reference_id"`
+	PemilikID       *uint  `gorm:"index" json:"pemilik_id"` // Member the attached record belongs to; can also see the file
+}
+
+// TableName specifies the table name for Berkas model
+func (Berkas) TableName() string {
+	return "berkas"
+}
+
+// BerkasURL is the access-controlled download path of an uploaded file
+func BerkasURL(id uint) string {
+	return fmt.Sprintf("/api/berkas/%d/download", id)
+}
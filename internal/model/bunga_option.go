@@ -1,15 +1,20 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // BungaOption represents the admin-configurable interest rate options
 type BungaOption struct {
 	gorm.Model
-	Nama          string  `gorm:"type:varchar(50);not null" json:"nama"`    // e.g., "Bunga Rendah", "Bunga Standar"
-	Persen        float64 `gorm:"type:decimal(5,2);not null" json:"persen"` // e.g., 1.00, 2.00, 2.50
-	Deskripsi     string  `gorm:"type:text" json:"deskripsi"`               // Optional description
-	IsActive      bool    `gorm:"default:true" json:"is_active"`            // To enable/disable options
-	CreatedBy     uint    `gorm:"not null" json:"created_by"`               // Admin who created this option
+	Nama          string  `gorm:"type:varchar(50);not null" json:"nama"`               // e.g., "Bunga Rendah", "Bunga Standar"
+	Persen        float64 `gorm:"type:decimal(5,2);not null" json:"persen"`            // Monthly rate of the latest version, e.g., 1.00, 2.00, 2.50
+	MetodeBunga   string  `gorm:"type:varchar(20);default:'flat'" json:"metode_bunga"` // Default interest method of the latest version: flat, efektif, anuitas
+	Deskripsi     string  `gorm:"type:text" json:"deskripsi"`                          // Optional description
+	IsActive      bool    `gorm:"default:true" json:"is_active"`                       // To enable/disable options
+	CreatedBy     uint    `gorm:"not null" json:"created_by"`                          // Admin who created this option
 	CreatedByUser User    `gorm:"foreignKey:CreatedBy" json:"created_by_user,omitempty"`
 }
 
@@ -17,3 +22,23 @@ type BungaOption struct {
 func (BungaOption) TableName() string {
 	return "bunga_options"
 }
+
+// BungaOptionVersi is one rate of a BungaOption with the period it is in
+// effect. Versions are never edited; a rate change adds a version and ends
+// the previous one where the new one starts.
+type BungaOptionVersi struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	BungaOptionID uint       `gorm:"not null;uniqueIndex:idx_bunga_option_versi" json:"bunga_option_id"`
+	Versi         int        `gorm:"not null;uniqueIndex:idx_bunga_option_versi" json:"versi"`
+	Persen        float64    `gorm:"type:decimal(5,2);not null" json:"persen"`
+	MetodeBunga   string     `gorm:"type:varchar(20);default:'flat'" json:"metode_bunga"`
+	EffectiveFrom time.Time  `gorm:"not null;index" json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to"` // nil while open-ended
+	CreatedBy     uint       `gorm:"not null" json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for BungaOptionVersi model
+func (BungaOptionVersi) TableName() string {
+	return "bunga_option_versi"
+}
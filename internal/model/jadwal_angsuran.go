@@ -0,0 +1,77 @@
+package model
+
+import (
+	"koperasi-service/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Interest calculation methods for a loan schedule
+const (
+	MetodeBungaFlat    = "flat"    // Interest on the original principal every month
+	MetodeBungaEfektif = "efektif" // Interest on the remaining principal (declining balance), equal principal
+	MetodeBungaAnuitas = "anuitas" // Equal monthly installment, interest on the remaining principal
+)
+
+// ValidMetodeBunga lists the supported interest methods
+var ValidMetodeBunga = map[string]bool{
+	MetodeBungaFlat:    true,
+	MetodeBungaEfektif: true,
+	MetodeBungaAnuitas: true,
+}
+
+// Payment statuses of a schedule row
+const (
+	JadwalBelumBayar = "belum_bayar" // Nothing paid yet
+	JadwalSebagian   = "sebagian"    // Partly paid, the rest is in arrears once due
+	JadwalLunas      = "lunas"       // Denda, bunga and pokok fully paid
+	JadwalTerlambat  = "terlambat"   // Due and unpaid after a failed auto-debit
+	// Open row of a schedule version replaced by a restructuring
+	JadwalDirestrukturisasi = "direstrukturisasi"
+)
+
+// JadwalAngsuran is one monthly row of a loan's amortization schedule
+type JadwalAngsuran struct {
+	gorm.Model
+	PinjamanID          uint        `gorm:"not null;index" json:"pinjaman_id"` // References pinjaman table
+	Versi               int         `gorm:"default:1;index" json:"versi"`      // Schedule version, see Pinjaman.VersiJadwal
+	AngsuranKe          int         `gorm:"not null" json:"angsuran_ke"`
+	TanggalJatuhTempo   time.Time   `gorm:"not null;index" json:"tanggal_jatuh_tempo"`
+	Pokok               money.Money `gorm:"type:decimal(15,2);not null" json:"pokok"`
+	Bunga               money.Money `gorm:"type:decimal(15,2);not null" json:"bunga"`
+	TotalAngsuran       money.Money `gorm:"type:decimal(15,2);not null" json:"total_angsuran"`
+	SisaPokok           money.Money `gorm:"type:decimal(15,2);not null" json:"sisa_pokok"` // Remaining principal after this installment
+	Denda               money.Money `gorm:"type:decimal(15,2);default:0" json:"denda"`     // Late fee charged on this installment
+	DendaDihitungSampai *time.Time  `json:"denda_dihitung_sampai"`                         // Day up to which Denda has been accrued
+	DendaDibayar        money.Money `gorm:"type:decimal(15,2);default:0" json:"denda_dibayar"`
+	BungaDibayar        money.Money `gorm:"type:decimal(15,2);default:0" json:"bunga_dibayar"`
+	PokokDibayar        money.Money `gorm:"type:decimal(15,2);default:0" json:"pokok_dibayar"`
+	TanggalLunas        *time.Time  `json:"tanggal_lunas"`
+	Status              string      `gorm:"type:varchar(20);default:'belum_bayar'" json:"status"`
+}
+
+// SisaDenda returns the unpaid late fee of the row
+func (j *JadwalAngsuran) SisaDenda() money.Money {
+	return j.Denda - j.DendaDibayar
+}
+
+// SisaBunga returns the unpaid interest of the row
+func (j *JadwalAngsuran) SisaBunga() money.Money {
+	return j.Bunga - j.BungaDibayar
+}
+
+// SisaPokokAngsuran returns the unpaid principal of the row
+func (j *JadwalAngsuran) SisaPokokAngsuran() money.Money {
+	return j.Pokok - j.PokokDibayar
+}
+
+// SisaTagihan returns everything still owed on the row
+func (j *JadwalAngsuran) SisaTagihan() money.Money {
+	return j.SisaDenda() + j.SisaBunga() + j.SisaPokokAngsuran()
+}
+
+// TableName specifies the table name for JadwalAngsuran model
+func (JadwalAngsuran) TableName() string {
+	return "jadwal_angsuran"
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"koperasi-service/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Balances a month's jasa simpanan can be computed on
+const (
+	DasarSaldoTerendah = "saldo_terendah"  // Lowest end-of-day balance of the month
+	DasarSaldoRataRata = "saldo_rata_rata" // Average end-of-day balance of the month
+	DasarSaldoAkhir    = "saldo_akhir"     // Balance at the end of the month
+)
+
+// AturanJasaSimpanan is the admin-configurable return paid on sukarela
+// balances. At most one rule is active at a time; without an active rule no
+// jasa is accrued.
+type AturanJasaSimpanan struct {
+	gorm.Model
+	Nama             string      `gorm:"type:varchar(50);not null" json:"nama"`
+	PersenTahunan    float64     `gorm:"type:decimal(5,2);not null" json:"persen_tahunan"`                    // Annual rate, applied per day (365-day year)
+	DasarPerhitungan string      `gorm:"type:varchar(20);default:'saldo_rata_rata'" json:"dasar_perhitungan"` // Dasar* constant
+	PersenPajak      float64     `gorm:"type:decimal(5,2);default:0" json:"persen_pajak"`                     // Tax withheld from the jasa, 0 means none
+	BatasBebasPajak  money.Money `gorm:"type:decimal(15,2);default:0" json:"batas_bebas_pajak"`               // Jasa up to this amount is not taxed
+	Deskripsi        string      `gorm:"type:text" json:"deskripsi"`
+	IsActive         bool        `gorm:"default:false" json:"is_active"`
+	CreatedBy        uint        `gorm:"not null" json:"created_by"` // Admin who created this rule
+	CreatedByUser    User        `gorm:"foreignKey:CreatedBy" json:"created_by_user,omitempty"`
+}
+
+// TableName specifies the table name for AturanJasaSimpanan model
+func (AturanJasaSimpanan) TableName() string {
+	return "aturan_jasa_simpanan"
+}
+
+// SetCreatedBy records the admin who created the rule
+func (a *AturanJasaSimpanan) SetCreatedBy(userID uint) {
+	a.CreatedBy = userID
+}
+
+// JasaSimpanan is the return credited to one sukarela wallet for one month.
+// A wallet is credited at most once per month.
+type JasaSimpanan struct {
+	gorm.Model
+	SimpananID       uint        `gorm:"not null;uniqueIndex:idx_jasa_simpanan_wallet_periode" json:"simpanan_id"`
+	UserID           uint        `gorm:"not null;index" json:"user_id"`
+	Periode          time.Time   `gorm:"type:date;not null;uniqueIndex:idx_jasa_simpanan_wallet_periode" json:"periode"` // First day of the month
+	AturanID         uint        `json:"aturan_id"`
+	DasarPerhitungan string      `gorm:"type:varchar(20)" json:"dasar_perhitungan"`
+	PersenTahunan    float64     `gorm:"type:decimal(5,2)" json:"persen_tahunan"`
+	JumlahHari       int         `json:"jumlah_hari"`                           // Days the rate was applied for
+	SaldoDasar       money.Money `gorm:"type:decimal(15,2)" json:"saldo_dasar"` // Balance the rate was applied to
+	JasaBruto        money.Money `gorm:"type:decimal(15,2)" json:"jasa_bruto"`
+	Pajak            money.Money `gorm:"type:decimal(15,2);default:0" json:"pajak"`
+	JasaBersih       money.Money `gorm:"type:decimal(15,2)" json:"jasa_bersih"` // Credited to the wallet
+	// The jasa transaction in the wallet history; nil in previews
+	SimpananTransactionID *uint `json:"simpanan_transaction_id"`
+	PostedBy              *uint `json:"posted_by"` // Admin who posted it (nil for the scheduled job)
+}
+
+// TableName specifies the table name for JasaSimpanan model
+func (JasaSimpanan) TableName() string {
+	return "jasa_simpanan"
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"koperasi-service/pkg/money"
+
+	"gorm.io/gorm"
+)
+
+// JenisPinjaman is a loan product such as "Pinjaman Konsumtif" or "Pinjaman
+// Darurat". A Pinjaman that references a product must fit its amount and
+// tenor range and takes its rate, interest method and fees from it. A zero
+// maximum disables that limit.
+type JenisPinjaman struct {
+	gorm.Model
+	Nama               string           `gorm:"type:varchar(50);not null" json:"nama"`
+	Deskripsi          string           `gorm:"type:text" json:"deskripsi"`
+	MinimalJumlah      money.Money      `gorm:"type:decimal(15,2);default:0" json:"minimal_jumlah"`
+	MaksimalJumlah     money.Money      `gorm:"type:decimal(15,2);default:0" json:"maksimal_jumlah"`
+	MinimalBulan       int              `gorm:"default:1" json:"minimal_bulan"`
+	MaksimalBulan      int              `gorm:"default:0" json:"maksimal_bulan"`
+	BungaOptionID      *uint            `gorm:"index" json:"bunga_option_id"`                        // Rate comes from this option when set
+	BungaPersen        float64          `gorm:"type:decimal(5,2);default:0" json:"bunga_persen"`     // Monthly rate when no option is linked
+	MetodeBunga        string           `gorm:"type:varchar(20);default:'flat'" json:"metode_bunga"` // flat, efektif, anuitas
+	BiayaAdmin         money.Money      `gorm:"type:decimal(15,2);default:0" json:"biaya_admin"`     // Fixed admin fee per loan
+	BiayaProvisiPersen float64          `gorm:"type:decimal(5,2);default:0" json:"biaya_provisi_persen"`
+	WajibPenjamin      bool             `gorm:"default:false" json:"wajib_penjamin"`                     // A co-member guarantor is required
+	WajibAgunan        bool             `gorm:"default:false" json:"wajib_agunan"`                       // Collateral is required
+	MinimalNilaiAgunan float64          `gorm:"type:decimal(6,2);default:0" json:"minimal_nilai_agunan"` // Collateral value as percent of jumlah pinjaman
+	AturanKelayakanID  *uint            `gorm:"index" json:"aturan_kelayakan_id"`                        // Eligibility rule of the product, the active rule when empty
+	IsActive           bool             `gorm:"default:true" json:"is_active"`                           // Inactive products accept no new applications
+	CreatedBy          uint             `gorm:"not null" json:"created_by"`                              // Admin who created this product
+	CreatedByUser      User             `gorm:"foreignKey:CreatedBy" json:"created_by_user,omitempty"`
+	BungaOption        *BungaOption     `gorm:"foreignKey:BungaOptionID" json:"bunga_option,omitempty"`
+	AturanKelayakan    *AturanKelayakan `gorm:"foreignKey:AturanKelayakanID" json:"aturan_kelayakan,omitempty"`
+}
+
+// TableName specifies the table name for JenisPinjaman model
+func (JenisPinjaman) TableName() string {
+	return "jenis_pinjaman"
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"koperasi-service/pkg/money"
+
+	"gorm.io/gorm"
+)
+
+// JenisSimpanan is a savings wallet type of the koperasi, such as simpanan
+// hari raya. Every member holds one wallet (Simpanan.Type = Kode) of each
+// active type. Time-deposit wallets are not part of the catalog.
+type JenisSimpanan struct {
+	gorm.Model
+	Kode           string      `gorm:"type:varchar(30);not null;uniqueIndex:idx_jenis_simpanan_kode_aktif,where:deleted_at IS NULL" json:"kode"` // Wallet type; cannot change once created, free again once deleted
+	Nama           string      `gorm:"type:varchar(50);not null" json:"nama"`
+	Deskripsi      string      `gorm:"type:text" json:"deskripsi"`
+	BisaDitarik    bool        `json:"bisa_ditarik"`                                        // Members can request withdrawals from it
+	Wajib          bool        `json:"wajib"`                                               // Mandatory savings; top-ups must be at least NominalDefault
+	SaldoMinimal   money.Money `gorm:"type:decimal(15,2);default:0" json:"saldo_minimal"`   // Must stay in the wallet after withdrawals and transfers
+	NominalDefault money.Money `gorm:"type:decimal(15,2);default:0" json:"nominal_default"` // Top-up amount when none is given
+	IsActive       bool        `gorm:"default:true" json:"is_active"`                       // Inactive types take no top-ups and are not given to new members
+	Bawaan         bool        `json:"bawaan"`                                              // Built in (pokok, wajib, sukarela); cannot be deleted or deactivated
+	CreatedBy      *uint       `json:"created_by"`                                          // Admin who created this type, nil for built-in types
+}
+
+// TableName specifies the table name for JenisSimpanan model
+func (JenisSimpanan) TableName() string {
+	return "jenis_simpanan"
+}
+
+// HanyaSaatKeluar reports whether the type's money only leaves the koperasi
+// when the member resigns. Such types can never be withdrawn.
+func (j JenisSimpanan) HanyaSaatKeluar() bool {
+	return j.Kode == "pokok" || j.Kode == "wajib"
+}
+
+// JenisSimpananBawaan are the wallet types every koperasi has. Their codes are
+// used by the simpanan rules, so they are seeded at startup.
+var JenisSimpananBawaan = []JenisSimpanan{
+	{Kode: "pokok", Nama: "Simpanan Pokok", Wajib: true, IsActive: true, Bawaan: true},
+	{Kode: "wajib", Nama: "Simpanan Wajib", Wajib: true, IsActive: true, Bawaan: true},
+	{Kode: "sukarela", Nama: "Simpanan Sukarela", BisaDitarik: true, IsActive: true, Bawaan: true},
+}
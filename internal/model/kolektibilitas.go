@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// Collectibility buckets of a loan by days past due
+const (
+	KolektibilitasLancar         = 1 // No arrears
+	KolektibilitasDalamPerhatian = 2 // 1-90 days past due
+	KolektibilitasKurangLancar   = 3 // 91-120 days past due
+	KolektibilitasDiragukan      = 4 // 121-180 days past due
+	KolektibilitasMacet          = 5 // More than 180 days past due
+)
+
+// NamaKolektibilitas maps a bucket to its name
+var NamaKolektibilitas = map[int]string{
+	KolektibilitasLancar:         "lancar",
+	KolektibilitasDalamPerhatian: "dalam perhatian khusus",
+	KolektibilitasKurangLancar:   "kurang lancar",
+	KolektibilitasDiragukan:      "diragukan",
+	KolektibilitasMacet:          "macet",
+}
+
+// RiwayatKolektibilitas records a change of a loan's collectibility bucket
+type RiwayatKolektibilitas struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	PinjamanID    uint      `gorm:"not null;index" json:"pinjaman_id"`
+	Dari          int       `gorm:"not null" json:"dari"` // Previous bucket
+	Ke            int       `gorm:"not null" json:"ke"`   // New bucket
+	HariTunggakan int       `gorm:"not null" json:"hari_tunggakan"`
+	Tanggal       time.Time `gorm:"not null" json:"tanggal"` // Classification date
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for RiwayatKolektibilitas model
+func (RiwayatKolektibilitas) TableName() string {
+	return "riwayat_kolektibilitas"
+}
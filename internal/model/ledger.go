@@ -0,0 +1,97 @@
+package model
+
+import (
+	"errors"
+	"koperasi-service/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Ledger entry types, one per kind of money movement
+const (
+	LedgerTopup          = "topup"
+	LedgerAdjustment     = "adjustment"
+	LedgerWithdrawal     = "withdrawal"
+	LedgerSHUCredit      = "shu_credit" // A member's SHU paid into their sukarela wallet
+	LedgerReversal       = "reversal"
+	LedgerOpeningBalance = "opening_balance" // Carries over balances that existed before the ledger
+	LedgerAutoDebet      = "auto_debet"      // Installment debited from a wallet
+	LedgerJasaSimpanan   = "jasa_simpanan"   // Monthly return credited to a sukarela wallet
+	LedgerBerjangka      = "berjangka"       // Money moved into or out of a time deposit
+	LedgerBungaBerjangka = "bunga_berjangka" // Time-deposit interest credited at maturity
+	LedgerTransfer       = "transfer"        // Sukarela moved from one member to another
+)
+
+// Ledger accounts. Member wallets are liabilities of the koperasi, so a wallet
+// grows on the credit side; every other account is the counter side of a movement.
+const (
+	AkunSimpananAnggota = "simpanan_anggota" // One sub-account per Simpanan wallet (SimpananID set)
+	AkunKas             = "kas"              // Cash / bank
+	AkunPenyesuaian     = "penyesuaian"      // Admin adjustments
+	AkunSHU             = "shu_dibagikan"    // SHU distributed to members
+	AkunSaldoAwal       = "saldo_awal"       // Opening balances
+	AkunAngsuran        = "angsuran"         // Loan installments paid from wallets
+	AkunBebanJasa       = "beban_jasa"       // Jasa simpanan paid to members
+	AkunUtangPajak      = "utang_pajak"      // Tax withheld from jasa simpanan, owed to the tax office
+	AkunPendapatanLain  = "pendapatan_lain"  // Other income, such as early-break penalties
+)
+
+// ErrLedgerImmutable is returned when code tries to change or delete a posted ledger row
+var ErrLedgerImmutable = errors.New("ledger entries are immutable, post a reversal instead")
+
+// LedgerJournal groups the balanced entries of one money movement. Journals are append-only.
+type LedgerJournal struct {
+	ID             uint          `gorm:"primaryKey" json:"id"`
+	EntryType      string        `gorm:"type:varchar(30);not null;index" json:"entry_type"`
+	ReferenceTable string        `gorm:"type:varchar(50)" json:"reference_table"` // Source table (simpanan_transactions, ...)
+	ReferenceID    uint          `gorm:"index" json:"reference_id"`               // ID of the source record
+	ReversalOfID   *uint         `gorm:"uniqueIndex" json:"reversal_of_id"`       // Journal reversed by this one (a journal can be reversed once)
+	Description    string        `gorm:"type:text" json:"description"`
+	PostedAt       time.Time     `gorm:"not null;index" json:"posted_at"`
+	PostedBy       *uint         `json:"posted_by"` // Admin who posted the movement (nil for system postings)
+	CreatedAt      time.Time     `json:"created_at"`
+	Entries        []LedgerEntry `gorm:"foreignKey:JournalID" json:"entries,omitempty"`
+}
+
+// TableName specifies the table name for LedgerJournal model
+func (LedgerJournal) TableName() string {
+	return "ledger_journals"
+}
+
+// BeforeUpdate blocks edits to posted journals
+func (LedgerJournal) BeforeUpdate(tx *gorm.DB) error {
+	return ErrLedgerImmutable
+}
+
+// BeforeDelete blocks deletion of posted journals
+func (LedgerJournal) BeforeDelete(tx *gorm.DB) error {
+	return ErrLedgerImmutable
+}
+
+// LedgerEntry is a single debit or credit line of a journal
+type LedgerEntry struct {
+	ID         uint        `gorm:"primaryKey" json:"id"`
+	JournalID  uint        `gorm:"not null;index" json:"journal_id"`
+	Account    string      `gorm:"type:varchar(30);not null;index" json:"account"`
+	SimpananID *uint       `gorm:"index" json:"simpanan_id"` // Set for simpanan_anggota entries
+	Debit      money.Money `gorm:"type:decimal(15,2);not null;default:0" json:"debit"`
+	Credit     money.Money `gorm:"type:decimal(15,2);not null;default:0" json:"credit"`
+	PostedAt   time.Time   `gorm:"not null;index" json:"posted_at"` // Copied from the journal for as-of queries
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// TableName specifies the table name for LedgerEntry model
+func (LedgerEntry) TableName() string {
+	return "ledger_entries"
+}
+
+// BeforeUpdate blocks edits to posted entries
+func (LedgerEntry) BeforeUpdate(tx *gorm.DB) error {
+	return ErrLedgerImmutable
+}
+
+// BeforeDelete blocks deletion of posted entries
+func (LedgerEntry) BeforeDelete(tx *gorm.DB) error {
+	return ErrLedgerImmutable
+}
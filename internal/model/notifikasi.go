@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of member notifications
+const (
+	NotifikasiAutoDebetGagal      = "auto_debet_gagal"
+	NotifikasiBerjangkaJatuhTempo = "berjangka_jatuh_tempo"
+	NotifikasiTransferMasuk       = "transfer_masuk"
+	NotifikasiTransferDitolak     = "transfer_ditolak"
+)
+
+// Notifikasi is an in-app message to a member
+type Notifikasi struct {
+	gorm.Model
+	UserID         uint       `gorm:"not null;index" json:"user_id"`
+	Jenis          string     `gorm:"type:varchar(50);not null" json:"jenis"`
+	Judul          string     `gorm:"type:varchar(150);not null" json:"judul"`
+	Pesan          string     `gorm:"type:text" json:"pesan"`
+	ReferenceTable string     `gorm:"type:varchar(50)" json:"reference_table"`
+	ReferenceID    uint       `json:"reference_id"`
+	DibacaPada     *time.Time `json:"dibaca_pada"`
+}
+
+// TableName specifies the table name for Notifikasi model
+func (Notifikasi) TableName() string {
+	return "notifikasi"
+}
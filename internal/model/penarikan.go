@@ -0,0 +1,46 @@
+package model
+
+import (
+	"koperasi-service/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Statuses of a withdrawal request
+const (
+	PenarikanPending  = "pending"  // Requested, the amount is held on the wallet
+	PenarikanApproved = "approved" // Approved by an admin, waiting for the transfer
+	PenarikanRejected = "rejected" // Rejected, the hold is released
+	PenarikanPaid     = "paid"     // Transferred, the wallet is debited
+)
+
+// PenarikanSimpanan is a member's request to withdraw from their sukarela
+// wallet to a bank account
+type PenarikanSimpanan struct {
+	gorm.Model
+	SimpananID            uint        `gorm:"not null;index" json:"simpanan_id"`
+	UserID                uint        `gorm:"not null;index" json:"user_id"`
+	Jumlah                money.Money `gorm:"type:decimal(15,2);not null" json:"jumlah"`
+	NoRekening            string      `gorm:"type:varchar(50);not null" json:"no_rekening"`
+	BankName              string      `gorm:"type:varchar(100);not null" json:"bank_name"`
+	AtasNama              string      `gorm:"type:varchar(100)" json:"atas_nama"` // Account holder
+	Keterangan            string      `gorm:"type:text" json:"keterangan"`
+	Status                string      `gorm:"type:varchar(20);default:'pending';index" json:"status"`
+	DisetujuiOleh         *uint       `json:"disetujui_oleh"`
+	TanggalDisetujui      *time.Time  `json:"tanggal_disetujui"`
+	DitolakOleh           *uint       `json:"ditolak_oleh"`
+	TanggalDitolak        *time.Time  `json:"tanggal_ditolak"`
+	AlasanPenolakan       string      `gorm:"type:text" json:"alasan_penolakan"`
+	DibayarOleh           *uint       `json:"dibayar_oleh"`
+	TanggalDibayar        *time.Time  `json:"tanggal_dibayar"`
+	ReferensiTransfer     string      `gorm:"type:varchar(100)" json:"referensi_transfer"`
+	ImageBuktiTransfer    string      `gorm:"type:varchar(255)" json:"image_bukti_transfer"`
+	SimpananTransactionID *uint       `json:"simpanan_transaction_id"` // Withdrawal transaction in the wallet history
+	User                  User        `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName specifies the table name for PenarikanSimpanan model
+func (PenarikanSimpanan) TableName() string {
+	return "penarikan_simpanan"
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"koperasi-service/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Pencairan records the disbursement of a Pinjaman: the gross loan amount,
+// what was deducted from it and the net amount transferred to the member.
+// JumlahBersih = JumlahBruto - BiayaAdmin - BiayaProvisi - PremiAsuransi - JumlahPelunasan.
+type Pencairan struct {
+	gorm.Model
+	PinjamanID          uint        `gorm:"not null;uniqueIndex" json:"pinjaman_id"` // A loan is disbursed once
+	JumlahBruto         money.Money `gorm:"type:decimal(15,2);not null" json:"jumlah_bruto"`
+	BiayaAdmin          money.Money `gorm:"type:decimal(15,2);default:0" json:"biaya_admin"`
+	BiayaProvisi        money.Money `gorm:"type:decimal(15,2);default:0" json:"biaya_provisi"`
+	PremiAsuransi       money.Money `gorm:"type:decimal(15,2);default:0" json:"premi_asuransi"`
+	PelunasanPinjamanID *uint       `json:"pelunasan_pinjaman_id"` // Previous loan paid off from this disbursement
+	PelunasanAngsuranID *uint       `json:"pelunasan_angsuran_id"` // The pelunasan Angsuran of that loan
+	JumlahPelunasan     money.Money `gorm:"type:decimal(15,2);default:0" json:"jumlah_pelunasan"`
+	JumlahBersih        money.Money `gorm:"type:decimal(15,2);not null" json:"jumlah_bersih"` // Transferred to the member
+	ReferensiTransfer   string      `gorm:"type:varchar(100);not null" json:"referensi_transfer"`
+	NoRekening          string      `gorm:"type:varchar(50)" json:"no_rekening"`
+	BankName            string      `gorm:"type:varchar(100)" json:"bank_name"`
+	ImageBuktiTransfer  string      `gorm:"type:varchar(255)" json:"image_bukti_transfer"` // The download path when BuktiTransferID is set
+	BuktiTransferID     *uint       `gorm:"index" json:"bukti_transfer_id"`                // Uploaded receipt (Berkas)
+	DicairkanOleh       uint        `gorm:"not null" json:"dicairkan_oleh"`
+	TanggalPencairan    time.Time   `gorm:"not null;index" json:"tanggal_pencairan"`
+}
+
+// TableName specifies the table name for Pencairan model
+func (Pencairan) TableName() string {
+	return "pencairan"
+}
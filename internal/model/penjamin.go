@@ -0,0 +1,20 @@
+package model
+
+import "gorm.io/gorm"
+
+// Penjamin links a Pinjaman to a co-member who guarantees it. A member
+// guarantees a loan at most once.
+type Penjamin struct {
+	gorm.Model
+	PinjamanID uint   `gorm:"not null;uniqueIndex:idx_penjamin_pinjaman_user" json:"pinjaman_id"`
+	UserID     uint   `gorm:"not null;uniqueIndex:idx_penjamin_pinjaman_user;index" json:"user_id"` // The guarantor
+	Hubungan   string `gorm:"type:varchar(50)" json:"hubungan"`                                     // Relation to the borrower, e.g. rekan kerja
+	Keterangan string `gorm:"type:text" json:"keterangan"`
+	CreatedBy  uint   `gorm:"not null" json:"created_by"`
+	User       User   `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName specifies the table name for Penjamin model
+func (Penjamin) TableName() string {
+	return "penjamin"
+}
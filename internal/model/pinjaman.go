@@ -1,28 +1,60 @@
 package model
 
 import (
+	"koperasi-service/pkg/money"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// Loan lifecycle statuses: proses -> disetujui/ditolak -> dicairkan -> lunas/macet
+const (
+	StatusPinjamanProses    = "proses"    // Submitted, waiting for a decision
+	StatusPinjamanDisetujui = "disetujui" // Approved, not yet disbursed
+	StatusPinjamanDitolak   = "ditolak"   // Rejected
+	StatusPinjamanDicairkan = "dicairkan" // Disbursed, installments running
+	StatusPinjamanLunas     = "lunas"     // Fully repaid
+	StatusPinjamanMacet     = "macet"     // Written off as bad debt
+)
+
 // Pinjaman represents a loan record in the system with installment tracking
 type Pinjaman struct {
 	gorm.Model
-	KodePinjaman        string       `gorm:"type:varchar(20);uniqueIndex;not null" json:"kode_pinjaman"`
-	UserID              uint         `gorm:"not null" json:"user_id"` // References users table
-	TanggalPinjam       time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"tanggal_pinjam"`
-	JumlahPinjaman      float64      `gorm:"type:decimal(15,2);not null" json:"jumlah_pinjaman"`
-	BungaOptionID       *uint        `gorm:"index" json:"bunga_option_id"`                   // References bunga_options table (nullable for backward compatibility)
-	BungaPersen         float64      `gorm:"type:decimal(5,2);not null" json:"bunga_persen"` // Copied from selected option for historical record
-	LamaBulan           int          `gorm:"not null" json:"lama_bulan"`
-	JumlahAngsuran      float64      `gorm:"type:decimal(15,2);not null" json:"jumlah_angsuran"`
-	SisaAngsuran        int          `gorm:"not null" json:"sisa_angsuran"`
-	Status              string       `gorm:"type:varchar(20);check:status IN ('proses', 'disetujui', 'lunas', 'macet')" json:"status"`
-	NoRekeningPencairan string       `gorm:"type:varchar(50)" json:"no_rekening_pencairan"` // Account number for loan disbursement
-	BankName            string       `gorm:"type:varchar(100)" json:"bank_name"`            // Bank name for disbursement
-	User                User         `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	BungaOption         *BungaOption `gorm:"foreignKey:BungaOptionID" json:"bunga_option,omitempty"`
+	KodePinjaman        string         `gorm:"type:varchar(20);uniqueIndex;not null" json:"kode_pinjaman"`
+	UserID              uint           `gorm:"not null" json:"user_id"` // References users table
+	TanggalPinjam       time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"tanggal_pinjam"`
+	JenisPinjamanID     *uint          `gorm:"index" json:"jenis_pinjaman_id"` // References jenis_pinjaman table (nullable for loans without a product)
+	JumlahPinjaman      money.Money    `gorm:"type:decimal(15,2);not null" json:"jumlah_pinjaman"`
+	BungaOptionID       *uint          `gorm:"index" json:"bunga_option_id"`                        // References bunga_options table (nullable for backward compatibility)
+	BungaOptionVersiID  *uint          `json:"bunga_option_versi_id"`                               // Option rate version in effect on tanggal_pinjam
+	BungaPersen         float64        `gorm:"type:decimal(5,2);not null" json:"bunga_persen"`      // Monthly rate, copied from selected option for historical record
+	MetodeBunga         string         `gorm:"type:varchar(20);default:'flat'" json:"metode_bunga"` // flat, efektif, anuitas
+	LamaBulan           int            `gorm:"not null" json:"lama_bulan"`
+	PenghasilanBulanan  money.Money    `gorm:"type:decimal(15,2);default:0" json:"penghasilan_bulanan"` // Monthly income declared by the borrower, for the debt-service ratio
+	JumlahAngsuran      money.Money    `gorm:"type:decimal(15,2);not null" json:"jumlah_angsuran"`      // First installment of the schedule
+	BiayaAdmin          money.Money    `gorm:"type:decimal(15,2);default:0" json:"biaya_admin"`         // Copied from the product
+	BiayaProvisi        money.Money    `gorm:"type:decimal(15,2);default:0" json:"biaya_provisi"`       // Product provision percent of jumlah pinjaman
+	SisaAngsuran        int            `gorm:"not null" json:"sisa_angsuran"`
+	Status              string         `gorm:"type:varchar(20);check:status IN ('proses', 'disetujui', 'ditolak', 'dicairkan', 'lunas', 'macet')" json:"status"`
+	NoRekeningPencairan string         `gorm:"type:varchar(50)" json:"no_rekening_pencairan"` // Account number for loan disbursement
+	BankName            string         `gorm:"type:varchar(100)" json:"bank_name"`            // Bank name for disbursement
+	DiputuskanOleh      *uint          `json:"diputuskan_oleh"`                               // Admin who approved or rejected the loan
+	TanggalKeputusan    *time.Time     `json:"tanggal_keputusan"`                             // When the loan was approved or rejected
+	AlasanPenolakan     string         `gorm:"type:text" json:"alasan_penolakan"`
+	ReferensiPencairan  string         `gorm:"type:varchar(100)" json:"referensi_pencairan"` // Transfer reference of the disbursement
+	DicairkanOleh       *uint          `json:"dicairkan_oleh"`
+	TanggalPencairan    *time.Time     `json:"tanggal_pencairan"`
+	TanggalLunas        *time.Time     `json:"tanggal_lunas"`
+	HapusBukuOleh       *uint          `json:"hapus_buku_oleh"` // Super admin who wrote the loan off
+	TanggalHapusBuku    *time.Time     `json:"tanggal_hapus_buku"`
+	AlasanHapusBuku     string         `gorm:"type:text" json:"alasan_hapus_buku"`
+	Kolektibilitas      int            `gorm:"default:1" json:"kolektibilitas"` // Collectibility bucket 1-5, see KolektibilitasLancar
+	HariTunggakan       int            `gorm:"default:0" json:"hari_tunggakan"` // Days past due of the oldest unpaid installment
+	TanggalKlasifikasi  *time.Time     `json:"tanggal_klasifikasi"`             // Last classification run
+	VersiJadwal         int            `gorm:"default:1" json:"versi_jadwal"`   // Current schedule version, raised by each restructuring
+	User                User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	BungaOption         *BungaOption   `gorm:"foreignKey:BungaOptionID" json:"bunga_option,omitempty"`
+	JenisPinjaman       *JenisPinjaman `gorm:"foreignKey:JenisPinjamanID" json:"jenis_pinjaman,omitempty"`
 }
 
 // TableName specifies the table name for Pinjaman model
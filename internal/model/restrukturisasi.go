@@ -0,0 +1,48 @@
+package model
+
+import (
+	"koperasi-service/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Statuses of a restructuring request
+const (
+	StatusRestrukturisasiDiajukan  = "diajukan"  // Waiting for an admin decision
+	StatusRestrukturisasiDisetujui = "disetujui" // Applied, the loan runs on the new schedule version
+	StatusRestrukturisasiDitolak   = "ditolak"
+)
+
+// Restrukturisasi is a request to reschedule a disbursed loan. Approving it
+// creates a new schedule version; the original loan terms and the previous
+// schedule versions are kept for reporting.
+type Restrukturisasi struct {
+	gorm.Model
+	PinjamanID            uint    `gorm:"not null;index" json:"pinjaman_id"`
+	Status                string  `gorm:"type:varchar(20);default:'diajukan'" json:"status"`
+	LamaBulan             int     `gorm:"not null" json:"lama_bulan"`                          // New tenor for the remaining principal, after the grace period
+	BungaPersen           float64 `gorm:"type:decimal(5,2);not null" json:"bunga_persen"`      // New monthly rate
+	MetodeBunga           string  `gorm:"type:varchar(20);default:'flat'" json:"metode_bunga"` // New interest method
+	MasaTenggangBulan     int     `gorm:"default:0" json:"masa_tenggang_bulan"`                // Interest-only months before principal is repaid
+	KapitalisasiTunggakan bool    `gorm:"default:false" json:"kapitalisasi_tunggakan"`         // Add unpaid bunga and denda to the principal
+	Alasan                string  `gorm:"type:text" json:"alasan"`
+	DiajukanOleh          uint    `gorm:"not null" json:"diajukan_oleh"`
+	// Filled in when the request is decided
+	DiputuskanOleh   *uint       `json:"diputuskan_oleh"`
+	TanggalKeputusan *time.Time  `json:"tanggal_keputusan"`
+	AlasanPenolakan  string      `gorm:"type:text" json:"alasan_penolakan"`
+	VersiLama        int         `json:"versi_lama"` // Schedule version replaced
+	VersiBaru        int         `json:"versi_baru"` // Schedule version created
+	SisaPokokLama    money.Money `gorm:"type:decimal(15,2);default:0" json:"sisa_pokok_lama"`
+	TunggakanBunga   money.Money `gorm:"type:decimal(15,2);default:0" json:"tunggakan_bunga"` // Unpaid bunga of due installments at approval
+	TunggakanDenda   money.Money `gorm:"type:decimal(15,2);default:0" json:"tunggakan_denda"`
+	PokokBaru        money.Money `gorm:"type:decimal(15,2);default:0" json:"pokok_baru"` // Principal of the new schedule
+	JumlahAngsuran   money.Money `gorm:"type:decimal(15,2);default:0" json:"jumlah_angsuran"`
+	Pinjaman         Pinjaman    `gorm:"foreignKey:PinjamanID" json:"pinjaman,omitempty"`
+}
+
+// TableName specifies the table name for Restrukturisasi model
+func (Restrukturisasi) TableName() string {
+	return "restrukturisasi"
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"koperasi-service/pkg/money"
 	"time"
 
 	"gorm.io/gorm"
@@ -11,13 +12,17 @@ type SHUAnggotaRecord struct {
 	ID          uint           `json:"id_shu_anggota" gorm:"primaryKey;column:id_shu_anggota"`
 	SHUID       uint           `json:"id_shu" gorm:"column:id_shu;not null"`
 	UserID      uint           `json:"id_anggota" gorm:"column:id_anggota;not null"`
-	JumlahModal float64        `json:"jumlah_modal" gorm:"column:jumlah_modal;type:decimal(15,2);not null"`
-	JumlahUsaha float64        `json:"jumlah_usaha" gorm:"column:jumlah_usaha;type:decimal(15,2);not null"`
-	SHUDiterima float64        `json:"shu_diterima" gorm:"column:shu_diterima;type:decimal(15,2);not null"`
+	JumlahModal money.Money    `json:"jumlah_modal" gorm:"column:jumlah_modal;type:decimal(15,2);not null"`
+	JumlahUsaha money.Money    `json:"jumlah_usaha" gorm:"column:jumlah_usaha;type:decimal(15,2);not null"`
+	SHUDiterima money.Money    `json:"shu_diterima" gorm:"column:shu_diterima;type:decimal(15,2);not null"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
 
+	// Set once SHUDiterima is credited to the member's sukarela wallet
+	SimpananTransactionID *uint      `json:"simpanan_transaction_id" gorm:"column:simpanan_transaction_id"`
+	DikreditkanPada       *time.Time `json:"dikreditkan_pada" gorm:"column:dikreditkan_pada"`
+
 	// Relationships
 	SHU  SHUTahunan `json:"shu,omitempty" gorm:"foreignKey:SHUID;references:ID"`
 	User User       `json:"user,omitempty" gorm:"foreignKey:UserID;references:ID"`
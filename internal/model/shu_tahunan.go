@@ -1,6 +1,7 @@
 package model
 
 import (
+	"koperasi-service/pkg/money"
 	"time"
 
 	"gorm.io/gorm"
@@ -9,41 +10,44 @@ import (
 // SHUTahunan represents annual profit sharing (Sisa Hasil Usaha) record
 type SHUTahunan struct {
 	gorm.Model
-	Tahun                    int       `gorm:"not null;index" json:"tahun"`
-	PendapatanOperasional    float64   `gorm:"type:decimal(15,2);default:0" json:"pendapatan_operasional"`
-	PendapatanNonOperasional float64   `gorm:"type:decimal(15,2);default:0" json:"pendapatan_non_operasional"`
-	BebanOperasional         float64   `gorm:"type:decimal(15,2);default:0" json:"beban_operasional"`
-	BebanNonOperasional      float64   `gorm:"type:decimal(15,2);default:0" json:"beban_non_operasional"`
-	BebanPajak               float64   `gorm:"type:decimal(15,2);default:0" json:"beban_pajak"`
-	TotalSHU                 float64   `gorm:"type:decimal(15,2);not null" json:"total_shu"`
-	TanggalHitung            time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"tanggal_hitung"`
-	Status                   string    `gorm:"type:varchar(20);check:status IN ('draft', 'final')" json:"status"`
+	Tahun                    int         `gorm:"not null;index" json:"tahun"`
+	PendapatanOperasional    money.Money `gorm:"type:decimal(15,2);default:0" json:"pendapatan_operasional"`
+	PendapatanNonOperasional money.Money `gorm:"type:decimal(15,2);default:0" json:"pendapatan_non_operasional"`
+	BebanOperasional         money.Money `gorm:"type:decimal(15,2);default:0" json:"beban_operasional"`
+	BebanNonOperasional      money.Money `gorm:"type:decimal(15,2);default:0" json:"beban_non_operasional"`
+	BebanPajak               money.Money `gorm:"type:decimal(15,2);default:0" json:"beban_pajak"`
+	TotalSHU                 money.Money `gorm:"type:decimal(15,2);not null" json:"total_shu"`
+	TanggalHitung            time.Time   `gorm:"default:CURRENT_TIMESTAMP" json:"tanggal_hitung"`
+	Status                   string      `gorm:"type:varchar(20);check:status IN ('draft', 'final')" json:"status"`
 }
 
 // SHUAnggota represents individual member's SHU calculation result
 type SHUAnggota struct {
-	UserID          uint    `json:"user_id"`
-	Email           string  `json:"email"`
-	TotalSimpanan   float64 `json:"total_simpanan"`
-	TotalPenjualan  float64 `json:"total_penjualan"`
-	JasaModal       float64 `json:"jasa_modal"`
-	JasaUsaha       float64 `json:"jasa_usaha"`
-	TotalSHUAnggota float64 `json:"total_shu_anggota"`
+	UserID          uint        `json:"user_id"`
+	Email           string      `json:"email"`
+	TotalSimpanan   money.Money `json:"total_simpanan"`
+	TotalPenjualan  money.Money `json:"total_penjualan"`
+	JasaModal       money.Money `json:"jasa_modal"`
+	JasaUsaha       money.Money `json:"jasa_usaha"`
+	TotalSHUAnggota money.Money `json:"total_shu_anggota"`
+	// Simpanan wajib months due before the end of the year and still unpaid
+	TunggakanWajibBulan int  `json:"tunggakan_wajib_bulan"`
+	WajibLancar         bool `json:"wajib_lancar"`
 }
 
 // SHUReport represents the complete SHU calculation report
 type SHUReport struct {
 	Tahun                    int          `json:"tahun"`
-	PendapatanOperasional    float64      `json:"pendapatan_operasional"`
-	PendapatanNonOperasional float64      `json:"pendapatan_non_operasional"`
-	BebanOperasional         float64      `json:"beban_operasional"`
-	BebanNonOperasional      float64      `json:"beban_non_operasional"`
-	BebanPajak               float64      `json:"beban_pajak"`
-	TotalSHUKoperasi         float64      `json:"total_shu_koperasi"`
+	PendapatanOperasional    money.Money  `json:"pendapatan_operasional"`
+	PendapatanNonOperasional money.Money  `json:"pendapatan_non_operasional"`
+	BebanOperasional         money.Money  `json:"beban_operasional"`
+	BebanNonOperasional      money.Money  `json:"beban_non_operasional"`
+	BebanPajak               money.Money  `json:"beban_pajak"`
+	TotalSHUKoperasi         money.Money  `json:"total_shu_koperasi"`
 	PersenJasaModal          float64      `json:"persen_jasa_modal"`
 	PersenJasaUsaha          float64      `json:"persen_jasa_usaha"`
-	TotalSimpananAll         float64      `json:"total_simpanan_all"`
-	TotalPenjualanAll        float64      `json:"total_penjualan_all"`
+	TotalSimpananAll         money.Money  `json:"total_simpanan_all"`
+	TotalPenjualanAll        money.Money  `json:"total_penjualan_all"`
 	TanggalHitung            time.Time    `json:"tanggal_hitung"`
 	DetailAnggota            []SHUAnggota `json:"detail_anggota"`
 }
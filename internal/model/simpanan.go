@@ -1,14 +1,23 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"koperasi-service/pkg/money"
 
-// Simpanan represents a savings wallet for each user with three types
+	"gorm.io/gorm"
+)
+
+// Simpanan represents a savings wallet for each user with three types, plus
+// one wallet per time deposit
 type Simpanan struct {
 	gorm.Model
-	UserID      uint
-	Type        string  // "pokok", "wajib", "sukarela"
-	Balance     float64 // Current balance in the wallet
-	Description string
+	UserID  uint
+	Type    string      // Kode of a jenis simpanan (e.g. "pokok", "wajib", "sukarela"), or "berjangka"
+	Balance money.Money `gorm:"type:decimal(15,2)"` // Current balance in the wallet
+	// Part of Balance held for pending withdrawal requests; it cannot be spent
+	SaldoDitahan money.Money `gorm:"type:decimal(15,2);default:0"`
+	Description  string
+	// The time deposit of a berjangka wallet
+	Berjangka *SimpananBerjangka `gorm:"foreignKey:SimpananID" json:"berjangka,omitempty"`
 }
 
 // SimpananTransaction represents top-up or adjustment transactions
@@ -16,11 +25,23 @@ type SimpananTransaction struct {
 	gorm.Model
 	SimpananID   uint // Reference to the simpanan wallet
 	Simpanan     Simpanan
-	Type         string  // "topup", "adjustment"
-	Amount       float64 // Amount of transaction (positive for topup, negative for deduction)
+	Type         string      // "topup", "adjustment", "reversal", "withdrawal", "jasa", "shu", "berjangka", "bunga", "transfer"
+	Amount       money.Money `gorm:"type:decimal(15,2)"` // Amount of transaction (positive for topup, negative for deduction)
 	Description  string
 	Status       string // "pending", "verified", "rejected"
 	VerifiedByID *uint  // Admin who verified the transaction
 	VerifiedBy   *User  `gorm:"foreignKey:VerifiedByID"`
 	VerifiedAt   *gorm.DeletedAt
+	// Ledger journal that moved the money; set once the transaction is verified.
+	// Verified transactions are never edited, corrections are posted as reversals.
+	LedgerJournalID *uint `gorm:"index"`
+	// The other leg of a move between two wallets
+	PasanganID *uint
+	// Uploaded receipt (Berkas) of a top-up
+	BuktiTransferID *uint `gorm:"index"`
+}
+
+// SaldoTersedia returns the balance that can be spent or withdrawn
+func (s *Simpanan) SaldoTersedia() money.Money {
+	return s.Balance - s.SaldoDitahan
 }
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"koperasi-service/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Statuses of a simpanan berjangka
+const (
+	BerjangkaMenunggu      = "menunggu" // Waiting for the transfer to be verified
+	BerjangkaAktif         = "aktif"
+	BerjangkaDitolak       = "ditolak"        // The transfer was rejected
+	BerjangkaDicairkan     = "dicairkan"      // Paid out to sukarela at maturity
+	BerjangkaDiperpanjang  = "diperpanjang"   // Rolled over into a new deposit at maturity
+	BerjangkaDicairkanDini = "dicairkan_dini" // Broken before maturity
+)
+
+// What happens to a simpanan berjangka at maturity
+const (
+	JatuhTempoCair            = "cair"             // Principal and interest go to sukarela
+	JatuhTempoPerpanjang      = "perpanjang"       // Principal and interest roll over
+	JatuhTempoPerpanjangPokok = "perpanjang_pokok" // Principal rolls over, interest goes to sukarela
+)
+
+// Where the money of a new simpanan berjangka comes from
+const (
+	SumberDanaSukarela     = "sukarela"     // Moved from the member's sukarela wallet
+	SumberDanaTransfer     = "transfer"     // Bank transfer verified by an admin
+	SumberDanaPerpanjangan = "perpanjangan" // Rolled over from a matured deposit
+)
+
+// JenisSimpananBerjangka is a time-deposit product such as "Berjangka 6
+// Bulan". A deposit locks the product's rate and penalty when it starts.
+type JenisSimpananBerjangka struct {
+	gorm.Model
+	Nama           string      `gorm:"type:varchar(50);not null" json:"nama"`
+	Deskripsi      string      `gorm:"type:text" json:"deskripsi"`
+	TenorBulan     int         `gorm:"not null" json:"tenor_bulan"`                         // 3, 6 or 12
+	PersenTahunan  float64     `gorm:"type:decimal(5,2);not null" json:"persen_tahunan"`    // Annual rate, applied per day (365-day year)
+	MinimalNominal money.Money `gorm:"type:decimal(15,2);default:0" json:"minimal_nominal"` // Smallest amount that can be placed
+	PersenPenalti  float64     `gorm:"type:decimal(5,2);default:0" json:"persen_penalti"`   // Early-break penalty, percent of the principal
+	IsActive       bool        `gorm:"default:true" json:"is_active"`                       // Inactive products accept no new deposits or rollovers
+	CreatedBy      uint        `gorm:"not null" json:"created_by"`                          // Admin who created this product
+	CreatedByUser  User        `gorm:"foreignKey:CreatedBy" json:"created_by_user,omitempty"`
+}
+
+// TableName specifies the table name for JenisSimpananBerjangka model
+func (JenisSimpananBerjangka) TableName() string {
+	return "jenis_simpanan_berjangka"
+}
+
+// SimpananBerjangka is one time deposit of a member. Its money sits in a
+// wallet of its own (Simpanan type "berjangka") and is held there until the
+// deposit ends, so it cannot be spent or withdrawn.
+type SimpananBerjangka struct {
+	gorm.Model
+	SimpananID          uint                    `gorm:"not null;uniqueIndex" json:"simpanan_id"` // The deposit's wallet
+	UserID              uint                    `gorm:"not null;index" json:"user_id"`
+	JenisID             uint                    `gorm:"not null;index" json:"jenis_id"`
+	Nominal             money.Money             `gorm:"type:decimal(15,2);not null" json:"nominal"` // Principal
+	TenorBulan          int                     `gorm:"not null" json:"tenor_bulan"`
+	PersenTahunan       float64                 `gorm:"type:decimal(5,2);not null" json:"persen_tahunan"` // Locked when the deposit starts
+	PersenPenalti       float64                 `gorm:"type:decimal(5,2);default:0" json:"persen_penalti"`
+	SumberDana          string                  `gorm:"type:varchar(20);not null" json:"sumber_dana"`
+	InstruksiJatuhTempo string                  `gorm:"type:varchar(20);default:'cair'" json:"instruksi_jatuh_tempo"`
+	Status              string                  `gorm:"type:varchar(20);not null;index" json:"status"`
+	TanggalMulai        *time.Time              `json:"tanggal_mulai"`
+	TanggalJatuhTempo   *time.Time              `gorm:"index" json:"tanggal_jatuh_tempo"`
+	Bunga               money.Money             `gorm:"type:decimal(15,2);default:0" json:"bunga"`   // Interest posted at maturity
+	Penalti             money.Money             `gorm:"type:decimal(15,2);default:0" json:"penalti"` // Charged when broken early
+	TanggalSelesai      *time.Time              `json:"tanggal_selesai"`
+	DiperpanjangDariID  *uint                   `gorm:"index" json:"diperpanjang_dari_id"` // Deposit this one rolled over from
+	DiperpanjangKeID    *uint                   `json:"diperpanjang_ke_id"`                // Deposit this one rolled over into
+	Keterangan          string                  `gorm:"type:text" json:"keterangan"`
+	Jenis               *JenisSimpananBerjangka `gorm:"foreignKey:JenisID" json:"jenis,omitempty"`
+}
+
+// TableName specifies the table name for SimpananBerjangka model
+func (SimpananBerjangka) TableName() string {
+	return "simpanan_berjangka"
+}
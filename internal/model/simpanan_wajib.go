@@ -0,0 +1,64 @@
+package model
+
+import (
+	"koperasi-service/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Statuses of a monthly simpanan wajib obligation
+const (
+	KewajibanWajibBelumBayar = "belum_bayar"
+	KewajibanWajibSebagian   = "sebagian" // Partly paid
+	KewajibanWajibLunas      = "lunas"
+)
+
+// AturanSimpananWajib is the admin-configurable monthly simpanan wajib amount.
+// At most one rule is active at a time; without an active rule no obligations
+// are created.
+type AturanSimpananWajib struct {
+	gorm.Model
+	Nama              string      `gorm:"type:varchar(50);not null" json:"nama"`
+	Nominal           money.Money `gorm:"type:decimal(15,2);not null" json:"nominal"` // Amount owed every month
+	TanggalJatuhTempo int         `gorm:"default:10" json:"tanggal_jatuh_tempo"`      // Day of the month the amount is due (1-28)
+	Deskripsi         string      `gorm:"type:text" json:"deskripsi"`
+	IsActive          bool        `gorm:"default:false" json:"is_active"`
+	CreatedBy         uint        `gorm:"not null" json:"created_by"` // Admin who created this rule
+	CreatedByUser     User        `gorm:"foreignKey:CreatedBy" json:"created_by_user,omitempty"`
+}
+
+// TableName specifies the table name for AturanSimpananWajib model
+func (AturanSimpananWajib) TableName() string {
+	return "aturan_simpanan_wajib"
+}
+
+// SetCreatedBy records the admin who created the rule
+func (a *AturanSimpananWajib) SetCreatedBy(userID uint) {
+	a.CreatedBy = userID
+}
+
+// KewajibanWajib is one member's simpanan wajib obligation for one month.
+// Verified wajib top-ups are matched against open months, oldest first.
+type KewajibanWajib struct {
+	gorm.Model
+	UserID            uint        `gorm:"not null;uniqueIndex:idx_kewajiban_wajib_user_periode" json:"user_id"`
+	SimpananID        uint        `gorm:"not null;index" json:"simpanan_id"`                                              // The member's wajib wallet
+	Periode           time.Time   `gorm:"type:date;not null;uniqueIndex:idx_kewajiban_wajib_user_periode" json:"periode"` // First day of the month
+	AturanID          uint        `json:"aturan_id"`
+	Nominal           money.Money `gorm:"type:decimal(15,2);not null" json:"nominal"`
+	Terbayar          money.Money `gorm:"type:decimal(15,2);default:0" json:"terbayar"`
+	TanggalJatuhTempo time.Time   `gorm:"not null" json:"tanggal_jatuh_tempo"`
+	Status            string      `gorm:"type:varchar(20);default:'belum_bayar';index" json:"status"`
+	TanggalLunas      *time.Time  `json:"tanggal_lunas"`
+}
+
+// TableName specifies the table name for KewajibanWajib model
+func (KewajibanWajib) TableName() string {
+	return "kewajiban_simpanan_wajib"
+}
+
+// Sisa returns the amount still owed for the month
+func (k *KewajibanWajib) Sisa() money.Money {
+	return money.Max(k.Nominal-k.Terbayar, 0)
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"koperasi-service/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Statuses of a transfer between members
+const (
+	TransferMenungguKonfirmasi  = "menunggu_konfirmasi"  // Created, the sender has not confirmed it yet
+	TransferMenungguPersetujuan = "menunggu_persetujuan" // Confirmed above the approval threshold, the amount is held
+	TransferBerhasil            = "berhasil"             // Moved to the recipient
+	TransferDitolak             = "ditolak"              // Rejected by an admin, the hold is released
+	TransferDibatalkan          = "dibatalkan"           // Cancelled by the sender
+)
+
+// AturanTransfer is the admin-configurable limits on sukarela transfers between
+// members. At most one rule is active at a time; without an active rule
+// transfers have no limits and need no approval.
+type AturanTransfer struct {
+	gorm.Model
+	Nama              string      `gorm:"type:varchar(50);not null" json:"nama"`
+	MinimalTransfer   money.Money `gorm:"type:decimal(15,2);default:0" json:"minimal_transfer"`
+	BatasPerTransaksi money.Money `gorm:"type:decimal(15,2);default:0" json:"batas_per_transaksi"` // 0 means no limit
+	BatasHarian       money.Money `gorm:"type:decimal(15,2);default:0" json:"batas_harian"`        // Total a member can send per day, 0 means no limit
+	BatasPersetujuan  money.Money `gorm:"type:decimal(15,2);default:0" json:"batas_persetujuan"`   // Transfers of this amount or more need an admin, 0 means never
+	Deskripsi         string      `gorm:"type:text" json:"deskripsi"`
+	IsActive          bool        `gorm:"default:false" json:"is_active"`
+	CreatedBy         uint        `gorm:"not null" json:"created_by"` // Admin who created this rule
+	CreatedByUser     User        `gorm:"foreignKey:CreatedBy" json:"created_by_user,omitempty"`
+}
+
+// TableName specifies the table name for AturanTransfer model
+func (AturanTransfer) TableName() string {
+	return "aturan_transfer"
+}
+
+// SetCreatedBy records the admin who created the rule
+func (a *AturanTransfer) SetCreatedBy(userID uint) {
+	a.CreatedBy = userID
+}
+
+// TransferSukarela is a move from one member's sukarela wallet to another's.
+// Both legs are SimpananTransaction rows of type "transfer" linked to each other.
+type TransferSukarela struct {
+	gorm.Model
+	PengirimID         uint        `gorm:"not null;index" json:"pengirim_id"`
+	PenerimaID         uint        `gorm:"not null;index" json:"penerima_id"`
+	SimpananPengirimID uint        `gorm:"not null" json:"simpanan_pengirim_id"`
+	SimpananPenerimaID uint        `gorm:"not null" json:"simpanan_penerima_id"`
+	NamaPengirim       string      `gorm:"type:varchar(100)" json:"nama_pengirim"`
+	NamaPenerima       string      `gorm:"type:varchar(100)" json:"nama_penerima"` // Shown to the sender to confirm
+	Jumlah             money.Money `gorm:"type:decimal(15,2);not null" json:"jumlah"`
+	Keterangan         string      `gorm:"type:text" json:"keterangan"`
+	Status             string      `gorm:"type:varchar(20);not null;index" json:"status"`
+	PerluPersetujuan   bool        `json:"perlu_persetujuan"` // Needs an admin under the active rule
+	BatasKonfirmasi    time.Time   `json:"batas_konfirmasi"`  // Unconfirmed transfers expire after this
+	DikonfirmasiPada   *time.Time  `gorm:"index" json:"dikonfirmasi_pada"`
+	DisetujuiOleh      *uint       `json:"disetujui_oleh"`
+	DitolakOleh        *uint       `json:"ditolak_oleh"`
+	AlasanPenolakan    string      `gorm:"type:text" json:"alasan_penolakan"`
+	TanggalSelesai     *time.Time  `json:"tanggal_selesai"`     // Moved, rejected or cancelled
+	TransaksiKeluarID  *uint       `json:"transaksi_keluar_id"` // Leg in the sender's wallet history
+	TransaksiMasukID   *uint       `json:"transaksi_masuk_id"`  // Leg in the recipient's wallet history
+}
+
+// TableName specifies the table name for TransferSukarela model
+func (TransferSukarela) TableName() string {
+	return "transfer_sukarela"
+}
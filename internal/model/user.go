@@ -1,6 +1,17 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Membership states of a member
+const (
+	StatusCalonAnggota  = "calon_anggota" // Registered, simpanan pokok not yet verified
+	StatusAnggotaAktif  = "aktif"
+	StatusAnggotaKeluar = "keluar" // Resigned, simpanan refunded
+)
 
 type User struct {
 	gorm.Model
@@ -14,6 +25,11 @@ type User struct {
 	Role        Role
 	AdminID     *uint `gorm:"index"`              // References the admin who registered this user
 	Admin       *User `gorm:"foreignKey:AdminID"` // The admin who registered this user
+	// Membership state; users that predate it are aktif
+	StatusKeanggotaan string     `gorm:"type:varchar(20);default:'aktif';index"`
+	TanggalAktif      *time.Time // When the simpanan pokok was verified
+	TanggalKeluar     *time.Time
+	AlasanKeluar      string
 }
 
 type Role struct {
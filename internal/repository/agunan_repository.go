@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"koperasi-service/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AgunanRepository handles persistence for loan collateral
+type AgunanRepository struct {
+	db *gorm.DB
+}
+
+// NewAgunanRepository constructs a new repository instance
+func NewAgunanRepository(db *gorm.DB) *AgunanRepository {
+	return &AgunanRepository{db: db}
+}
+
+// Create inserts a collateral item
+func (r *AgunanRepository) Create(a *model.Agunan) error {
+	return r.db.Create(a).Error
+}
+
+// GetByID returns a collateral item
+func (r *AgunanRepository) GetByID(id uint) (*model.Agunan, error) {
+	var a model.Agunan
+	if err := r.db.First(&a, id).Error; err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// GetByPinjaman returns the collateral of a loan
+func (r *AgunanRepository) GetByPinjaman(pinjamanID uint) ([]model.Agunan, error) {
+	var list []model.Agunan
+	if err := r.db.Where("pinjaman_id = ?", pinjamanID).Order("id").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// KembalikanByPinjaman marks every held collateral item of a loan as returned.
+// oleh is nil when the system closed the loan.
+func (r *AgunanRepository) KembalikanByPinjaman(pinjamanID uint, oleh *uint, at time.Time) error {
+	return r.db.Model(&model.Agunan{}).
+		Where("pinjaman_id = ? AND status = ?", pinjamanID, model.StatusAgunanDitahan).
+		Updates(map[string]interface{}{
+			"status":               model.StatusAgunanDikembalikan,
+			"tanggal_dikembalikan": at,
+			"dikembalikan_oleh":    oleh,
+		}).Error
+}
+
+// Delete removes a collateral item
+func (r *AgunanRepository) Delete(id uint) error {
+	return r.db.Delete(&model.Agunan{}, id).Error
+}
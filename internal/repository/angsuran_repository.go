@@ -4,6 +4,7 @@ import (
 	"koperasi-service/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // AngsuranRepository handles persistence for Angsuran entities
@@ -42,7 +43,17 @@ func (r *AngsuranRepository) GetAll(userID uint, pinjamanID uint) ([]model.Angsu
 // GetByID returns single angsuran by id with relations preloaded
 func (r *AngsuranRepository) GetByID(id uint) (*model.Angsuran, error) {
 	var a model.Angsuran
-	if err := r.db.Preload("Pinjaman").Preload("User").First(&a, id).Error; err != nil {
+	if err := r.db.Preload("Pinjaman").Preload("User").Preload("Alokasi").First(&a, id).Error; err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// GetByIDForUpdate returns an angsuran and takes a row lock on it.
+// Must be called inside UnitOfWork.Do.
+func (r *AngsuranRepository) GetByIDForUpdate(id uint) (*model.Angsuran, error) {
+	var a model.Angsuran
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&a, id).Error; err != nil {
 		return nil, err
 	}
 	return &a, nil
@@ -53,6 +64,14 @@ func (r *AngsuranRepository) Update(a *model.Angsuran) error {
 	return r.db.Save(a).Error
 }
 
+// CreateAlokasi stores the allocation rows of a verified payment
+func (r *AngsuranRepository) CreateAlokasi(rows []model.AlokasiAngsuran) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return r.db.Create(&rows).Error
+}
+
 // Delete removes an Angsuran by id
 func (r *AngsuranRepository) Delete(id uint) error {
 	return r.db.Delete(&model.Angsuran{}, id).Error
@@ -101,6 +120,13 @@ func (r *AngsuranRepository) GetByAdminUserID(adminID uint, pinjamanID uint) ([]
 	return list, nil
 }
 
+// HasPendingByPinjaman reports whether a payment of the loan is still waiting for verification
+func (r *AngsuranRepository) HasPendingByPinjaman(pinjamanID uint) (bool, error) {
+	var n int64
+	err := r.db.Model(&model.Angsuran{}).Where("pinjaman_id = ? AND status = ?", pinjamanID, "proses").Count(&n).Error
+	return n > 0, err
+}
+
 // GetNextAngsuranKe returns the next installment number for a loan
 func (r *AngsuranRepository) GetNextAngsuranKe(pinjamanID uint) (int, error) {
 	var maxAngsuranKe int
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// AturanRepository stores one kind of admin-configurable rule, such as
+// model.AturanDenda. At most one rule of a kind is active at a time.
+type AturanRepository[T any] interface {
+	Create(aturan *T) error
+	GetByID(id uint) (*T, error)
+	GetAll() ([]T, error)
+	GetActive() (*T, error)
+	Update(aturan *T) error
+	Delete(id uint) error
+	SetActive(id uint, isActive bool) error
+}
+
+type aturanRepository[T any] struct {
+	db *gorm.DB
+}
+
+func NewAturanRepository[T any](db *gorm.DB) AturanRepository[T] {
+	return &aturanRepository[T]{db: db}
+}
+
+func (r *aturanRepository[T]) Create(aturan *T) error {
+	return r.db.Create(aturan).Error
+}
+
+func (r *aturanRepository[T]) GetByID(id uint) (*T, error) {
+	var aturan T
+	err := r.db.Preload("CreatedByUser").First(&aturan, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &aturan, nil
+}
+
+func (r *aturanRepository[T]) GetAll() ([]T, error) {
+	var list []T
+	err := r.db.Preload("CreatedByUser").Order("id").Find(&list).Error
+	return list, err
+}
+
+// GetActive returns the active rule, or nil when none is active
+func (r *aturanRepository[T]) GetActive() (*T, error) {
+	var list []T
+	if err := r.db.Where("is_active = ?", true).Order("updated_at DESC").Limit(1).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return &list[0], nil
+}
+
+func (r *aturanRepository[T]) Update(aturan *T) error {
+	return r.db.Omit("CreatedByUser").Save(aturan).Error
+}
+
+func (r *aturanRepository[T]) Delete(id uint) error {
+	return r.db.Delete(new(T), id).Error
+}
+
+// SetActive activates or deactivates a rule; activating one deactivates the others
+func (r *aturanRepository[T]) SetActive(id uint, isActive bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if isActive {
+			if err := tx.Model(new(T)).Where("id <> ? AND is_active = ?", id, true).Update("is_active", false).Error; err != nil {
+				return err
+			}
+		}
+		res := tx.Model(new(T)).Where("id = ?", id).Update("is_active", isActive)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"koperasi-service/internal/model"
+	"koperasi-service/pkg/money"
 	"time"
 
 	"gorm.io/gorm"
@@ -173,16 +174,16 @@ type TransactionHistoryRepository interface {
 }
 
 type TransactionHistoryFilters struct {
-	UserID          *uint      `json:"user_id"`
-	TransactionType string     `json:"transaction_type"`
-	Status          string     `json:"status"`
-	StartDate       *time.Time `json:"start_date"`
-	EndDate         *time.Time `json:"end_date"`
-	MinAmount       *float64   `json:"min_amount"`
-	MaxAmount       *float64   `json:"max_amount"`
-	VerifiedBy      *uint      `json:"verified_by"`
-	Limit           int        `json:"limit"`
-	Offset          int        `json:"offset"`
+	UserID          *uint        `json:"user_id"`
+	TransactionType string       `json:"transaction_type"`
+	Status          string       `json:"status"`
+	StartDate       *time.Time   `json:"start_date"`
+	EndDate         *time.Time   `json:"end_date"`
+	MinAmount       *money.Money `json:"min_amount"`
+	MaxAmount       *money.Money `json:"max_amount"`
+	VerifiedBy      *uint        `json:"verified_by"`
+	Limit           int          `json:"limit"`
+	Offset          int          `json:"offset"`
 }
 
 type transactionHistoryRepository struct {
@@ -302,11 +303,11 @@ func (r *transactionHistoryRepository) GetUserTransactions(userID uint, startDat
 
 func (r *transactionHistoryRepository) GetFinancialSummary(startDate, endDate time.Time) (map[string]interface{}, error) {
 	var summary struct {
-		TotalSimpanan     float64 `gorm:"column:total_simpanan"`
-		TotalPinjaman     float64 `gorm:"column:total_pinjaman"`
-		TotalAngsuran     float64 `gorm:"column:total_angsuran"`
-		TotalSHU          float64 `gorm:"column:total_shu"`
-		TotalTransactions int64   `gorm:"column:total_transactions"`
+		TotalSimpanan     money.Money `gorm:"column:total_simpanan"`
+		TotalPinjaman     money.Money `gorm:"column:total_pinjaman"`
+		TotalAngsuran     money.Money `gorm:"column:total_angsuran"`
+		TotalSHU          money.Money `gorm:"column:total_shu"`
+		TotalTransactions int64       `gorm:"column:total_transactions"`
 	}
 
 	err := r.db.Model(&model.TransactionHistory{}).
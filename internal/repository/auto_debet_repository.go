@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"koperasi-service/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AutoDebetRepository handles persistence for auto-debit mandates and attempts
+type AutoDebetRepository struct {
+	db *gorm.DB
+}
+
+// NewAutoDebetRepository constructs a new repository instance
+func NewAutoDebetRepository(db *gorm.DB) *AutoDebetRepository {
+	return &AutoDebetRepository{db: db}
+}
+
+// GetMandatByPinjaman returns the mandate of a loan, active or not
+func (r *AutoDebetRepository) GetMandatByPinjaman(pinjamanID uint) (*model.MandatAutoDebet, error) {
+	var m model.MandatAutoDebet
+	if err := r.db.Where("pinjaman_id = ?", pinjamanID).First(&m).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// GetMandatByPinjamanForUpdate returns the mandate of a loan and takes a row
+// lock on it. Must be called inside UnitOfWork.Do.
+func (r *AutoDebetRepository) GetMandatByPinjamanForUpdate(pinjamanID uint) (*model.MandatAutoDebet, error) {
+	var m model.MandatAutoDebet
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("pinjaman_id = ?", pinjamanID).First(&m).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// SaveMandat inserts or updates a mandate
+func (r *AutoDebetRepository) SaveMandat(m *model.MandatAutoDebet) error {
+	return r.db.Save(m).Error
+}
+
+// GetPinjamanIDsMandatAktif returns the loans with an active mandate that are
+// in one of the given statuses
+func (r *AutoDebetRepository) GetPinjamanIDsMandatAktif(statuses ...string) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&model.MandatAutoDebet{}).
+		Joins("JOIN pinjaman ON pinjaman.id = mandat_auto_debet.pinjaman_id AND pinjaman.deleted_at IS NULL").
+		Where("mandat_auto_debet.is_active = ? AND pinjaman.status IN ?", true, statuses).
+		Order("mandat_auto_debet.pinjaman_id").
+		Pluck("mandat_auto_debet.pinjaman_id", &ids).Error
+	return ids, err
+}
+
+// CreateAttempt records an auto-debit attempt
+func (r *AutoDebetRepository) CreateAttempt(a *model.AutoDebet) error {
+	return r.db.Create(a).Error
+}
+
+// GetAttemptedJadwalIDs returns which of the given schedule rows already had an attempt
+func (r *AutoDebetRepository) GetAttemptedJadwalIDs(jadwalIDs []uint) (map[uint]bool, error) {
+	done := make(map[uint]bool)
+	if len(jadwalIDs) == 0 {
+		return done, nil
+	}
+	var ids []uint
+	if err := r.db.Model(&model.AutoDebet{}).Where("jadwal_angsuran_id IN ?", jadwalIDs).Pluck("jadwal_angsuran_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		done[id] = true
+	}
+	return done, nil
+}
+
+// GetAttemptsByPinjaman returns the auto-debit attempts of a loan, newest first
+func (r *AutoDebetRepository) GetAttemptsByPinjaman(pinjamanID uint) ([]model.AutoDebet, error) {
+	var list []model.AutoDebet
+	if err := r.db.Where("pinjaman_id = ?", pinjamanID).Order("id DESC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"koperasi-service/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BerkasRepository handles persistence for uploaded files
+type BerkasRepository struct {
+	db *gorm.DB
+}
+
+// NewBerkasRepository constructs a new repository instance
+func NewBerkasRepository(db *gorm.DB) *BerkasRepository {
+	return &BerkasRepository{db: db}
+}
+
+// Create inserts an uploaded file
+func (r *BerkasRepository) Create(b *model.Berkas) error {
+	return r.db.Create(b).Error
+}
+
+// GetByID returns an uploaded file
+func (r *BerkasRepository) GetByID(id uint) (*model.Berkas, error) {
+	var b model.Berkas
+	if err := r.db.First(&b, id).Error; err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// GetByIDForUpdate returns an uploaded file and takes a row lock on it.
+// Must be called inside UnitOfWork.Do.
+func (r *BerkasRepository) GetByIDForUpdate(id uint) (*model.Berkas, error) {
+	var b model.Berkas
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&b, id).Error; err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Update persists changes to an uploaded file
+func (r *BerkasRepository) Update(b *model.Berkas) error {
+	return r.db.Save(b).Error
+}
+
+// Delete removes an uploaded file record
+func (r *BerkasRepository) Delete(id uint) error {
+	return r.db.Delete(&model.Berkas{}, id).Error
+}
+
+// ListByUploader returns the files a user uploaded, newest first. With
+// lepasOnly only files not attached to any record are returned.
+func (r *BerkasRepository) ListByUploader(userID uint, lepasOnly bool) ([]model.Berkas, error) {
+	var list []model.Berkas
+	q := r.db.Where("diunggah_oleh = ?", userID)
+	if lepasOnly {
+		q = q.Where("reference_id IS NULL")
+	}
+	if err := q.Order("id DESC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ListLepasBefore returns files uploaded before t that were never attached
+func (r *BerkasRepository) ListLepasBefore(t time.Time) ([]model.Berkas, error) {
+	var list []model.Berkas
+	if err := r.db.Where("reference_id IS NULL AND created_at < ?", t).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// LockHash takes a lock on a content hash that is held until the transaction
+// ends, so storing and removing the same content cannot interleave. Must be
+// called inside UnitOfWork.Do.
+func (r *BerkasRepository) LockHash(hash string) error {
+	return r.db.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", hash).Error
+}
+
+// CountByHash counts the file records that share content
+func (r *BerkasRepository) CountByHash(hash string) (int64, error) {
+	var n int64
+	err := r.db.Model(&model.Berkas{}).Where("hash = ?", hash).Count(&n).Error
+	return n, err
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"koperasi-service/internal/model"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -14,6 +15,12 @@ type BungaOptionRepository interface {
 	Update(id uint, bungaOption *model.BungaOption) error
 	Delete(id uint) error
 	SetActive(id uint, isActive bool) error
+	CreateWithVersi(bungaOption *model.BungaOption, versi *model.BungaOptionVersi) error
+	AddVersi(versi *model.BungaOptionVersi) error
+	GetVersiAt(bungaOptionID uint, at time.Time) (*model.BungaOptionVersi, error)
+	GetLatestVersi(bungaOptionID uint) (*model.BungaOptionVersi, error)
+	GetVersiHistory(bungaOptionID uint) ([]model.BungaOptionVersi, error)
+	BackfillVersi() (int, error)
 }
 
 type bungaOptionRepository struct {
@@ -60,3 +67,80 @@ func (r *bungaOptionRepository) Delete(id uint) error {
 func (r *bungaOptionRepository) SetActive(id uint, isActive bool) error {
 	return r.db.Model(&model.BungaOption{}).Where("id = ?", id).Update("is_active", isActive).Error
 }
+
+// CreateWithVersi inserts an option together with its first rate version
+func (r *bungaOptionRepository) CreateWithVersi(bungaOption *model.BungaOption, versi *model.BungaOptionVersi) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(bungaOption).Error; err != nil {
+			return err
+		}
+		versi.BungaOptionID = bungaOption.ID
+		return tx.Create(versi).Error
+	})
+}
+
+// AddVersi ends the versions still in effect at the new version's start and
+// inserts it
+func (r *bungaOptionRepository) AddVersi(versi *model.BungaOptionVersi) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.BungaOptionVersi{}).
+			Where("bunga_option_id = ? AND (effective_to IS NULL OR effective_to > ?)", versi.BungaOptionID, versi.EffectiveFrom).
+			Update("effective_to", versi.EffectiveFrom).Error; err != nil {
+			return err
+		}
+		return tx.Create(versi).Error
+	})
+}
+
+// GetVersiAt returns the version in effect at the given time
+func (r *bungaOptionRepository) GetVersiAt(bungaOptionID uint, at time.Time) (*model.BungaOptionVersi, error) {
+	var versi model.BungaOptionVersi
+	err := r.db.Where("bunga_option_id = ? AND effective_from <= ? AND (effective_to IS NULL OR effective_to > ?)", bungaOptionID, at, at).
+		Order("effective_from DESC").First(&versi).Error
+	if err != nil {
+		return nil, err
+	}
+	return &versi, nil
+}
+
+// GetLatestVersi returns the version with the highest number
+func (r *bungaOptionRepository) GetLatestVersi(bungaOptionID uint) (*model.BungaOptionVersi, error) {
+	var versi model.BungaOptionVersi
+	if err := r.db.Where("bunga_option_id = ?", bungaOptionID).Order("versi DESC").First(&versi).Error; err != nil {
+		return nil, err
+	}
+	return &versi, nil
+}
+
+// GetVersiHistory returns all versions of an option, oldest first
+func (r *bungaOptionRepository) GetVersiHistory(bungaOptionID uint) ([]model.BungaOptionVersi, error) {
+	var list []model.BungaOptionVersi
+	err := r.db.Where("bunga_option_id = ?", bungaOptionID).Order("versi").Find(&list).Error
+	return list, err
+}
+
+// BackfillVersi gives options created before versioning a first version,
+// effective from their creation, and returns how many were added
+func (r *bungaOptionRepository) BackfillVersi() (int, error) {
+	var options []model.BungaOption
+	err := r.db.Unscoped().
+		Where("NOT EXISTS (SELECT 1 FROM bunga_option_versi v WHERE v.bunga_option_id = bunga_options.id)").
+		Find(&options).Error
+	if err != nil {
+		return 0, err
+	}
+	for _, o := range options {
+		versi := &model.BungaOptionVersi{
+			BungaOptionID: o.ID,
+			Versi:         1,
+			Persen:        o.Persen,
+			MetodeBunga:   o.MetodeBunga,
+			EffectiveFrom: o.CreatedAt,
+			CreatedBy:     o.CreatedBy,
+		}
+		if err := r.db.Create(versi).Error; err != nil {
+			return 0, err
+		}
+	}
+	return len(options), nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"koperasi-service/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JadwalAngsuranRepository handles persistence for loan amortization schedules
+type JadwalAngsuranRepository struct {
+	db *gorm.DB
+}
+
+// NewJadwalAngsuranRepository constructs a new repository instance
+func NewJadwalAngsuranRepository(db *gorm.DB) *JadwalAngsuranRepository {
+	return &JadwalAngsuranRepository{db: db}
+}
+
+// CreateBatch inserts all rows of a schedule
+func (r *JadwalAngsuranRepository) CreateBatch(rows []model.JadwalAngsuran) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return r.db.Create(&rows).Error
+}
+
+// versiBerlaku restricts a query to the current schedule version of the loan
+const versiBerlaku = "pinjaman_id = ? AND versi = (SELECT versi_jadwal FROM pinjaman WHERE pinjaman.id = ?)"
+
+// GetByPinjaman returns the current schedule of a loan ordered by installment number
+func (r *JadwalAngsuranRepository) GetByPinjaman(pinjamanID uint) ([]model.JadwalAngsuran, error) {
+	var rows []model.JadwalAngsuran
+	if err := r.db.Where(versiBerlaku, pinjamanID, pinjamanID).Order("angsuran_ke").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// GetByPinjamanVersi returns one schedule version of a loan, including versions
+// replaced by a restructuring
+func (r *JadwalAngsuranRepository) GetByPinjamanVersi(pinjamanID uint, versi int) ([]model.JadwalAngsuran, error) {
+	var rows []model.JadwalAngsuran
+	if err := r.db.Where("pinjaman_id = ? AND versi = ?", pinjamanID, versi).Order("angsuran_ke").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// GetByPinjamanForUpdate returns the current schedule of a loan and locks its rows.
+// Must be called inside UnitOfWork.Do.
+func (r *JadwalAngsuranRepository) GetByPinjamanForUpdate(pinjamanID uint) ([]model.JadwalAngsuran, error) {
+	var rows []model.JadwalAngsuran
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where(versiBerlaku, pinjamanID, pinjamanID).Order("angsuran_ke").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Update persists changes to a schedule row
+func (r *JadwalAngsuranRepository) Update(row *model.JadwalAngsuran) error {
+	return r.db.Save(row).Error
+}
+
+// DeleteByPinjaman permanently removes the current schedule of a loan so it can
+// be regenerated. Versions replaced by a restructuring are kept.
+func (r *JadwalAngsuranRepository) DeleteByPinjaman(pinjamanID uint) error {
+	return r.db.Unscoped().Where(versiBerlaku, pinjamanID, pinjamanID).Delete(&model.JadwalAngsuran{}).Error
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"koperasi-service/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JasaSimpananRepository handles persistence for monthly jasa simpanan postings
+type JasaSimpananRepository struct {
+	db *gorm.DB
+}
+
+// NewJasaSimpananRepository constructs a new repository instance
+func NewJasaSimpananRepository(db *gorm.DB) *JasaSimpananRepository {
+	return &JasaSimpananRepository{db: db}
+}
+
+// Create inserts a posting
+func (r *JasaSimpananRepository) Create(j *model.JasaSimpanan) error {
+	return r.db.Create(j).Error
+}
+
+// ExistsForPeriode reports whether the wallet was already credited for the month
+func (r *JasaSimpananRepository) ExistsForPeriode(simpananID uint, periode time.Time) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.JasaSimpanan{}).Where("simpanan_id = ? AND periode = ?", simpananID, periode).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// List returns postings, newest month first. userID > 0 filters by member and
+// a non-nil periode by month.
+func (r *JasaSimpananRepository) List(userID uint, periode *time.Time) ([]model.JasaSimpanan, error) {
+	var list []model.JasaSimpanan
+	q := r.db
+	if userID > 0 {
+		q = q.Where("user_id = ?", userID)
+	}
+	if periode != nil {
+		q = q.Where("periode = ?", *periode)
+	}
+	if err := q.Order("periode DESC, id").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"koperasi-service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type JenisPinjamanRepository interface {
+	Create(jenis *model.JenisPinjaman) error
+	GetByID(id uint) (*model.JenisPinjaman, error)
+	GetAll() ([]model.JenisPinjaman, error)
+	GetActive() ([]model.JenisPinjaman, error)
+	Update(jenis *model.JenisPinjaman) error
+	Delete(id uint) error
+	SetActive(id uint, isActive bool) error
+}
+
+type jenisPinjamanRepository struct {
+	db *gorm.DB
+}
+
+func NewJenisPinjamanRepository(db *gorm.DB) JenisPinjamanRepository {
+	return &jenisPinjamanRepository{db: db}
+}
+
+func (r *jenisPinjamanRepository) Create(jenis *model.JenisPinjaman) error {
+	return r.db.Omit("CreatedByUser", "BungaOption", "AturanKelayakan").Create(jenis).Error
+}
+
+func (r *jenisPinjamanRepository) GetByID(id uint) (*model.JenisPinjaman, error) {
+	var jenis model.JenisPinjaman
+	err := r.db.Preload("CreatedByUser").Preload("BungaOption").Preload("AturanKelayakan").First(&jenis, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &jenis, nil
+}
+
+func (r *jenisPinjamanRepository) GetAll() ([]model.JenisPinjaman, error) {
+	var list []model.JenisPinjaman
+	err := r.db.Preload("BungaOption").Preload("AturanKelayakan").Order("id").Find(&list).Error
+	return list, err
+}
+
+func (r *jenisPinjamanRepository) GetActive() ([]model.JenisPinjaman, error) {
+	var list []model.JenisPinjaman
+	err := r.db.Where("is_active = ?", true).Preload("BungaOption").Preload("AturanKelayakan").Order("id").Find(&list).Error
+	return list, err
+}
+
+func (r *jenisPinjamanRepository) Update(jenis *model.JenisPinjaman) error {
+	return r.db.Omit("CreatedByUser", "BungaOption", "AturanKelayakan").Save(jenis).Error
+}
+
+func (r *jenisPinjamanRepository) Delete(id uint) error {
+	return r.db.Delete(&model.JenisPinjaman{}, id).Error
+}
+
+func (r *jenisPinjamanRepository) SetActive(id uint, isActive bool) error {
+	res := r.db.Model(&model.JenisPinjaman{}).Where("id = ?", id).Update("is_active", isActive)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"koperasi-service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type JenisSimpananBerjangkaRepository interface {
+	Create(jenis *model.JenisSimpananBerjangka) error
+	GetByID(id uint) (*model.JenisSimpananBerjangka, error)
+	GetAll() ([]model.JenisSimpananBerjangka, error)
+	GetActive() ([]model.JenisSimpananBerjangka, error)
+	Update(jenis *model.JenisSimpananBerjangka) error
+	Delete(id uint) error
+	SetActive(id uint, isActive bool) error
+}
+
+type jenisSimpananBerjangkaRepository struct {
+	db *gorm.DB
+}
+
+func NewJenisSimpananBerjangkaRepository(db *gorm.DB) JenisSimpananBerjangkaRepository {
+	return &jenisSimpananBerjangkaRepository{db: db}
+}
+
+func (r *jenisSimpananBerjangkaRepository) Create(jenis *model.JenisSimpananBerjangka) error {
+	return r.db.Omit("CreatedByUser").Create(jenis).Error
+}
+
+func (r *jenisSimpananBerjangkaRepository) GetByID(id uint) (*model.JenisSimpananBerjangka, error) {
+	var jenis model.JenisSimpananBerjangka
+	err := r.db.Preload("CreatedByUser").First(&jenis, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &jenis, nil
+}
+
+func (r *jenisSimpananBerjangkaRepository) GetAll() ([]model.JenisSimpananBerjangka, error) {
+	var list []model.JenisSimpananBerjangka
+	err := r.db.Order("tenor_bulan, id").Find(&list).Error
+	return list, err
+}
+
+func (r *jenisSimpananBerjangkaRepository) GetActive() ([]model.JenisSimpananBerjangka, error) {
+	var list []model.JenisSimpananBerjangka
+	err := r.db.Where("is_active = ?", true).Order("tenor_bulan, id").Find(&list).Error
+	return list, err
+}
+
+func (r *jenisSimpananBerjangkaRepository) Update(jenis *model.JenisSimpananBerjangka) error {
+	return r.db.Omit("CreatedByUser").Save(jenis).Error
+}
+
+func (r *jenisSimpananBerjangkaRepository) Delete(id uint) error {
+	return r.db.Delete(&model.JenisSimpananBerjangka{}, id).Error
+}
+
+func (r *jenisSimpananBerjangkaRepository) SetActive(id uint, isActive bool) error {
+	res := r.db.Model(&model.JenisSimpananBerjangka{}).Where("id = ?", id).Update("is_active", isActive)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
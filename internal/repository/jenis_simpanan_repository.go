@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"koperasi-service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type JenisSimpananRepository interface {
+	Create(jenis *model.JenisSimpanan) error
+	GetByID(id uint) (*model.JenisSimpanan, error)
+	GetByKode(kode string) (*model.JenisSimpanan, error)
+	GetAll() ([]model.JenisSimpanan, error)
+	GetActive() ([]model.JenisSimpanan, error)
+	Update(jenis *model.JenisSimpanan) error
+	Delete(id uint) error
+	SetActive(id uint, isActive bool) error
+	SeedBawaan() error
+}
+
+type jenisSimpananRepository struct {
+	db *gorm.DB
+}
+
+func NewJenisSimpananRepository(db *gorm.DB) JenisSimpananRepository {
+	return &jenisSimpananRepository{db: db}
+}
+
+func (r *jenisSimpananRepository) Create(jenis *model.JenisSimpanan) error {
+	return r.db.Create(jenis).Error
+}
+
+func (r *jenisSimpananRepository) GetByID(id uint) (*model.JenisSimpanan, error) {
+	var jenis model.JenisSimpanan
+	err := r.db.First(&jenis, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &jenis, nil
+}
+
+func (r *jenisSimpananRepository) GetByKode(kode string) (*model.JenisSimpanan, error) {
+	var jenis model.JenisSimpanan
+	err := r.db.Where("kode = ?", kode).First(&jenis).Error
+	if err != nil {
+		return nil, err
+	}
+	return &jenis, nil
+}
+
+func (r *jenisSimpananRepository) GetAll() ([]model.JenisSimpanan, error) {
+	var list []model.JenisSimpanan
+	err := r.db.Order("id").Find(&list).Error
+	return list, err
+}
+
+func (r *jenisSimpananRepository) GetActive() ([]model.JenisSimpanan, error) {
+	var list []model.JenisSimpanan
+	err := r.db.Where("is_active = ?", true).Order("id").Find(&list).Error
+	return list, err
+}
+
+func (r *jenisSimpananRepository) Update(jenis *model.JenisSimpanan) error {
+	return r.db.Save(jenis).Error
+}
+
+func (r *jenisSimpananRepository) Delete(id uint) error {
+	return r.db.Delete(&model.JenisSimpanan{}, id).Error
+}
+
+func (r *jenisSimpananRepository) SetActive(id uint, isActive bool) error {
+	res := r.db.Model(&model.JenisSimpanan{}).Where("id = ?", id).Update("is_active", isActive)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SeedBawaan creates the built-in wallet types that do not exist yet
+func (r *jenisSimpananRepository) SeedBawaan() error {
+	for _, jenis := range model.JenisSimpananBawaan {
+		if err := r.db.Where(model.JenisSimpanan{Kode: jenis.Kode}).FirstOrCreate(&jenis).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"koperasi-service/internal/model"
+	"koperasi-service/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TunggakanWajibRow is the overdue simpanan wajib of one member
+type TunggakanWajibRow struct {
+	UserID         uint        `json:"user_id"`
+	Nama           string      `json:"nama"`
+	Email          string      `json:"email"`
+	JumlahBulan    int         `json:"jumlah_bulan"`
+	TotalTunggakan money.Money `json:"total_tunggakan"`
+}
+
+// KewajibanWajibRepository handles persistence for monthly simpanan wajib obligations
+type KewajibanWajibRepository struct {
+	db *gorm.DB
+}
+
+// NewKewajibanWajibRepository constructs a new repository instance
+func NewKewajibanWajibRepository(db *gorm.DB) *KewajibanWajibRepository {
+	return &KewajibanWajibRepository{db: db}
+}
+
+// Create inserts an obligation
+func (r *KewajibanWajibRepository) Create(k *model.KewajibanWajib) error {
+	return r.db.Create(k).Error
+}
+
+// Update persists changes to an obligation
+func (r *KewajibanWajibRepository) Update(k *model.KewajibanWajib) error {
+	return r.db.Save(k).Error
+}
+
+// ExistsForPeriode reports whether the member already has an obligation for the month
+func (r *KewajibanWajibRepository) ExistsForPeriode(userID uint, periode time.Time) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.KewajibanWajib{}).Where("user_id = ? AND periode = ?", userID, periode).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetByUser returns the obligations of a member, oldest month first
+func (r *KewajibanWajibRepository) GetByUser(userID uint) ([]model.KewajibanWajib, error) {
+	var list []model.KewajibanWajib
+	if err := r.db.Where("user_id = ?", userID).Order("periode").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetOpenByUser returns the obligations of a member not yet paid in full,
+// oldest month first
+func (r *KewajibanWajibRepository) GetOpenByUser(userID uint) ([]model.KewajibanWajib, error) {
+	var list []model.KewajibanWajib
+	if err := r.db.Where("user_id = ? AND status <> ?", userID, model.KewajibanWajibLunas).Order("periode").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetTunggakanByUser returns the obligations of a member that are overdue on
+// tanggal, oldest month first
+func (r *KewajibanWajibRepository) GetTunggakanByUser(userID uint, tanggal time.Time) ([]model.KewajibanWajib, error) {
+	var list []model.KewajibanWajib
+	if err := r.db.Where("user_id = ? AND status <> ? AND tanggal_jatuh_tempo < ?", userID, model.KewajibanWajibLunas, tanggal).
+		Order("periode").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetRekapTunggakan returns the overdue obligations on tanggal grouped per member
+func (r *KewajibanWajibRepository) GetRekapTunggakan(tanggal time.Time) ([]TunggakanWajibRow, error) {
+	var rows []TunggakanWajibRow
+	err := r.db.Model(&model.KewajibanWajib{}).
+		Select("kewajiban_simpanan_wajib.user_id, users.name AS nama, users.email, COUNT(*) AS jumlah_bulan, COALESCE(SUM(kewajiban_simpanan_wajib.nominal - kewajiban_simpanan_wajib.terbayar), 0) AS total_tunggakan").
+		Joins("JOIN users ON users.id = kewajiban_simpanan_wajib.user_id").
+		Where("kewajiban_simpanan_wajib.status <> ? AND kewajiban_simpanan_wajib.tanggal_jatuh_tempo < ?", model.KewajibanWajibLunas, tanggal).
+		Group("kewajiban_simpanan_wajib.user_id, users.name, users.email").
+		Order("kewajiban_simpanan_wajib.user_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// GetTotalTerbayar returns what has been matched against the member's obligations
+func (r *KewajibanWajibRepository) GetTotalTerbayar(userID uint) (money.Money, error) {
+	var total money.Money
+	err := r.db.Model(&model.KewajibanWajib{}).
+		Select("COALESCE(SUM(terbayar), 0)").
+		Where("user_id = ?", userID).
+		Scan(&total).Error
+	return total, err
+}
+
+// GetTotalSetoran returns the verified top-ups of a wajib wallet made on or
+// after since, the money available to pay obligations. Reversed top-ups are
+// left out.
+func (r *KewajibanWajibRepository) GetTotalSetoran(simpananID uint, since time.Time) (money.Money, error) {
+	var total money.Money
+	err := r.db.Model(&model.SimpananTransaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("simpanan_id = ? AND type = ? AND status = ? AND created_at >= ?", simpananID, "topup", "verified", since).
+		Where("(ledger_journal_id IS NULL OR ledger_journal_id NOT IN (?))", r.db.Model(&model.LedgerJournal{}).Select("reversal_of_id").Where("reversal_of_id IS NOT NULL")).
+		Scan(&total).Error
+	return total, err
+}
+
+// DeleteOpenByUser removes the obligations of a member not yet paid in full
+func (r *KewajibanWajibRepository) DeleteOpenByUser(userID uint) error {
+	return r.db.Where("user_id = ? AND status <> ?", userID, model.KewajibanWajibLunas).Delete(&model.KewajibanWajib{}).Error
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"koperasi-service/internal/model"
+	"koperasi-service/pkg/money"
+
+	"gorm.io/gorm"
+)
+
+// KolektibilitasRepository handles collectibility history and aging queries
+type KolektibilitasRepository struct {
+	db *gorm.DB
+}
+
+// NewKolektibilitasRepository constructs a new repository instance
+func NewKolektibilitasRepository(db *gorm.DB) *KolektibilitasRepository {
+	return &KolektibilitasRepository{db: db}
+}
+
+// AgingBucket is the number of active loans and their outstanding principal in one bucket
+type AgingBucket struct {
+	Kolektibilitas int         `json:"kolektibilitas"`
+	JumlahPinjaman int64       `json:"jumlah_pinjaman"`
+	SisaPokok      money.Money `json:"sisa_pokok"`
+}
+
+// CreateRiwayat stores a classification change
+func (r *KolektibilitasRepository) CreateRiwayat(riwayat *model.RiwayatKolektibilitas) error {
+	return r.db.Create(riwayat).Error
+}
+
+// GetRiwayatByPinjaman returns the classification changes of a loan, newest first
+func (r *KolektibilitasRepository) GetRiwayatByPinjaman(pinjamanID uint) ([]model.RiwayatKolektibilitas, error) {
+	var list []model.RiwayatKolektibilitas
+	if err := r.db.Where("pinjaman_id = ?", pinjamanID).Order("tanggal DESC, id DESC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetAging groups disbursed and written-off loans by bucket with their
+// outstanding principal from the schedule. If adminID > 0 only loans of
+// members registered by that admin are counted.
+func (r *KolektibilitasRepository) GetAging(adminID uint) ([]AgingBucket, error) {
+	var rows []AgingBucket
+	q := r.db.Table("pinjaman").
+		Select("pinjaman.kolektibilitas, COUNT(DISTINCT pinjaman.id) AS jumlah_pinjaman, COALESCE(SUM(jadwal_angsuran.pokok - jadwal_angsuran.pokok_dibayar), 0) AS sisa_pokok").
+		Joins("LEFT JOIN jadwal_angsuran ON jadwal_angsuran.pinjaman_id = pinjaman.id AND jadwal_angsuran.versi = pinjaman.versi_jadwal AND jadwal_angsuran.deleted_at IS NULL").
+		Where("pinjaman.deleted_at IS NULL AND pinjaman.status IN ?", []string{model.StatusPinjamanDicairkan, model.StatusPinjamanMacet})
+	if adminID > 0 {
+		q = q.Joins("JOIN users ON pinjaman.user_id = users.id").Where("users.admin_id = ?", adminID)
+	}
+	if err := q.Group("pinjaman.kolektibilitas").Order("pinjaman.kolektibilitas").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"errors"
+	"koperasi-service/internal/model"
+	"koperasi-service/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LedgerRepository handles persistence for the append-only simpanan ledger.
+// It only ever inserts; posted journals are corrected with reversals.
+type LedgerRepository struct {
+	db *gorm.DB
+}
+
+// NewLedgerRepository constructs a new repository instance.
+func NewLedgerRepository(db *gorm.DB) *LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+// PostJournal inserts a journal with its entries. The entries must balance
+// (total debit == total credit) and carry a non-zero amount.
+func (r *LedgerRepository) PostJournal(j *model.LedgerJournal) error {
+	var debit, credit money.Money
+	for _, e := range j.Entries {
+		if e.Debit < 0 || e.Credit < 0 {
+			return errors.New("ledger amounts must not be negative")
+		}
+		debit += e.Debit
+		credit += e.Credit
+	}
+	if debit == 0 || debit != credit {
+		return errors.New("unbalanced ledger journal")
+	}
+	if j.PostedAt.IsZero() {
+		j.PostedAt = time.Now()
+	}
+
+	entries := j.Entries
+	if err := r.db.Omit("Entries").Create(j).Error; err != nil {
+		return err
+	}
+	for i := range entries {
+		entries[i].JournalID = j.ID
+		entries[i].PostedAt = j.PostedAt
+	}
+	if err := r.db.Create(&entries).Error; err != nil {
+		return err
+	}
+	j.Entries = entries
+	return nil
+}
+
+// GetJournalByID returns a journal with its entries.
+func (r *LedgerRepository) GetJournalByID(id uint) (*model.LedgerJournal, error) {
+	var j model.LedgerJournal
+	if err := r.db.Preload("Entries").First(&j, id).Error; err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// IsReversed reports whether a reversal has already been posted for the journal.
+func (r *LedgerRepository) IsReversed(journalID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.LedgerJournal{}).
+		Where("reversal_of_id = ?", journalID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetWalletBalance returns the balance of a wallet derived from its ledger
+// entries. If asOf is non-nil only entries posted at or before it are counted.
+func (r *LedgerRepository) GetWalletBalance(simpananID uint, asOf *time.Time) (money.Money, error) {
+	var total money.Money
+	q := r.db.Model(&model.LedgerEntry{}).
+		Select("COALESCE(SUM(credit - debit), 0)").
+		Where("account = ? AND simpanan_id = ?", model.AkunSimpananAnggota, simpananID)
+	if asOf != nil {
+		q = q.Where("posted_at <= ?", *asOf)
+	}
+	err := q.Scan(&total).Error
+	return total, err
+}
+
+// GetWalletBalanceBefore returns the ledger balance of a wallet from the
+// entries posted strictly before t.
+func (r *LedgerRepository) GetWalletBalanceBefore(simpananID uint, t time.Time) (money.Money, error) {
+	var total money.Money
+	err := r.db.Model(&model.LedgerEntry{}).
+		Select("COALESCE(SUM(credit - debit), 0)").
+		Where("account = ? AND simpanan_id = ? AND posted_at < ?", model.AkunSimpananAnggota, simpananID, t).
+		Scan(&total).Error
+	return total, err
+}
+
+// GetWalletEntriesBetween returns the entries of a wallet posted in [from, to)
+// in posting order.
+func (r *LedgerRepository) GetWalletEntriesBetween(simpananID uint, from, to time.Time) ([]model.LedgerEntry, error) {
+	var entries []model.LedgerEntry
+	err := r.db.Where("account = ? AND simpanan_id = ? AND posted_at >= ? AND posted_at < ?", model.AkunSimpananAnggota, simpananID, from, to).
+		Order("posted_at, id").
+		Find(&entries).Error
+	return entries, err
+}
+
+// GetEntriesByWallet returns all ledger entries of a wallet in posting order.
+func (r *LedgerRepository) GetEntriesByWallet(simpananID uint) ([]model.LedgerEntry, error) {
+	var entries []model.LedgerEntry
+	err := r.db.Where("account = ? AND simpanan_id = ?", model.AkunSimpananAnggota, simpananID).
+		Order("posted_at, id").
+		Find(&entries).Error
+	return entries, err
+}
+
+// HasWalletEntries reports whether a wallet has any ledger entries
+func (r *LedgerRepository) HasWalletEntries(simpananID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.LedgerEntry{}).
+		Where("account = ? AND simpanan_id = ?", model.AkunSimpananAnggota, simpananID).
+		Limit(1).Count(&count).Error
+	return count > 0, err
+}
+
+// GetWalletsWithoutEntries returns wallets with a non-zero cached balance but
+// no ledger entries yet (balances that predate the ledger).
+func (r *LedgerRepository) GetWalletsWithoutEntries() ([]model.Simpanan, error) {
+	var wallets []model.Simpanan
+	err := r.db.Where("balance <> 0").
+		Where("NOT EXISTS (SELECT 1 FROM ledger_entries e WHERE e.account = ? AND e.simpanan_id = simpanans.id)", model.AkunSimpananAnggota).
+		Find(&wallets).Error
+	return wallets, err
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"koperasi-service/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotifikasiRepository handles persistence for member notifications
+type NotifikasiRepository struct {
+	db *gorm.DB
+}
+
+// NewNotifikasiRepository constructs a new repository instance
+func NewNotifikasiRepository(db *gorm.DB) *NotifikasiRepository {
+	return &NotifikasiRepository{db: db}
+}
+
+// Create inserts a notification
+func (r *NotifikasiRepository) Create(n *model.Notifikasi) error {
+	return r.db.Create(n).Error
+}
+
+// GetByUser returns the notifications of a user, newest first. With unreadOnly
+// only notifications not yet read are returned.
+func (r *NotifikasiRepository) GetByUser(userID uint, unreadOnly bool) ([]model.Notifikasi, error) {
+	var list []model.Notifikasi
+	q := r.db.Where("user_id = ?", userID)
+	if unreadOnly {
+		q = q.Where("dibaca_pada IS NULL")
+	}
+	if err := q.Order("id DESC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// MarkRead marks a notification of a user as read. It returns
+// gorm.ErrRecordNotFound when the user has no such notification.
+func (r *NotifikasiRepository) MarkRead(id, userID uint, at time.Time) error {
+	res := r.db.Model(&model.Notifikasi{}).
+		Where("id = ? AND user_id = ? AND dibaca_pada IS NULL", id, userID).
+		Update("dibaca_pada", at)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		var count int64
+		if err := r.db.Model(&model.Notifikasi{}).Where("id = ? AND user_id = ?", id, userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return gorm.ErrRecordNotFound
+		}
+	}
+	return nil
+}
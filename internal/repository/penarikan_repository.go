@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"koperasi-service/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PenarikanRepository handles persistence for simpanan withdrawal requests
+type PenarikanRepository struct {
+	db *gorm.DB
+}
+
+// NewPenarikanRepository constructs a new repository instance
+func NewPenarikanRepository(db *gorm.DB) *PenarikanRepository {
+	return &PenarikanRepository{db: db}
+}
+
+// Create inserts a withdrawal request
+func (r *PenarikanRepository) Create(p *model.PenarikanSimpanan) error {
+	return r.db.Create(p).Error
+}
+
+// GetByID returns a withdrawal request with its member
+func (r *PenarikanRepository) GetByID(id uint) (*model.PenarikanSimpanan, error) {
+	var p model.PenarikanSimpanan
+	if err := r.db.Preload("User").First(&p, id).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetByIDForUpdate returns a withdrawal request and takes a row lock on it.
+// Must be called inside UnitOfWork.Do.
+func (r *PenarikanRepository) GetByIDForUpdate(id uint) (*model.PenarikanSimpanan, error) {
+	var p model.PenarikanSimpanan
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&p, id).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Update persists changes to a withdrawal request
+func (r *PenarikanRepository) Update(p *model.PenarikanSimpanan) error {
+	return r.db.Omit("User").Save(p).Error
+}
+
+// List returns withdrawal requests, newest first. A zero userID or an empty
+// status matches every request.
+func (r *PenarikanRepository) List(userID uint, status string) ([]model.PenarikanSimpanan, error) {
+	var list []model.PenarikanSimpanan
+	q := r.db.Preload("User")
+	if userID > 0 {
+		q = q.Where("user_id = ?", userID)
+	}
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if err := q.Order("id DESC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"koperasi-service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// PencairanRepository handles persistence for loan disbursements
+type PencairanRepository struct {
+	db *gorm.DB
+}
+
+// NewPencairanRepository constructs a new repository instance
+func NewPencairanRepository(db *gorm.DB) *PencairanRepository {
+	return &PencairanRepository{db: db}
+}
+
+// Create inserts a disbursement record
+func (r *PencairanRepository) Create(pc *model.Pencairan) error {
+	return r.db.Create(pc).Error
+}
+
+// GetByPinjaman returns the disbursement of a loan
+func (r *PencairanRepository) GetByPinjaman(pinjamanID uint) (*model.Pencairan, error) {
+	var pc model.Pencairan
+	if err := r.db.Where("pinjaman_id = ?", pinjamanID).First(&pc).Error; err != nil {
+		return nil, err
+	}
+	return &pc, nil
+}
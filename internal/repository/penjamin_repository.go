@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"koperasi-service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// PenjaminRepository handles persistence for loan guarantors
+type PenjaminRepository struct {
+	db *gorm.DB
+}
+
+// NewPenjaminRepository constructs a new repository instance
+func NewPenjaminRepository(db *gorm.DB) *PenjaminRepository {
+	return &PenjaminRepository{db: db}
+}
+
+// Create inserts a guarantor link
+func (r *PenjaminRepository) Create(pj *model.Penjamin) error {
+	return r.db.Create(pj).Error
+}
+
+// GetByID returns a guarantor link
+func (r *PenjaminRepository) GetByID(id uint) (*model.Penjamin, error) {
+	var pj model.Penjamin
+	if err := r.db.First(&pj, id).Error; err != nil {
+		return nil, err
+	}
+	return &pj, nil
+}
+
+// GetByPinjaman returns the guarantors of a loan with their user
+func (r *PenjaminRepository) GetByPinjaman(pinjamanID uint) ([]model.Penjamin, error) {
+	var list []model.Penjamin
+	if err := r.db.Preload("User").Where("pinjaman_id = ?", pinjamanID).Order("id").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetPinjamanDijamin returns the loans a member guarantees that are in one of
+// the given statuses
+func (r *PenjaminRepository) GetPinjamanDijamin(userID uint, statuses ...string) ([]model.Pinjaman, error) {
+	var list []model.Pinjaman
+	err := r.db.Joins("JOIN penjamin ON penjamin.pinjaman_id = pinjaman.id AND penjamin.deleted_at IS NULL").
+		Where("penjamin.user_id = ? AND pinjaman.status IN ?", userID, statuses).
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Delete removes a guarantor link. The row is deleted for good so the member
+// can be added again.
+func (r *PenjaminRepository) Delete(id uint) error {
+	return r.db.Unscoped().Delete(&model.Penjamin{}, id).Error
+}
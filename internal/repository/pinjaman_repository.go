@@ -4,6 +4,7 @@ import (
 	"koperasi-service/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // PinjamanRepository handles persistence for Pinjaman entities
@@ -43,6 +44,16 @@ func (r *PinjamanRepository) GetByID(id uint) (*model.Pinjaman, error) {
 	return &p, nil
 }
 
+// GetByIDForUpdate returns a pinjaman and takes a row lock on it.
+// Must be called inside UnitOfWork.Do.
+func (r *PinjamanRepository) GetByIDForUpdate(id uint) (*model.Pinjaman, error) {
+	var p model.Pinjaman
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&p, id).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
 // Update persists changes to an existing Pinjaman
 func (r *PinjamanRepository) Update(p *model.Pinjaman) error {
 	return r.db.Save(p).Error
@@ -53,6 +64,24 @@ func (r *PinjamanRepository) Delete(id uint) error {
 	return r.db.Delete(&model.Pinjaman{}, id).Error
 }
 
+// GetIDsByStatus returns the ids of loans in any of the given statuses
+func (r *PinjamanRepository) GetIDsByStatus(statuses ...string) ([]uint, error) {
+	var ids []uint
+	if err := r.db.Model(&model.Pinjaman{}).Where("status IN ?", statuses).Order("id").Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// GetByUserAndStatus returns the loans of a user in any of the given statuses
+func (r *PinjamanRepository) GetByUserAndStatus(userID uint, statuses ...string) ([]model.Pinjaman, error) {
+	var list []model.Pinjaman
+	if err := r.db.Where("user_id = ? AND status IN ?", userID, statuses).Order("id").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
 // GetByKodePinjaman finds pinjaman by kode_pinjaman
 func (r *PinjamanRepository) GetByKodePinjaman(kode string) (*model.Pinjaman, error) {
 	var p model.Pinjaman
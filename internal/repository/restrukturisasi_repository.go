@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"koperasi-service/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RestrukturisasiRepository handles persistence for loan restructuring requests
+type RestrukturisasiRepository struct {
+	db *gorm.DB
+}
+
+// NewRestrukturisasiRepository constructs a new repository instance
+func NewRestrukturisasiRepository(db *gorm.DB) *RestrukturisasiRepository {
+	return &RestrukturisasiRepository{db: db}
+}
+
+// Create inserts a new restructuring request
+func (r *RestrukturisasiRepository) Create(rs *model.Restrukturisasi) error {
+	return r.db.Create(rs).Error
+}
+
+// GetByIDForUpdate returns a restructuring request and takes a row lock on it.
+// Must be called inside UnitOfWork.Do.
+func (r *RestrukturisasiRepository) GetByIDForUpdate(id uint) (*model.Restrukturisasi, error) {
+	var rs model.Restrukturisasi
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&rs, id).Error; err != nil {
+		return nil, err
+	}
+	return &rs, nil
+}
+
+// GetByPinjaman returns the restructuring requests of a loan, oldest first
+func (r *RestrukturisasiRepository) GetByPinjaman(pinjamanID uint) ([]model.Restrukturisasi, error) {
+	var list []model.Restrukturisasi
+	if err := r.db.Where("pinjaman_id = ?", pinjamanID).Order("id").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// CountByPinjamanAndStatus counts the requests of a loan in a status
+func (r *RestrukturisasiRepository) CountByPinjamanAndStatus(pinjamanID uint, status string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Restrukturisasi{}).Where("pinjaman_id = ? AND status = ?", pinjamanID, status).Count(&count).Error
+	return count, err
+}
+
+// GetByStatus returns requests in a status with their loan. If adminID > 0
+// only loans of members registered by that admin are returned.
+func (r *RestrukturisasiRepository) GetByStatus(status string, adminID uint) ([]model.Restrukturisasi, error) {
+	var list []model.Restrukturisasi
+	q := r.db.Preload("Pinjaman").Preload("Pinjaman.User").Where("restrukturisasi.status = ?", status)
+	if adminID > 0 {
+		q = q.Joins("JOIN pinjaman ON pinjaman.id = restrukturisasi.pinjaman_id").
+			Joins("JOIN users ON users.id = pinjaman.user_id").
+			Where("users.admin_id = ?", adminID)
+	}
+	if err := q.Order("restrukturisasi.id").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Update persists changes to a restructuring request
+func (r *RestrukturisasiRepository) Update(rs *model.Restrukturisasi) error {
+	return r.db.Save(rs).Error
+}
@@ -2,8 +2,10 @@ package repository
 
 import (
 	"koperasi-service/internal/model"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // SHUAnggotaRepository handles database operations for SHU Anggota records
@@ -28,6 +30,22 @@ func (r *SHUAnggotaRepository) GetByID(id uint) (*model.SHUAnggotaRecord, error)
 	return &shuAnggota, err
 }
 
+// GetByIDForUpdate retrieves a SHU Anggota record and takes a row lock on it.
+// Must be called inside UnitOfWork.Do.
+func (r *SHUAnggotaRepository) GetByIDForUpdate(id uint) (*model.SHUAnggotaRecord, error) {
+	var shuAnggota model.SHUAnggotaRecord
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("SHU").First(&shuAnggota, id).Error; err != nil {
+		return nil, err
+	}
+	return &shuAnggota, nil
+}
+
+// SetDikreditkan records that a SHU Anggota record was credited to a wallet
+func (r *SHUAnggotaRepository) SetDikreditkan(id uint, transactionID uint, at time.Time) error {
+	return r.db.Model(&model.SHUAnggotaRecord{}).Where("id_shu_anggota = ?", id).
+		Updates(map[string]interface{}{"simpanan_transaction_id": transactionID, "dikreditkan_pada": at}).Error
+}
+
 // GetBySHUIDAndUserID retrieves a SHU Anggota record by SHU ID and User ID
 func (r *SHUAnggotaRepository) GetBySHUIDAndUserID(shuID, userID uint) (*model.SHUAnggotaRecord, error) {
 	var shuAnggota model.SHUAnggotaRecord
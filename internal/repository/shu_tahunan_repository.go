@@ -2,6 +2,8 @@ package repository
 
 import (
 	"koperasi-service/internal/model"
+	"koperasi-service/pkg/money"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -59,8 +61,8 @@ func (r *SHUTahunanRepository) Delete(id uint) error {
 }
 
 // GetTotalSimpananByYear calculates total simpanan for a specific year
-func (r *SHUTahunanRepository) GetTotalSimpananByYear(tahun int) (float64, error) {
-	var total float64
+func (r *SHUTahunanRepository) GetTotalSimpananByYear(tahun int) (money.Money, error) {
+	var total money.Money
 	err := r.db.Model(&model.Simpanan{}).
 		Select("COALESCE(SUM(amount), 0)").
 		Where("EXTRACT(YEAR FROM created_at) = ?", tahun).
@@ -69,10 +71,10 @@ func (r *SHUTahunanRepository) GetTotalSimpananByYear(tahun int) (float64, error
 }
 
 // GetSimpananByUserAndYear calculates simpanan per user for a specific year
-func (r *SHUTahunanRepository) GetSimpananByUserAndYear(tahun int) (map[uint]float64, error) {
+func (r *SHUTahunanRepository) GetSimpananByUserAndYear(tahun int) (map[uint]money.Money, error) {
 	type UserSimpanan struct {
-		UserID uint    `json:"user_id"`
-		Total  float64 `json:"total"`
+		UserID uint        `json:"user_id"`
+		Total  money.Money `json:"total"`
 	}
 
 	var results []UserSimpanan
@@ -86,7 +88,7 @@ func (r *SHUTahunanRepository) GetSimpananByUserAndYear(tahun int) (map[uint]flo
 		return nil, err
 	}
 
-	userSimpanan := make(map[uint]float64)
+	userSimpanan := make(map[uint]money.Money)
 	for _, result := range results {
 		userSimpanan[result.UserID] = result.Total
 	}
@@ -96,8 +98,8 @@ func (r *SHUTahunanRepository) GetSimpananByUserAndYear(tahun int) (map[uint]flo
 
 // GetTotalPenjualanByYear calculates total penjualan (loans) for a specific year
 // Note: Using pinjaman as proxy for "penjualan" since it's the main transaction volume
-func (r *SHUTahunanRepository) GetTotalPenjualanByYear(tahun int) (float64, error) {
-	var total float64
+func (r *SHUTahunanRepository) GetTotalPenjualanByYear(tahun int) (money.Money, error) {
+	var total money.Money
 	err := r.db.Model(&model.Pinjaman{}).
 		Select("COALESCE(SUM(jumlah_pinjaman), 0)").
 		Where("EXTRACT(YEAR FROM created_at) = ?", tahun).
@@ -106,10 +108,10 @@ func (r *SHUTahunanRepository) GetTotalPenjualanByYear(tahun int) (float64, erro
 }
 
 // GetPenjualanByUserAndYear calculates penjualan per user for a specific year
-func (r *SHUTahunanRepository) GetPenjualanByUserAndYear(tahun int) (map[uint]float64, error) {
+func (r *SHUTahunanRepository) GetPenjualanByUserAndYear(tahun int) (map[uint]money.Money, error) {
 	type UserPenjualan struct {
-		UserID uint    `json:"user_id"`
-		Total  float64 `json:"total"`
+		UserID uint        `json:"user_id"`
+		Total  money.Money `json:"total"`
 	}
 
 	var results []UserPenjualan
@@ -123,7 +125,7 @@ func (r *SHUTahunanRepository) GetPenjualanByUserAndYear(tahun int) (map[uint]fl
 		return nil, err
 	}
 
-	userPenjualan := make(map[uint]float64)
+	userPenjualan := make(map[uint]money.Money)
 	for _, result := range results {
 		userPenjualan[result.UserID] = result.Total
 	}
@@ -133,30 +135,66 @@ func (r *SHUTahunanRepository) GetPenjualanByUserAndYear(tahun int) (map[uint]fl
 
 // GetPendapatanOperasionalByYear calculates operational income for a specific year
 // This includes income from loans (bunga), fees, and other operational activities
-func (r *SHUTahunanRepository) GetPendapatanOperasionalByYear(tahun int) (float64, error) {
-	var total float64
+func (r *SHUTahunanRepository) GetPendapatanOperasionalByYear(tahun int) (money.Money, error) {
+	var total money.Money
 
-	// Calculate from loan interest (bunga from angsuran that are verified)
+	// Calculate from loan interest, late-payment denda and early-settlement
+	// fees allocated from verified angsuran (including short and over payments)
 	err := r.db.Model(&model.Angsuran{}).
-		Select("COALESCE(SUM(bunga), 0)").
-		Where("EXTRACT(YEAR FROM created_at) = ? AND status = ?", tahun, "verified").
+		Select("COALESCE(SUM(bunga + denda + biaya), 0)").
+		Where("EXTRACT(YEAR FROM created_at) = ? AND status IN ?", tahun, []string{"verified", "kurang", "lebih"}).
 		Scan(&total).Error
 
 	if err != nil {
 		return 0, err
 	}
 
-	return total, nil
+	// Admin and provision fees deducted at disbursement
+	var biaya money.Money
+	err = r.db.Model(&model.Pencairan{}).
+		Select("COALESCE(SUM(biaya_admin + biaya_provisi), 0)").
+		Where("EXTRACT(YEAR FROM tanggal_pencairan) = ?", tahun).
+		Scan(&biaya).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return total + biaya, nil
 }
 
 // GetPendapatanNonOperasionalByYear calculates non-operational income for a specific year
 // This could include investment returns, grants, or other non-operational income
-func (r *SHUTahunanRepository) GetPendapatanNonOperasionalByYear(tahun int) (float64, error) {
+func (r *SHUTahunanRepository) GetPendapatanNonOperasionalByYear(tahun int) (money.Money, error) {
 	// For now, this returns 0 as we don't have non-operational income tracking
 	// This can be extended later when non-operational income sources are added
 	return 0, nil
 }
 
+// GetTunggakanWajibByUser counts per user the simpanan wajib months due before
+// tanggal that are not paid in full
+func (r *SHUTahunanRepository) GetTunggakanWajibByUser(tanggal time.Time) (map[uint]int, error) {
+	type UserTunggakan struct {
+		UserID uint
+		Bulan  int
+	}
+
+	var results []UserTunggakan
+	err := r.db.Model(&model.KewajibanWajib{}).
+		Select("user_id, COUNT(*) as bulan").
+		Where("status <> ? AND tanggal_jatuh_tempo < ?", model.KewajibanWajibLunas, tanggal).
+		Group("user_id").
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	tunggakan := make(map[uint]int)
+	for _, result := range results {
+		tunggakan[result.UserID] = result.Bulan
+	}
+	return tunggakan, nil
+}
+
 // GetAllUsers returns all users for SHU calculation
 func (r *SHUTahunanRepository) GetAllUsers() ([]model.User, error) {
 	var users []model.User
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"errors"
+	"koperasi-service/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SimpananBerjangkaRepository handles persistence for time deposits
+type SimpananBerjangkaRepository struct {
+	db *gorm.DB
+}
+
+// NewSimpananBerjangkaRepository constructs a new repository instance
+func NewSimpananBerjangkaRepository(db *gorm.DB) *SimpananBerjangkaRepository {
+	return &SimpananBerjangkaRepository{db: db}
+}
+
+// Create inserts a deposit
+func (r *SimpananBerjangkaRepository) Create(b *model.SimpananBerjangka) error {
+	return r.db.Omit("Jenis").Create(b).Error
+}
+
+// GetByID returns a deposit with its product
+func (r *SimpananBerjangkaRepository) GetByID(id uint) (*model.SimpananBerjangka, error) {
+	var b model.SimpananBerjangka
+	if err := r.db.Preload("Jenis").First(&b, id).Error; err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// GetByIDForUpdate returns a deposit and takes a row lock on it.
+// Must be called inside UnitOfWork.Do.
+func (r *SimpananBerjangkaRepository) GetByIDForUpdate(id uint) (*model.SimpananBerjangka, error) {
+	var b model.SimpananBerjangka
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&b, id).Error; err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// GetBySimpananIDForUpdate returns the deposit of a berjangka wallet and takes
+// a row lock on it, or nil when the wallet is not a deposit
+func (r *SimpananBerjangkaRepository) GetBySimpananIDForUpdate(simpananID uint) (*model.SimpananBerjangka, error) {
+	var b model.SimpananBerjangka
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("simpanan_id = ?", simpananID).First(&b).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Update persists changes to a deposit
+func (r *SimpananBerjangkaRepository) Update(b *model.SimpananBerjangka) error {
+	return r.db.Omit("Jenis").Save(b).Error
+}
+
+// List returns deposits, newest first. A zero userID or no statuses match
+// every deposit.
+func (r *SimpananBerjangkaRepository) List(userID uint, statuses ...string) ([]model.SimpananBerjangka, error) {
+	var list []model.SimpananBerjangka
+	q := r.db.Preload("Jenis")
+	if userID > 0 {
+		q = q.Where("user_id = ?", userID)
+	}
+	if len(statuses) > 0 {
+		q = q.Where("status IN ?", statuses)
+	}
+	if err := q.Order("id DESC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetJatuhTempo returns the active deposits that mature before sampai, oldest first
+func (r *SimpananBerjangkaRepository) GetJatuhTempo(sampai time.Time) ([]model.SimpananBerjangka, error) {
+	var list []model.SimpananBerjangka
+	err := r.db.Where("status = ? AND tanggal_jatuh_tempo < ?", model.BerjangkaAktif, sampai).
+		Order("tanggal_jatuh_tempo, id").
+		Find(&list).Error
+	return list, err
+}
@@ -1,9 +1,11 @@
 package repository
 
 import (
+	"errors"
 	"koperasi-service/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // SimpananRepository handles persistence for Simpanan wallets and transactions.
@@ -16,9 +18,12 @@ func NewSimpananRepository(db *gorm.DB) *SimpananRepository {
 	return &SimpananRepository{db: db}
 }
 
-// InitializeUserWallets creates the three wallet types for a new user
+// InitializeUserWallets creates a wallet of every active wallet type for a new user
 func (r *SimpananRepository) InitializeUserWallets(userID uint) error {
-	walletTypes := []string{"pokok", "wajib", "sukarela"}
+	var walletTypes []string
+	if err := r.db.Model(&model.JenisSimpanan{}).Where("is_active = ?", true).Order("id").Pluck("kode", &walletTypes).Error; err != nil {
+		return err
+	}
 
 	for _, walletType := range walletTypes {
 		wallet := &model.Simpanan{
@@ -34,10 +39,30 @@ func (r *SimpananRepository) InitializeUserWallets(userID uint) error {
 	return nil
 }
 
-// GetUserWallets returns all wallet types for a specific user
+// BackfillWallets creates a wallet of walletType for every user that has none
+// yet; members who have left get none. It returns the number of wallets created.
+func (r *SimpananRepository) BackfillWallets(walletType string) (int64, error) {
+	res := r.db.Exec(`INSERT INTO simpanans (created_at, updated_at, user_id, type, balance, saldo_ditahan, description)
+		SELECT NOW(), NOW(), u.id, ?, 0, 0, ?
+		FROM users u
+		WHERE u.deleted_at IS NULL AND u.status_keanggotaan <> ?
+		AND NOT EXISTS (SELECT 1 FROM simpanans s WHERE s.user_id = u.id AND s.type = ? AND s.deleted_at IS NULL)`,
+		walletType, "Wallet "+walletType, model.StatusAnggotaKeluar, walletType)
+	return res.RowsAffected, res.Error
+}
+
+// HasWalletsWithBalance reports whether any wallet of walletType still holds money
+func (r *SimpananRepository) HasWalletsWithBalance(walletType string) (bool, error) {
+	var n int64
+	err := r.db.Model(&model.Simpanan{}).Where("type = ? AND balance <> 0", walletType).Count(&n).Error
+	return n > 0, err
+}
+
+// GetUserWallets returns all wallet types for a specific user, time deposits
+// with their deposit details
 func (r *SimpananRepository) GetUserWallets(userID uint) ([]model.Simpanan, error) {
 	var wallets []model.Simpanan
-	if err := r.db.Where("user_id = ?", userID).Find(&wallets).Error; err != nil {
+	if err := r.db.Preload("Berjangka").Where("user_id = ?", userID).Order("id").Find(&wallets).Error; err != nil {
 		return nil, err
 	}
 	return wallets, nil
@@ -65,6 +90,17 @@ func (r *SimpananRepository) GetAllWallets(userID uint) ([]model.Simpanan, error
 	return list, nil
 }
 
+// GetWalletsByType returns the wallets of one type whose owner has not left
+// the koperasi, in id order.
+func (r *SimpananRepository) GetWalletsByType(walletType string) ([]model.Simpanan, error) {
+	var list []model.Simpanan
+	err := r.db.Joins("JOIN users ON users.id = simpanans.user_id AND users.deleted_at IS NULL").
+		Where("simpanans.type = ? AND users.status_keanggotaan <> ?", walletType, model.StatusAnggotaKeluar).
+		Order("simpanans.id").
+		Find(&list).Error
+	return list, err
+}
+
 // GetWalletByID returns single wallet by id.
 func (r *SimpananRepository) GetWalletByID(id uint) (*model.Simpanan, error) {
 	var s model.Simpanan
@@ -74,9 +110,24 @@ func (r *SimpananRepository) GetWalletByID(id uint) (*model.Simpanan, error) {
 	return &s, nil
 }
 
+// GetWalletByIDForUpdate returns a wallet and takes a row lock (SELECT ... FOR UPDATE).
+// Must be called inside UnitOfWork.Do.
+func (r *SimpananRepository) GetWalletByIDForUpdate(id uint) (*model.Simpanan, error) {
+	var s model.Simpanan
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&s, id).Error; err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
 // UpdateWallet persists changes to an existing wallet.
 func (r *SimpananRepository) UpdateWallet(s *model.Simpanan) error {
-	return r.db.Save(s).Error
+	return r.db.Omit("Berjangka").Save(s).Error
+}
+
+// CreateWallet inserts a wallet, such as the wallet of a new time deposit
+func (r *SimpananRepository) CreateWallet(s *model.Simpanan) error {
+	return r.db.Omit("Berjangka").Create(s).Error
 }
 
 // CreateTransaction creates a new simpanan transaction
@@ -102,9 +153,49 @@ func (r *SimpananRepository) GetTransactionByID(id uint) (*model.SimpananTransac
 	return &tx, nil
 }
 
-// UpdateTransaction updates a transaction
-func (r *SimpananRepository) UpdateTransaction(tx *model.SimpananTransaction) error {
-	return r.db.Save(tx).Error
+// GetTransactionByIDForUpdate returns a transaction and takes a row lock on it.
+// Must be called inside UnitOfWork.Do.
+func (r *SimpananRepository) GetTransactionByIDForUpdate(id uint) (*model.SimpananTransaction, error) {
+	var tx model.SimpananTransaction
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tx, id).Error; err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// UpdateTransaction records the outcome of a transaction that is still in
+// fromStatus. Processed transactions are immutable, so the update fails if the
+// row has already moved on.
+func (r *SimpananRepository) UpdateTransaction(tx *model.SimpananTransaction, fromStatus string) error {
+	res := r.db.Model(&model.SimpananTransaction{}).
+		Where("id = ? AND status = ?", tx.ID, fromStatus).
+		Updates(map[string]interface{}{
+			"status":            tx.Status,
+			"verified_by_id":    tx.VerifiedByID,
+			"verified_at":       tx.VerifiedAt,
+			"ledger_journal_id": tx.LedgerJournalID,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("transaction already processed")
+	}
+	return nil
+}
+
+// SetTransactionJournal links a transaction to the ledger journal that posted it.
+func (r *SimpananRepository) SetTransactionJournal(id uint, journalID uint) error {
+	return r.db.Model(&model.SimpananTransaction{}).
+		Where("id = ? AND ledger_journal_id IS NULL", id).
+		Update("ledger_journal_id", journalID).Error
+}
+
+// SetTransactionPasangan links a transaction to the other leg of its move.
+func (r *SimpananRepository) SetTransactionPasangan(id uint, pasanganID uint) error {
+	return r.db.Model(&model.SimpananTransaction{}).
+		Where("id = ? AND pasangan_id IS NULL", id).
+		Update("pasangan_id", pasanganID).Error
 }
 
 // GetPendingTransactions returns all pending transactions (for admin verification)
@@ -115,3 +206,15 @@ func (r *SimpananRepository) GetPendingTransactions() ([]model.SimpananTransacti
 	}
 	return transactions, nil
 }
+
+// HasTransaction reports whether a wallet has a transaction of the given type
+// in one of statuses
+func (r *SimpananRepository) HasTransaction(simpananID uint, txType string, statuses ...string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.SimpananTransaction{}).
+		Where("simpanan_id = ? AND type = ? AND status IN ?", simpananID, txType, statuses).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
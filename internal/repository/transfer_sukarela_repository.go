@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"koperasi-service/internal/model"
+	"koperasi-service/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TransferSukarelaRepository handles persistence for sukarela transfers between members
+type TransferSukarelaRepository struct {
+	db *gorm.DB
+}
+
+// NewTransferSukarelaRepository constructs a new repository instance
+func NewTransferSukarelaRepository(db *gorm.DB) *TransferSukarelaRepository {
+	return &TransferSukarelaRepository{db: db}
+}
+
+// Create inserts a transfer
+func (r *TransferSukarelaRepository) Create(t *model.TransferSukarela) error {
+	return r.db.Create(t).Error
+}
+
+// GetByID returns a transfer
+func (r *TransferSukarelaRepository) GetByID(id uint) (*model.TransferSukarela, error) {
+	var t model.TransferSukarela
+	if err := r.db.First(&t, id).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetByIDForUpdate returns a transfer and takes a row lock on it.
+// Must be called inside UnitOfWork.Do.
+func (r *TransferSukarelaRepository) GetByIDForUpdate(id uint) (*model.TransferSukarela, error) {
+	var t model.TransferSukarela
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&t, id).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Update persists changes to a transfer
+func (r *TransferSukarelaRepository) Update(t *model.TransferSukarela) error {
+	return r.db.Save(t).Error
+}
+
+// List returns transfers a member sent or received, newest first. A zero
+// userID or an empty status matches every transfer.
+func (r *TransferSukarelaRepository) List(userID uint, status string) ([]model.TransferSukarela, error) {
+	var list []model.TransferSukarela
+	q := r.db
+	if userID > 0 {
+		q = q.Where("pengirim_id = ? OR penerima_id = ?", userID, userID)
+	}
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if err := q.Order("id DESC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// SumTerkirim returns the total a member confirmed sending in [from, to),
+// leaving out rejected and cancelled transfers
+func (r *TransferSukarelaRepository) SumTerkirim(pengirimID uint, from, to time.Time) (money.Money, error) {
+	var total money.Money
+	err := r.db.Model(&model.TransferSukarela{}).
+		Select("COALESCE(SUM(jumlah), 0)").
+		Where("pengirim_id = ? AND status IN ? AND dikonfirmasi_pada >= ? AND dikonfirmasi_pada < ?",
+			pengirimID, []string{model.TransferMenungguPersetujuan, model.TransferBerhasil}, from, to).
+		Scan(&total).Error
+	return total, err
+}
+
+// CountMenungguPersetujuan counts the transfers a member sent or is to receive
+// that wait for an admin
+func (r *TransferSukarelaRepository) CountMenungguPersetujuan(userID uint) (int64, error) {
+	var n int64
+	err := r.db.Model(&model.TransferSukarela{}).
+		Where("(pengirim_id = ? OR penerima_id = ?) AND status = ?", userID, userID, model.TransferMenungguPersetujuan).
+		Count(&n).Error
+	return n, err
+}
@@ -0,0 +1,72 @@
+package repository
+
+import "gorm.io/gorm"
+
+// Repositories groups repository instances that share one database transaction.
+type Repositories struct {
+	Users           *UserRepository
+	Simpanan        *SimpananRepository
+	Pinjaman        *PinjamanRepository
+	Angsuran        *AngsuranRepository
+	Ledger          *LedgerRepository
+	Jadwal          *JadwalAngsuranRepository
+	Kolektibilitas  *KolektibilitasRepository
+	Restrukturisasi *RestrukturisasiRepository
+	Pencairan       *PencairanRepository
+	Transaksi       TransactionHistoryRepository
+	Penjamin        *PenjaminRepository
+	Agunan          *AgunanRepository
+	AutoDebet       *AutoDebetRepository
+	Notifikasi      *NotifikasiRepository
+	Penarikan       *PenarikanRepository
+	KewajibanWajib  *KewajibanWajibRepository
+	JasaSimpanan    *JasaSimpananRepository
+	Berjangka       *SimpananBerjangkaRepository
+	Transfer        *TransferSukarelaRepository
+	Berkas          *BerkasRepository
+	SHUAnggota      *SHUAnggotaRepository
+}
+
+// UnitOfWork runs multi-step operations so they either fully commit or fully roll back.
+type UnitOfWork struct {
+	db *gorm.DB
+}
+
+// NewUnitOfWork constructs a new unit of work.
+func NewUnitOfWork(db *gorm.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// Do runs fn inside a database transaction. Every repository in repos is bound
+// to that transaction; returning an error (or panicking) rolls everything back.
+func (u *UnitOfWork) Do(fn func(repos *Repositories) error) error {
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		return fn(newRepositories(tx))
+	})
+}
+
+func newRepositories(tx *gorm.DB) *Repositories {
+	return &Repositories{
+		Users:           &UserRepository{db: tx},
+		Simpanan:        &SimpananRepository{db: tx},
+		Pinjaman:        &PinjamanRepository{db: tx},
+		Angsuran:        &AngsuranRepository{db: tx},
+		Ledger:          &LedgerRepository{db: tx},
+		Jadwal:          &JadwalAngsuranRepository{db: tx},
+		Kolektibilitas:  &KolektibilitasRepository{db: tx},
+		Restrukturisasi: &RestrukturisasiRepository{db: tx},
+		Pencairan:       &PencairanRepository{db: tx},
+		Transaksi:       &transactionHistoryRepository{db: tx},
+		Penjamin:        &PenjaminRepository{db: tx},
+		Agunan:          &AgunanRepository{db: tx},
+		AutoDebet:       &AutoDebetRepository{db: tx},
+		Notifikasi:      &NotifikasiRepository{db: tx},
+		Penarikan:       &PenarikanRepository{db: tx},
+		KewajibanWajib:  &KewajibanWajibRepository{db: tx},
+		JasaSimpanan:    &JasaSimpananRepository{db: tx},
+		Berjangka:       &SimpananBerjangkaRepository{db: tx},
+		Transfer:        &TransferSukarelaRepository{db: tx},
+		Berkas:          &BerkasRepository{db: tx},
+		SHUAnggota:      &SHUAnggotaRepository{db: tx},
+	}
+}
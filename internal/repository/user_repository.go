@@ -4,6 +4,7 @@ import (
 	"koperasi-service/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository struct {
@@ -55,6 +56,16 @@ func (r *UserRepository) FindByID(id uint) (*model.User, error) {
 	return &u, nil
 }
 
+// FindByIDForUpdate returns user by id and takes a row lock on it.
+// Must be called inside UnitOfWork.Do.
+func (r *UserRepository) FindByIDForUpdate(id uint) (*model.User, error) {
+	var u model.User
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&u, id).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
 // Update saves user changes.
 func (r *UserRepository) Update(u *model.User) error {
 	return r.db.Save(u).Error
@@ -64,3 +75,22 @@ func (r *UserRepository) Update(u *model.User) error {
 func (r *UserRepository) Delete(id uint) error {
 	return r.db.Delete(&model.User{}, id).Error
 }
+
+// ListByRole returns the users with the named role, oldest first.
+func (r *UserRepository) ListByRole(roleName string) ([]model.User, error) {
+	var users []model.User
+	if err := r.db.Joins("JOIN roles ON roles.id = users.role_id").
+		Where("roles.name = ?", roleName).Order("users.id").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// FindRoleByID returns a role by id.
+func (r *UserRepository) FindRoleByID(id uint) (*model.Role, error) {
+	var role model.Role
+	if err := r.db.First(&role, id).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
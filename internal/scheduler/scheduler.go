@@ -0,0 +1,86 @@
+// Package scheduler runs background jobs once a day at a fixed local time.
+package scheduler
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Job is a task that runs once a day at Hour:Minute local time
+type Job struct {
+	Name   string
+	Hour   int
+	Minute int
+	Run    func(now time.Time) error
+}
+
+// Scheduler starts one goroutine per job and stops them on Stop
+type Scheduler struct {
+	jobs []Job
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New returns an empty scheduler
+func New() *Scheduler {
+	return &Scheduler{stop: make(chan struct{})}
+}
+
+// Daily registers a job that runs every day at hour:minute local time
+func (s *Scheduler) Daily(name string, hour, minute int, run func(now time.Time) error) {
+	s.jobs = append(s.jobs, Job{Name: name, Hour: hour, Minute: minute, Run: run})
+}
+
+// Start launches the registered jobs
+func (s *Scheduler) Start() {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(job)
+	}
+}
+
+// Stop signals all jobs to stop and waits for running ones to finish
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(job Job) {
+	defer s.wg.Done()
+	for {
+		wait := time.Until(NextRun(time.Now(), job.Hour, job.Minute))
+		timer := time.NewTimer(wait)
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case now := <-timer.C:
+			s.run(job, now)
+		}
+	}
+}
+
+// run executes one job and keeps a panic from taking the server down
+func (s *Scheduler) run(job Job, now time.Time) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("scheduler: job %s panicked: %v", job.Name, r)
+		}
+	}()
+	start := time.Now()
+	if err := job.Run(now); err != nil {
+		log.Printf("scheduler: job %s failed: %v", job.Name, err)
+		return
+	}
+	log.Printf("scheduler: job %s finished in %s", job.Name, time.Since(start))
+}
+
+// NextRun returns the first hour:minute strictly after now
+func NextRun(now time.Time, hour, minute int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
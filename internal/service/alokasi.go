@@ -0,0 +1,130 @@
+package service
+
+import (
+	"koperasi-service/internal/model"
+	"koperasi-service/pkg/money"
+	"time"
+)
+
+// alokasiResult is the outcome of allocating one payment against a schedule
+type alokasiResult struct {
+	Denda     money.Money // Total paid to late fees
+	Bunga     money.Money // Total paid to interest
+	Pokok     money.Money // Total paid to principal
+	Kelebihan money.Money // Left over after the schedule, to be refunded
+	Lebih     bool        // More was paid than was due
+	Kurang    bool        // A due installment is still not fully paid
+	Rows      []model.AlokasiAngsuran
+	Changed   []int // Indexes of the schedule rows that received money
+}
+
+// alokasikan allocates jumlah against the open rows of jadwal and updates the
+// rows in place. Rows due on or before tanggal are settled first: all their
+// denda, then their bunga, then their pokok, oldest row first within each
+// component. When nothing is due yet the next open row counts as due.
+// Whatever is left either rolls into the following installments (lanjut) or
+// is reported as Kelebihan for a refund.
+func alokasikan(jadwal []model.JadwalAngsuran, jumlah money.Money, tanggal time.Time, lanjut bool, now time.Time) alokasiResult {
+	var res alokasiResult
+	sisa := jumlah
+	alokasi := make(map[int]*model.AlokasiAngsuran)
+
+	pay := func(i int, outstanding money.Money, paid *money.Money, total *money.Money, part func(a *model.AlokasiAngsuran) *money.Money) {
+		amount := money.Min(sisa, outstanding)
+		if amount <= 0 {
+			return
+		}
+		a, ok := alokasi[i]
+		if !ok {
+			a = &model.AlokasiAngsuran{JadwalAngsuranID: jadwal[i].ID, AngsuranKe: jadwal[i].AngsuranKe}
+			alokasi[i] = a
+			res.Changed = append(res.Changed, i)
+		}
+		*paid += amount
+		*total += amount
+		*part(a) += amount
+		sisa -= amount
+	}
+	payDenda := func(i int) {
+		pay(i, jadwal[i].SisaDenda(), &jadwal[i].DendaDibayar, &res.Denda, func(a *model.AlokasiAngsuran) *money.Money { return &a.Denda })
+	}
+	payBunga := func(i int) {
+		pay(i, jadwal[i].SisaBunga(), &jadwal[i].BungaDibayar, &res.Bunga, func(a *model.AlokasiAngsuran) *money.Money { return &a.Bunga })
+	}
+	payPokok := func(i int) {
+		pay(i, jadwal[i].SisaPokokAngsuran(), &jadwal[i].PokokDibayar, &res.Pokok, func(a *model.AlokasiAngsuran) *money.Money { return &a.Pokok })
+	}
+
+	// Rows that are due, and the open rows after them
+	var due, later []int
+	for i := range jadwal {
+		if jadwal[i].SisaTagihan() <= 0 {
+			continue
+		}
+		if !jadwal[i].TanggalJatuhTempo.After(tanggal) {
+			due = append(due, i)
+		} else {
+			later = append(later, i)
+		}
+	}
+	if len(due) == 0 && len(later) > 0 {
+		due, later = later[:1], later[1:]
+	}
+
+	for _, i := range due {
+		payDenda(i)
+	}
+	for _, i := range due {
+		payBunga(i)
+	}
+	for _, i := range due {
+		payPokok(i)
+	}
+	for _, i := range due {
+		if jadwal[i].SisaTagihan() > 0 {
+			res.Kurang = true
+		}
+	}
+
+	res.Lebih = sisa > 0
+	if lanjut {
+		for _, i := range later {
+			payDenda(i)
+			payBunga(i)
+			payPokok(i)
+		}
+	}
+	res.Kelebihan = sisa
+
+	for _, i := range res.Changed {
+		row := &jadwal[i]
+		if row.SisaTagihan() <= 0 {
+			row.Status = model.JadwalLunas
+			row.TanggalLunas = &now
+		} else {
+			row.Status = model.JadwalSebagian
+		}
+		res.Rows = append(res.Rows, *alokasi[i])
+	}
+	return res
+}
+
+// sisaPokokPinjaman returns the principal of the schedule that is still unpaid
+func sisaPokokPinjaman(jadwal []model.JadwalAngsuran) money.Money {
+	var total money.Money
+	for i := range jadwal {
+		total += jadwal[i].SisaPokokAngsuran()
+	}
+	return total
+}
+
+// sisaAngsuranTerbuka counts the schedule rows that are not fully paid
+func sisaAngsuranTerbuka(jadwal []model.JadwalAngsuran) int {
+	n := 0
+	for i := range jadwal {
+		if jadwal[i].Status != model.JadwalLunas {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"koperasi-service/internal/model"
+	"koperasi-service/pkg/money"
+	"testing"
+	"time"
+)
+
+// jadwalAlokasi is three installments of 100 pokok and 10 bunga due on the
+// first of January, February and March 2024; the first two carry denda.
+func jadwalAlokasi() []model.JadwalAngsuran {
+	rp := money.FromRupiah
+	jadwal := make([]model.JadwalAngsuran, 3)
+	for i := range jadwal {
+		jadwal[i].ID = uint(i + 1)
+		jadwal[i].AngsuranKe = i + 1
+		jadwal[i].TanggalJatuhTempo = time.Date(2024, time.Month(i+1), 1, 0, 0, 0, 0, time.UTC)
+		jadwal[i].Pokok = rp(100)
+		jadwal[i].Bunga = rp(10)
+		jadwal[i].Status = model.JadwalBelumBayar
+	}
+	jadwal[0].Denda = rp(5)
+	jadwal[1].Denda = rp(2)
+	return jadwal
+}
+
+func TestAlokasikan(t *testing.T) {
+	rp := money.FromRupiah
+	februari := time.Date(2024, time.February, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		jumlah        money.Money
+		tanggal       time.Time
+		lanjut        bool
+		want          []model.AlokasiAngsuran
+		wantKelebihan money.Money
+		wantLebih     bool
+		wantKurang    bool
+		wantStatus    []string
+	}{
+		{
+			name:   "denda then bunga of all due rows before any pokok",
+			jumlah: rp(20), tanggal: februari, lanjut: true,
+			want: []model.AlokasiAngsuran{
+				{JadwalAngsuranID: 1, AngsuranKe: 1, Denda: rp(5), Bunga: rp(10)},
+				{JadwalAngsuranID: 2, AngsuranKe: 2, Denda: rp(2), Bunga: rp(3)},
+			},
+			wantKurang: true,
+			wantStatus: []string{model.JadwalSebagian, model.JadwalSebagian, model.JadwalBelumBayar},
+		},
+		{
+			name:   "exactly the due rows",
+			jumlah: rp(227), tanggal: februari, lanjut: true,
+			want: []model.AlokasiAngsuran{
+				{JadwalAngsuranID: 1, AngsuranKe: 1, Denda: rp(5), Bunga: rp(10), Pokok: rp(100)},
+				{JadwalAngsuranID: 2, AngsuranKe: 2, Denda: rp(2), Bunga: rp(10), Pokok: rp(100)},
+			},
+			wantStatus: []string{model.JadwalLunas, model.JadwalLunas, model.JadwalBelumBayar},
+		},
+		{
+			name:   "overpayment carries over to the next row",
+			jumlah: rp(250), tanggal: februari, lanjut: true,
+			want: []model.AlokasiAngsuran{
+				{JadwalAngsuranID: 1, AngsuranKe: 1, Denda: rp(5), Bunga: rp(10), Pokok: rp(100)},
+				{JadwalAngsuranID: 2, AngsuranKe: 2, Denda: rp(2), Bunga: rp(10), Pokok: rp(100)},
+				{JadwalAngsuranID: 3, AngsuranKe: 3, Bunga: rp(10), Pokok: rp(13)},
+			},
+			wantLebih:  true,
+			wantStatus: []string{model.JadwalLunas, model.JadwalLunas, model.JadwalSebagian},
+		},
+		{
+			name:   "overpayment to be refunded",
+			jumlah: rp(250), tanggal: februari, lanjut: false,
+			want: []model.AlokasiAngsuran{
+				{JadwalAngsuranID: 1, AngsuranKe: 1, Denda: rp(5), Bunga: rp(10), Pokok: rp(100)},
+				{JadwalAngsuranID: 2, AngsuranKe: 2, Denda: rp(2), Bunga: rp(10), Pokok: rp(100)},
+			},
+			wantKelebihan: rp(23),
+			wantLebih:     true,
+			wantStatus:    []string{model.JadwalLunas, model.JadwalLunas, model.JadwalBelumBayar},
+		},
+		{
+			name:   "more than the whole schedule",
+			jumlah: rp(400), tanggal: februari, lanjut: true,
+			want: []model.AlokasiAngsuran{
+				{JadwalAngsuranID: 1, AngsuranKe: 1, Denda: rp(5), Bunga: rp(10), Pokok: rp(100)},
+				{JadwalAngsuranID: 2, AngsuranKe: 2, Denda: rp(2), Bunga: rp(10), Pokok: rp(100)},
+				{JadwalAngsuranID: 3, AngsuranKe: 3, Bunga: rp(10), Pokok: rp(100)},
+			},
+			wantKelebihan: rp(63),
+			wantLebih:     true,
+			wantStatus:    []string{model.JadwalLunas, model.JadwalLunas, model.JadwalLunas},
+		},
+		{
+			name:   "nothing due yet pays the next row",
+			jumlah: rp(50), tanggal: time.Date(2023, time.December, 20, 0, 0, 0, 0, time.UTC), lanjut: true,
+			want: []model.AlokasiAngsuran{
+				{JadwalAngsuranID: 1, AngsuranKe: 1, Denda: rp(5), Bunga: rp(10), Pokok: rp(35)},
+			},
+			wantKurang: true,
+			wantStatus: []string{model.JadwalSebagian, model.JadwalBelumBayar, model.JadwalBelumBayar},
+		},
+	}
+	now := time.Date(2024, time.February, 16, 0, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jadwal := jadwalAlokasi()
+			res := alokasikan(jadwal, tt.jumlah, tt.tanggal, tt.lanjut, now)
+
+			if len(res.Rows) != len(tt.want) {
+				t.Fatalf("rows = %+v, want %+v", res.Rows, tt.want)
+			}
+			var denda, bunga, pokok money.Money
+			for i, got := range res.Rows {
+				if got != tt.want[i] {
+					t.Errorf("row %d = %+v, want %+v", i, got, tt.want[i])
+				}
+				denda += got.Denda
+				bunga += got.Bunga
+				pokok += got.Pokok
+			}
+			if res.Denda != denda || res.Bunga != bunga || res.Pokok != pokok {
+				t.Errorf("totals %s/%s/%s, rows add up to %s/%s/%s", res.Denda, res.Bunga, res.Pokok, denda, bunga, pokok)
+			}
+			if res.Denda+res.Bunga+res.Pokok+res.Kelebihan != tt.jumlah {
+				t.Errorf("allocated %s of %s", res.Denda+res.Bunga+res.Pokok+res.Kelebihan, tt.jumlah)
+			}
+			if res.Kelebihan != tt.wantKelebihan || res.Lebih != tt.wantLebih || res.Kurang != tt.wantKurang {
+				t.Errorf("kelebihan %s lebih %v kurang %v, want %s %v %v", res.Kelebihan, res.Lebih, res.Kurang, tt.wantKelebihan, tt.wantLebih, tt.wantKurang)
+			}
+			for i, row := range jadwal {
+				if row.Status != tt.wantStatus[i] {
+					t.Errorf("jadwal %d status %s, want %s", i, row.Status, tt.wantStatus[i])
+				}
+				if (row.Status == model.JadwalLunas) != (row.TanggalLunas != nil) {
+					t.Errorf("jadwal %d: status %s with tanggal lunas %v", i, row.Status, row.TanggalLunas)
+				}
+			}
+		})
+	}
+}
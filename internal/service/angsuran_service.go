@@ -4,26 +4,34 @@ import (
 	"errors"
 	"koperasi-service/internal/model"
 	"koperasi-service/internal/repository"
+	"koperasi-service/pkg/money"
 	"time"
 )
 
 // AngsuranService handles business logic for Angsuran with role constraints
 type AngsuranService struct {
-	repo         *repository.AngsuranRepository
-	pinjamanRepo *repository.PinjamanRepository
-	userRepo     *repository.UserRepository
+	repo            *repository.AngsuranRepository
+	pinjamanRepo    *repository.PinjamanRepository
+	userRepo        *repository.UserRepository
+	jadwalRepo      *repository.JadwalAngsuranRepository
+	aturanDendaRepo repository.AturanRepository[model.AturanDenda]
+	uow             *repository.UnitOfWork
 }
 
 // NewAngsuranService creates a new service instance
-func NewAngsuranService(repo *repository.AngsuranRepository, pinjamanRepo *repository.PinjamanRepository, userRepo *repository.UserRepository) *AngsuranService {
+func NewAngsuranService(repo *repository.AngsuranRepository, pinjamanRepo *repository.PinjamanRepository, userRepo *repository.UserRepository, jadwalRepo *repository.JadwalAngsuranRepository, aturanDendaRepo repository.AturanRepository[model.AturanDenda], uow *repository.UnitOfWork) *AngsuranService {
 	return &AngsuranService{
-		repo:         repo,
-		pinjamanRepo: pinjamanRepo,
-		userRepo:     userRepo,
+		repo:            repo,
+		pinjamanRepo:    pinjamanRepo,
+		userRepo:        userRepo,
+		jadwalRepo:      jadwalRepo,
+		aturanDendaRepo: aturanDendaRepo,
+		uow:             uow,
 	}
 }
 
-// Create adds a new Angsuran payment record
+// Create adds a new Angsuran payment record. An uploaded receipt given in
+// BuktiTransferID is attached to it.
 func (s *AngsuranService) Create(requestorID uint, requestorRole string, a *model.Angsuran) error {
 	// Verify the pinjaman exists and access is allowed
 	pinjaman, err := s.pinjamanRepo.GetByID(a.PinjamanID)
@@ -36,13 +44,14 @@ func (s *AngsuranService) Create(requestorID uint, requestorRole string, a *mode
 		return errors.New("forbidden")
 	}
 
-	// Set default values
-	if a.TanggalBayar.IsZero() {
-		a.TanggalBayar = time.Now()
-	}
-	if a.Status == "" {
-		a.Status = "proses"
+	// Installments are only paid on disbursed loans (or recovered on written-off ones)
+	if pinjaman.Status != model.StatusPinjamanDicairkan && pinjaman.Status != model.StatusPinjamanMacet {
+		return errors.New("pinjaman has not been disbursed")
 	}
+
+	// The payment date is the server's; a date chosen by the client could dodge denda.
+	// The admin confirms when the money actually arrived on verification.
+	a.TanggalBayar = time.Now()
 	if a.UserID == 0 {
 		a.UserID = pinjaman.UserID
 	}
@@ -56,12 +65,48 @@ func (s *AngsuranService) Create(requestorID uint, requestorRole string, a *mode
 		a.AngsuranKe = nextKe
 	}
 
+	// Denda is computed from the schedule and the active rule, never typed in
+	aturan, err := s.aturanDendaRepo.GetActive()
+	if err != nil {
+		return err
+	}
+	jadwal, err := s.jadwalRepo.GetByPinjaman(a.PinjamanID)
+	if err != nil {
+		return err
+	}
+	terapkanDenda(jadwal, aturan, a.TanggalBayar)
+	a.Denda = sisaDendaPinjaman(jadwal)
+
 	// Calculate total if not provided
 	if a.TotalBayar == 0 {
 		a.TotalBayar = a.Pokok + a.Bunga + a.Denda
 	}
+	if a.TotalBayar <= 0 {
+		return errors.New("total bayar must be greater than 0")
+	}
+	// The split is only the member's claim until verification allocates it
+	a.Status = "proses"
 
-	return s.repo.Create(a)
+	if a.BuktiTransferID != nil {
+		a.ImageBuktiTransfer = model.BerkasURL(*a.BuktiTransferID)
+	}
+	return s.uow.Do(func(repos *repository.Repositories) error {
+		// Serializes with pelunasan, which refuses a loan with pending payments
+		locked, err := repos.Pinjaman.GetByIDForUpdate(a.PinjamanID)
+		if err != nil {
+			return err
+		}
+		if locked.Status != model.StatusPinjamanDicairkan && locked.Status != model.StatusPinjamanMacet {
+			return errors.New("pinjaman has not been disbursed")
+		}
+		if err := repos.Angsuran.Create(a); err != nil {
+			return err
+		}
+		if a.BuktiTransferID == nil {
+			return nil
+		}
+		return lampirkanBukti(repos, *a.BuktiTransferID, requestorID, model.BerkasAngsuran, a.ID, pinjaman.UserID)
+	})
 }
 
 // List returns angsuran list filtered by access rules
@@ -121,6 +166,11 @@ func (s *AngsuranService) Update(requestorID uint, requestorRole string, id uint
 		return nil, errors.New("forbidden")
 	}
 
+	// Verified payments are allocated to the schedule and can no longer change
+	if existing.Status != "proses" {
+		return nil, errors.New("payment already verified")
+	}
+
 	// Update allowed fields - only update if explicitly provided
 	if payload.Pokok > 0 {
 		existing.Pokok = payload.Pokok
@@ -130,21 +180,12 @@ func (s *AngsuranService) Update(requestorID uint, requestorRole string, id uint
 	if payload.Bunga > 0 {
 		existing.Bunga = payload.Bunga
 	}
-	if payload.Denda >= 0 {
-		existing.Denda = payload.Denda
-	}
 	if payload.TotalBayar > 0 {
 		existing.TotalBayar = payload.TotalBayar
 	}
-	if payload.Status != "" {
-		existing.Status = payload.Status
-	}
-	if !payload.TanggalBayar.IsZero() {
-		existing.TanggalBayar = payload.TanggalBayar
-	}
 
 	// Recalculate total if components changed
-	if payload.Pokok > 0 || payload.Bunga >= 0 || payload.Denda >= 0 {
+	if payload.Pokok > 0 || payload.Bunga >= 0 {
 		existing.TotalBayar = existing.Pokok + existing.Bunga + existing.Denda
 	}
 
@@ -168,15 +209,29 @@ func (s *AngsuranService) Delete(requestorID uint, requestorRole string, id uint
 		return errors.New("forbidden")
 	}
 
+	if existing.Status != "proses" {
+		return errors.New("payment already verified")
+	}
+
 	return s.repo.Delete(id)
 }
 
-// VerifyPayment allows admin to verify angsuran payment and update status
-func (s *AngsuranService) VerifyPayment(requestorID uint, requestorRole string, id uint, status string) (*model.Angsuran, error) {
+// VerifyPayment allows admin to verify an angsuran payment. The received amount
+// (TotalBayar unless jumlahDiterima is given) is allocated against the loan's
+// schedule and the status is derived from the result: verified when the due
+// installments are exactly covered, kurang when arrears remain and lebih when
+// more was paid. Overpayment rolls into the next installments unless
+// kembalikanKelebihan is set, in which case it is recorded for a refund.
+// Denda and allocation are as of tanggalDiterima, the date the admin confirms
+// the money arrived, or TanggalBayar when it is not given.
+func (s *AngsuranService) VerifyPayment(requestorID uint, requestorRole string, id uint, jumlahDiterima *money.Money, tanggalDiterima *time.Time, kembalikanKelebihan bool) (*model.Angsuran, error) {
 	// Only admin and super_admin can verify payments
 	if requestorRole != "admin" && requestorRole != "super_admin" {
 		return nil, errors.New("forbidden")
 	}
+	if tanggalDiterima != nil && tanggalDiterima.After(time.Now()) {
+		return nil, errors.New("tanggal diterima must not be in the future")
+	}
 
 	existing, err := s.repo.GetByID(id)
 	if err != nil {
@@ -197,31 +252,150 @@ func (s *AngsuranService) VerifyPayment(requestorID uint, requestorRole string,
 		}
 	}
 
-	// Validate status
-	validStatuses := map[string]bool{"verified": true, "kurang": true, "lebih": true}
-	if !validStatuses[status] {
-		return nil, errors.New("invalid status for verification")
+	aturan, err := s.aturanDendaRepo.GetActive()
+	if err != nil {
+		return nil, err
 	}
 
-	existing.Status = status
+	var result *model.Angsuran
+	err = s.uow.Do(func(repos *repository.Repositories) error {
+		locked, err := repos.Angsuran.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if locked.Status != "proses" {
+			return errors.New("payment already verified")
+		}
+
+		jumlah := locked.TotalBayar
+		if jumlahDiterima != nil {
+			jumlah = *jumlahDiterima
+		}
+		if jumlah <= 0 {
+			return errors.New("jumlah diterima must be greater than 0")
+		}
 
-	// If payment is verified, update the pinjaman's remaining installments
-	if status == "verified" {
-		pinjaman, err := s.pinjamanRepo.GetByID(existing.PinjamanID)
-		if err == nil && pinjaman.SisaAngsuran > 0 {
-			pinjaman.SisaAngsuran--
-			if pinjaman.SisaAngsuran == 0 {
-				pinjaman.Status = "lunas"
+		pinjaman, err := repos.Pinjaman.GetByIDForUpdate(locked.PinjamanID)
+		if err != nil {
+			return err
+		}
+		if pinjaman.Status != model.StatusPinjamanDicairkan && pinjaman.Status != model.StatusPinjamanMacet {
+			return errors.New("pinjaman has not been disbursed")
+		}
+
+		jadwal, err := ensureJadwal(repos, pinjaman)
+		if err != nil {
+			return err
+		}
+
+		// Charge the denda due on the date the money arrived before allocating
+		now := time.Now()
+		diterima := locked.TanggalBayar
+		if tanggalDiterima != nil {
+			diterima = *tanggalDiterima
+		}
+		changed := make(map[int]bool)
+		for _, i := range terapkanDenda(jadwal, aturan, diterima) {
+			changed[i] = true
+		}
+		res := alokasikan(jadwal, jumlah, diterima, !kembalikanKelebihan, now)
+		for _, i := range res.Changed {
+			changed[i] = true
+		}
+		for i := range jadwal {
+			if !changed[i] {
+				continue
+			}
+			if err := repos.Jadwal.Update(&jadwal[i]); err != nil {
+				return err
 			}
-			s.pinjamanRepo.Update(pinjaman)
 		}
-	}
+		for i := range res.Rows {
+			res.Rows[i].AngsuranID = locked.ID
+		}
+		if err := repos.Angsuran.CreateAlokasi(res.Rows); err != nil {
+			return err
+		}
 
-	if err := s.repo.Update(existing); err != nil {
+		// The payment record shows what it actually paid for
+		locked.TotalBayar = jumlah
+		locked.Denda = res.Denda
+		locked.Bunga = res.Bunga
+		locked.Pokok = res.Pokok
+		locked.Kelebihan = res.Kelebihan
+		locked.KelebihanKembali = kembalikanKelebihan
+		locked.DiverifikasiOleh = &requestorID
+		locked.TanggalVerifikasi = &now
+		locked.TanggalDiterima = &diterima
+		switch {
+		case res.Kurang:
+			locked.Status = "kurang"
+		case res.Lebih:
+			locked.Status = "lebih"
+		default:
+			locked.Status = "verified"
+		}
+		if err := repos.Angsuran.Update(locked); err != nil {
+			return err
+		}
+
+		// The loan is closed only when no principal is outstanding
+		pinjaman.SisaAngsuran = sisaAngsuranTerbuka(jadwal)
+		if sisaPokokPinjaman(jadwal) == 0 {
+			if err := markLunas(pinjaman, now); err != nil {
+				return err
+			}
+			// Collateral goes back to the member once the loan is repaid
+			if err := repos.Agunan.KembalikanByPinjaman(pinjaman.ID, &requestorID, now); err != nil {
+				return err
+			}
+		}
+		if err := repos.Pinjaman.Update(pinjaman); err != nil {
+			return err
+		}
+
+		locked.Alokasi = res.Rows
+		result = locked
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return existing, nil
+	return result, nil
+}
+
+// ensureJadwal returns the locked schedule of a loan. Loans created before
+// schedules existed get one generated from their disbursement (or loan) date,
+// with the installments already counted as paid marked lunas.
+func ensureJadwal(repos *repository.Repositories, p *model.Pinjaman) ([]model.JadwalAngsuran, error) {
+	jadwal, err := repos.Jadwal.GetByPinjamanForUpdate(p.ID)
+	if err != nil || len(jadwal) > 0 {
+		return jadwal, err
+	}
+
+	start := p.TanggalPinjam
+	if p.TanggalPencairan != nil {
+		start = *p.TanggalPencairan
+	}
+	metode := p.MetodeBunga
+	if metode == "" {
+		metode = model.MetodeBungaFlat
+	}
+	jadwal, err = generateJadwal(p.JumlahPinjaman, p.BungaPersen, p.LamaBulan, metode, start)
+	if err != nil {
+		return nil, err
+	}
+	paid := p.LamaBulan - p.SisaAngsuran
+	for i := 0; i < paid && i < len(jadwal); i++ {
+		jadwal[i].BungaDibayar = jadwal[i].Bunga
+		jadwal[i].PokokDibayar = jadwal[i].Pokok
+		jadwal[i].Status = model.JadwalLunas
+	}
+	if err := saveJadwal(repos, p, jadwal); err != nil {
+		return nil, err
+	}
+	return jadwal, nil
 }
 
 // GetPendingPayments returns angsuran with 'proses' status for admin verification
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"errors"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+)
+
+// NewAturanDendaService manages the late-payment penalty rules
+func NewAturanDendaService(repo repository.AturanRepository[model.AturanDenda], userRepo *repository.UserRepository) AturanService[model.AturanDenda] {
+	return newAturanService(repo, userRepo, aturanJenis[model.AturanDenda]{
+		nama:     "aturan denda",
+		validate: validateAturanDenda,
+		salin: func(dst, src *model.AturanDenda) {
+			dst.Nama = src.Nama
+			dst.PersenPerHari = src.PersenPerHari
+			dst.BiayaTetap = src.BiayaTetap
+			dst.MasaTenggangHari = src.MasaTenggangHari
+			dst.MaksimalDenda = src.MaksimalDenda
+			dst.Deskripsi = src.Deskripsi
+		},
+	})
+}
+
+// validateAturanDenda checks the rule values
+func validateAturanDenda(aturan *model.AturanDenda) error {
+	if aturan.PersenPerHari < 0 || aturan.BiayaTetap < 0 || aturan.MaksimalDenda < 0 {
+		return errors.New("denda values must not be negative")
+	}
+	if aturan.MasaTenggangHari < 0 {
+		return errors.New("masa tenggang must not be negative")
+	}
+	if aturan.PersenPerHari == 0 && aturan.BiayaTetap == 0 {
+		return errors.New("persen per hari or biaya tetap must be set")
+	}
+	return nil
+}
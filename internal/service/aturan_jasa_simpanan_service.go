@@ -0,0 +1,42 @@
+package service
+
+import (
+	"errors"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+)
+
+// NewAturanJasaSimpananService manages the rules for the return paid on sukarela balances
+func NewAturanJasaSimpananService(repo repository.AturanRepository[model.AturanJasaSimpanan], userRepo *repository.UserRepository) AturanService[model.AturanJasaSimpanan] {
+	return newAturanService(repo, userRepo, aturanJenis[model.AturanJasaSimpanan]{
+		nama:     "aturan jasa simpanan",
+		validate: validateAturanJasaSimpanan,
+		salin: func(dst, src *model.AturanJasaSimpanan) {
+			dst.Nama = src.Nama
+			dst.PersenTahunan = src.PersenTahunan
+			dst.DasarPerhitungan = src.DasarPerhitungan
+			dst.PersenPajak = src.PersenPajak
+			dst.BatasBebasPajak = src.BatasBebasPajak
+			dst.Deskripsi = src.Deskripsi
+		},
+	})
+}
+
+// validateAturanJasaSimpanan checks the rule values
+func validateAturanJasaSimpanan(aturan *model.AturanJasaSimpanan) error {
+	if aturan.PersenTahunan <= 0 || aturan.PersenTahunan > 100 {
+		return errors.New("persen tahunan must be between 0 and 100")
+	}
+	switch aturan.DasarPerhitungan {
+	case model.DasarSaldoTerendah, model.DasarSaldoRataRata, model.DasarSaldoAkhir:
+	default:
+		return errors.New("dasar perhitungan must be saldo_terendah, saldo_rata_rata or saldo_akhir")
+	}
+	if aturan.PersenPajak < 0 || aturan.PersenPajak >= 100 {
+		return errors.New("persen pajak must be at least 0 and below 100")
+	}
+	if aturan.BatasBebasPajak < 0 {
+		return errors.New("batas bebas pajak must not be negative")
+	}
+	return nil
+}
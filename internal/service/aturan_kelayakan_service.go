@@ -0,0 +1,39 @@
+package service
+
+import (
+	"errors"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+)
+
+// NewAturanKelayakanService manages the loan eligibility rules
+func NewAturanKelayakanService(repo repository.AturanRepository[model.AturanKelayakan], userRepo *repository.UserRepository) AturanService[model.AturanKelayakan] {
+	return newAturanService(repo, userRepo, aturanJenis[model.AturanKelayakan]{
+		nama:     "aturan kelayakan",
+		validate: validateAturanKelayakan,
+		salin: func(dst, src *model.AturanKelayakan) {
+			dst.Nama = src.Nama
+			dst.KelipatanSimpanan = src.KelipatanSimpanan
+			dst.MaksimalPinjamanAktif = src.MaksimalPinjamanAktif
+			dst.MinimalBulanKeanggotaan = src.MinimalBulanKeanggotaan
+			dst.MaksimalRasioAngsuran = src.MaksimalRasioAngsuran
+			dst.KelipatanPenjaminan = src.KelipatanPenjaminan
+			dst.WajibLancar = src.WajibLancar
+			dst.Deskripsi = src.Deskripsi
+		},
+	})
+}
+
+// validateAturanKelayakan checks the rule values
+func validateAturanKelayakan(aturan *model.AturanKelayakan) error {
+	if aturan.KelipatanSimpanan < 0 || aturan.MaksimalRasioAngsuran < 0 || aturan.KelipatanPenjaminan < 0 {
+		return errors.New("kelayakan values must not be negative")
+	}
+	if aturan.MaksimalPinjamanAktif < 0 || aturan.MinimalBulanKeanggotaan < 0 {
+		return errors.New("kelayakan limits must not be negative")
+	}
+	if aturan.MaksimalRasioAngsuran > 100 {
+		return errors.New("maksimal rasio angsuran must not exceed 100")
+	}
+	return nil
+}
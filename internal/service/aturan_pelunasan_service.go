@@ -0,0 +1,43 @@
+package service
+
+import (
+	"errors"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+)
+
+// NewAturanPelunasanService manages the early-settlement rules
+func NewAturanPelunasanService(repo repository.AturanRepository[model.AturanPelunasan], userRepo *repository.UserRepository) AturanService[model.AturanPelunasan] {
+	return newAturanService(repo, userRepo, aturanJenis[model.AturanPelunasan]{
+		nama:     "aturan pelunasan",
+		validate: validateAturanPelunasan,
+		salin: func(dst, src *model.AturanPelunasan) {
+			dst.Nama = src.Nama
+			dst.KebijakanBunga = src.KebijakanBunga
+			dst.PersenRebate = src.PersenRebate
+			dst.BiayaPersen = src.BiayaPersen
+			dst.BiayaTetap = src.BiayaTetap
+			dst.Deskripsi = src.Deskripsi
+		},
+	})
+}
+
+// validateAturanPelunasan checks the rule values and defaults the interest policy
+func validateAturanPelunasan(aturan *model.AturanPelunasan) error {
+	if aturan.KebijakanBunga == "" {
+		aturan.KebijakanBunga = model.BungaPelunasanAkrual
+	}
+	if !model.ValidBungaPelunasan[aturan.KebijakanBunga] {
+		return errors.New("invalid kebijakan bunga")
+	}
+	if aturan.PersenRebate < 0 || aturan.BiayaPersen < 0 || aturan.BiayaTetap < 0 {
+		return errors.New("pelunasan values must not be negative")
+	}
+	if aturan.PersenRebate > 100 {
+		return errors.New("persen rebate must not exceed 100")
+	}
+	if aturan.PersenRebate > 0 && aturan.KebijakanBunga != model.BungaPelunasanPenuh {
+		return errors.New("persen rebate only applies to kebijakan bunga penuh")
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"errors"
+	"koperasi-service/internal/repository"
+)
+
+// AturanService manages one kind of admin-configurable rule. Only admins can
+// change rules; new rules start inactive and activating one replaces the
+// rule in effect.
+type AturanService[T any] interface {
+	Create(userID uint, aturan *T) (*T, error)
+	GetByID(id uint) (*T, error)
+	GetAll() ([]T, error)
+	GetActive() (*T, error)
+	Update(id uint, userID uint, payload *T) (*T, error)
+	Delete(id uint, userID uint) error
+	SetActive(id uint, userID uint, isActive bool) error
+}
+
+// aturanModel is a pointer to a rule model
+type aturanModel[T any] interface {
+	*T
+	SetCreatedBy(userID uint)
+}
+
+// aturanJenis holds what differs between the kinds of rules
+type aturanJenis[T any] struct {
+	nama     string // Kind of rule in messages, e.g. "aturan denda"
+	validate func(aturan *T) error
+	salin    func(dst, src *T) // Copies the fields an admin sets
+}
+
+type aturanService[T any, P aturanModel[T]] struct {
+	repo     repository.AturanRepository[T]
+	userRepo *repository.UserRepository
+	jenis    aturanJenis[T]
+}
+
+func newAturanService[T any, P aturanModel[T]](repo repository.AturanRepository[T], userRepo *repository.UserRepository, jenis aturanJenis[T]) AturanService[T] {
+	return &aturanService[T, P]{
+		repo:     repo,
+		userRepo: userRepo,
+		jenis:    jenis,
+	}
+}
+
+func (s *aturanService[T, P]) Create(userID uint, payload *T) (*T, error) {
+	if err := s.checkAdmin(userID, "only admin can create "+s.jenis.nama); err != nil {
+		return nil, err
+	}
+
+	if err := s.jenis.validate(payload); err != nil {
+		return nil, err
+	}
+
+	// New rules start inactive; activating one replaces the current rule
+	aturan := new(T)
+	s.jenis.salin(aturan, payload)
+	P(aturan).SetCreatedBy(userID)
+	if err := s.repo.Create(aturan); err != nil {
+		return nil, err
+	}
+
+	return aturan, nil
+}
+
+func (s *aturanService[T, P]) GetByID(id uint) (*T, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *aturanService[T, P]) GetAll() ([]T, error) {
+	return s.repo.GetAll()
+}
+
+func (s *aturanService[T, P]) GetActive() (*T, error) {
+	return s.repo.GetActive()
+}
+
+func (s *aturanService[T, P]) Update(id uint, userID uint, payload *T) (*T, error) {
+	if err := s.checkAdmin(userID, "only admin can update "+s.jenis.nama); err != nil {
+		return nil, err
+	}
+
+	if err := s.jenis.validate(payload); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.jenis.salin(existing, payload)
+	if err := s.repo.Update(existing); err != nil {
+		return nil, err
+	}
+
+	return existing, nil
+}
+
+func (s *aturanService[T, P]) Delete(id uint, userID uint) error {
+	if err := s.checkAdmin(userID, "only admin can delete "+s.jenis.nama); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(id)
+}
+
+func (s *aturanService[T, P]) SetActive(id uint, userID uint, isActive bool) error {
+	if err := s.checkAdmin(userID, "only admin can modify "+s.jenis.nama+" status"); err != nil {
+		return err
+	}
+
+	return s.repo.SetActive(id, isActive)
+}
+
+// checkAdmin verifies that the user is admin or super_admin
+func (s *aturanService[T, P]) checkAdmin(userID uint, message string) error {
+	user, err := s.userRepo.FindByIDWithRole(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if user.Role.Name != "admin" && user.Role.Name != "super_admin" {
+		return errors.New(message)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"errors"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+)
+
+// NewAturanSimpananPokokService manages the simpanan pokok amount rules
+func NewAturanSimpananPokokService(repo repository.AturanRepository[model.AturanSimpananPokok], userRepo *repository.UserRepository) AturanService[model.AturanSimpananPokok] {
+	return newAturanService(repo, userRepo, aturanJenis[model.AturanSimpananPokok]{
+		nama:     "aturan simpanan pokok",
+		validate: validateAturanSimpananPokok,
+		salin: func(dst, src *model.AturanSimpananPokok) {
+			dst.Nama = src.Nama
+			dst.Nominal = src.Nominal
+			dst.Deskripsi = src.Deskripsi
+		},
+	})
+}
+
+// validateAturanSimpananPokok checks the rule values
+func validateAturanSimpananPokok(aturan *model.AturanSimpananPokok) error {
+	if aturan.Nominal <= 0 {
+		return errors.New("nominal must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"errors"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+)
+
+// NewAturanSimpananWajibService manages the monthly simpanan wajib rules
+func NewAturanSimpananWajibService(repo repository.AturanRepository[model.AturanSimpananWajib], userRepo *repository.UserRepository) AturanService[model.AturanSimpananWajib] {
+	return newAturanService(repo, userRepo, aturanJenis[model.AturanSimpananWajib]{
+		nama:     "aturan simpanan wajib",
+		validate: validateAturanSimpananWajib,
+		salin: func(dst, src *model.AturanSimpananWajib) {
+			dst.Nama = src.Nama
+			dst.Nominal = src.Nominal
+			dst.TanggalJatuhTempo = src.TanggalJatuhTempo
+			dst.Deskripsi = src.Deskripsi
+		},
+	})
+}
+
+// validateAturanSimpananWajib checks the rule values
+func validateAturanSimpananWajib(aturan *model.AturanSimpananWajib) error {
+	if aturan.Nominal <= 0 {
+		return errors.New("nominal must be positive")
+	}
+	// Every month has a 28th, so the due date never rolls into the next month
+	if aturan.TanggalJatuhTempo < 1 || aturan.TanggalJatuhTempo > 28 {
+		return errors.New("tanggal jatuh tempo must be between 1 and 28")
+	}
+	return nil
+}
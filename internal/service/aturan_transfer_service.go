@@ -0,0 +1,37 @@
+package service
+
+import (
+	"errors"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+)
+
+// NewAturanTransferService manages the sukarela transfer limit rules
+func NewAturanTransferService(repo repository.AturanRepository[model.AturanTransfer], userRepo *repository.UserRepository) AturanService[model.AturanTransfer] {
+	return newAturanService(repo, userRepo, aturanJenis[model.AturanTransfer]{
+		nama:     "aturan transfer",
+		validate: validateAturanTransfer,
+		salin: func(dst, src *model.AturanTransfer) {
+			dst.Nama = src.Nama
+			dst.MinimalTransfer = src.MinimalTransfer
+			dst.BatasPerTransaksi = src.BatasPerTransaksi
+			dst.BatasHarian = src.BatasHarian
+			dst.BatasPersetujuan = src.BatasPersetujuan
+			dst.Deskripsi = src.Deskripsi
+		},
+	})
+}
+
+// validateAturanTransfer checks the rule values
+func validateAturanTransfer(aturan *model.AturanTransfer) error {
+	if aturan.MinimalTransfer < 0 || aturan.BatasPerTransaksi < 0 || aturan.BatasHarian < 0 || aturan.BatasPersetujuan < 0 {
+		return errors.New("aturan transfer amounts must not be negative")
+	}
+	if aturan.BatasPerTransaksi > 0 && aturan.MinimalTransfer > aturan.BatasPerTransaksi {
+		return errors.New("minimal transfer must not exceed batas per transaksi")
+	}
+	if aturan.BatasHarian > 0 && aturan.BatasPerTransaksi > aturan.BatasHarian {
+		return errors.New("batas per transaksi must not exceed batas harian")
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"koperasi-service/internal/model"
 	"koperasi-service/internal/repository"
+	"koperasi-service/pkg/money"
 	"time"
 )
 
@@ -177,7 +178,7 @@ func (s *auditTrailService) GetAuditSummary(userID uint, startDate, endDate time
 
 // TransactionHistoryService handles transaction history operations
 type TransactionHistoryService interface {
-	CreateTransactionRecord(userID uint, transactionType, referenceTable string, referenceID uint, amount, balanceBefore, balanceAfter float64, status, description, metadata string) error
+	CreateTransactionRecord(userID uint, transactionType, referenceTable string, referenceID uint, amount, balanceBefore, balanceAfter money.Money, status, description, metadata string) error
 	GetTransactionHistory(userID uint, filters repository.TransactionHistoryFilters) ([]model.TransactionHistory, int64, error)
 	GetTransactionByID(userID uint, id uint) (*model.TransactionHistory, error)
 	GetUserTransactions(userID uint, targetUserID uint, startDate, endDate time.Time) ([]model.TransactionHistory, error)
@@ -198,7 +199,7 @@ func NewTransactionHistoryService(transactionRepo repository.TransactionHistoryR
 	}
 }
 
-func (s *transactionHistoryService) CreateTransactionRecord(userID uint, transactionType, referenceTable string, referenceID uint, amount, balanceBefore, balanceAfter float64, status, description, metadata string) error {
+func (s *transactionHistoryService) CreateTransactionRecord(userID uint, transactionType, referenceTable string, referenceID uint, amount, balanceBefore, balanceAfter money.Money, status, description, metadata string) error {
 	transaction := &model.TransactionHistory{
 		UserID:          userID,
 		TransactionType: transactionType,
@@ -316,9 +317,9 @@ func (s *transactionHistoryService) GenerateFinancialReport(userID uint, reportT
 	}
 
 	// Analyze transaction patterns
-	typeBreakdown := make(map[string]float64)
+	typeBreakdown := make(map[string]money.Money)
 	statusBreakdown := make(map[string]int)
-	monthlyBreakdown := make(map[string]float64)
+	monthlyBreakdown := make(map[string]money.Money)
 
 	for _, txn := range transactions {
 		typeBreakdown[txn.TransactionType] += txn.Amount
@@ -11,12 +11,12 @@ import (
 )
 
 type AuthService struct {
-	repo         *repository.UserRepository
-	simpananRepo *repository.SimpananRepository
+	repo *repository.UserRepository
+	uow  *repository.UnitOfWork
 }
 
-func NewAuthService(repo *repository.UserRepository, simpananRepo *repository.SimpananRepository) *AuthService {
-	return &AuthService{repo: repo, simpananRepo: simpananRepo}
+func NewAuthService(repo *repository.UserRepository, uow *repository.UnitOfWork) *AuthService {
+	return &AuthService{repo: repo, uow: uow}
 }
 
 func (s *AuthService) Register(user *model.User) error {
@@ -26,19 +26,18 @@ func (s *AuthService) Register(user *model.User) error {
 	}
 	user.Password = string(hashed)
 
-	// Create user first
-	if err := s.repo.Create(user); err != nil {
-		return err
-	}
-
-	// Initialize user wallets (3 types)
-	if err := s.simpananRepo.InitializeUserWallets(user.ID); err != nil {
-		// If wallet initialization fails, we should rollback user creation
-		// For now, we'll just return the error
-		return err
-	}
-
-	return nil
+	// Create the user and its wallets together; a wallet failure rolls back the user
+	return s.uow.Do(func(repos *repository.Repositories) error {
+		if err := setStatusKeanggotaanAwal(repos, user); err != nil {
+			return err
+		}
+		if err := repos.Users.Create(user); err != nil {
+			return err
+		}
+
+		// Initialize user wallets (one per active jenis simpanan)
+		return repos.Simpanan.InitializeUserWallets(user.ID)
+	})
 }
 
 func (s *AuthService) Login(email, password, jwtSecret string) (string, error) {
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+	"koperasi-service/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AutoDebetInfo is the auto-debit mandate of a loan with its attempts
+type AutoDebetInfo struct {
+	Mandat  *model.MandatAutoDebet `json:"mandat"`
+	Riwayat []model.AutoDebet      `json:"riwayat"`
+}
+
+// AktifkanAutoDebet opts a loan into auto-debit from the borrower's sukarela
+// wallet (same access as Get). A revoked mandate is activated again.
+func (s *PinjamanService) AktifkanAutoDebet(requestorID uint, requestorRole string, id uint) (*model.MandatAutoDebet, error) {
+	p, err := s.Get(requestorID, requestorRole, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *model.MandatAutoDebet
+	err = s.uow.Do(func(repos *repository.Repositories) error {
+		p, err = repos.Pinjaman.GetByIDForUpdate(p.ID)
+		if err != nil {
+			return err
+		}
+		switch p.Status {
+		case model.StatusPinjamanLunas, model.StatusPinjamanDitolak:
+			return errors.New("only running pinjaman can use auto debet")
+		}
+		wallet, err := repos.Simpanan.GetWalletByUserAndType(p.UserID, "sukarela")
+		if err != nil {
+			return err
+		}
+
+		m, err := repos.AutoDebet.GetMandatByPinjamanForUpdate(p.ID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			m = &model.MandatAutoDebet{PinjamanID: p.ID, UserID: p.UserID}
+		} else if err != nil {
+			return err
+		}
+		m.SimpananID = wallet.ID
+		m.IsActive = true
+		m.CreatedBy = requestorID
+		m.DicabutOleh = nil
+		m.DicabutPada = nil
+		if err := repos.AutoDebet.SaveMandat(m); err != nil {
+			return err
+		}
+		result = m
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CabutAutoDebet revokes the auto-debit mandate of a loan (same access as Get)
+func (s *PinjamanService) CabutAutoDebet(requestorID uint, requestorRole string, id uint) (*model.MandatAutoDebet, error) {
+	p, err := s.Get(requestorID, requestorRole, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *model.MandatAutoDebet
+	err = s.uow.Do(func(repos *repository.Repositories) error {
+		m, err := repos.AutoDebet.GetMandatByPinjamanForUpdate(p.ID)
+		if err != nil {
+			return err
+		}
+		if m.IsActive {
+			now := time.Now()
+			m.IsActive = false
+			m.DicabutOleh = &requestorID
+			m.DicabutPada = &now
+			if err := repos.AutoDebet.SaveMandat(m); err != nil {
+				return err
+			}
+		}
+		result = m
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetAutoDebet returns the mandate of a loan and its attempts (same access as Get)
+func (s *PinjamanService) GetAutoDebet(requestorID uint, requestorRole string, id uint) (*AutoDebetInfo, error) {
+	p, err := s.Get(requestorID, requestorRole, id)
+	if err != nil {
+		return nil, err
+	}
+	m, err := s.autoDebetRepo.GetMandatByPinjaman(p.ID)
+	if err != nil {
+		return nil, err
+	}
+	riwayat, err := s.autoDebetRepo.GetAttemptsByPinjaman(p.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &AutoDebetInfo{Mandat: m, Riwayat: riwayat}, nil
+}
+
+// JalankanAutoDebet debits every loan with an active mandate that has an
+// installment falling due by now. Each loan is handled in its own transaction
+// so one failure does not stop the run; it returns the number of successful
+// and failed debits.
+func (s *PinjamanService) JalankanAutoDebet(now time.Time) (int, int, error) {
+	aturanDenda, err := s.aturanDendaRepo.GetActive()
+	if err != nil {
+		return 0, 0, err
+	}
+	ids, err := s.autoDebetRepo.GetPinjamanIDsMandatAktif(model.StatusPinjamanDicairkan, model.StatusPinjamanMacet)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	berhasil, gagal := 0, 0
+	var errs []error
+	for _, id := range ids {
+		status, err := s.autoDebet(id, aturanDenda, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pinjaman %d: %w", id, err))
+			continue
+		}
+		switch status {
+		case model.AutoDebetBerhasil:
+			berhasil++
+		case model.AutoDebetGagal:
+			gagal++
+		}
+	}
+	return berhasil, gagal, errors.Join(errs...)
+}
+
+// RunAutoDebet runs the auto-debit job on demand (super admin only)
+func (s *PinjamanService) RunAutoDebet(requestorRole string) (int, int, error) {
+	if requestorRole != "super_admin" {
+		return 0, 0, errors.New("forbidden")
+	}
+	return s.JalankanAutoDebet(time.Now())
+}
+
+// autoDebet makes the attempt of one loan and returns its status, or "" when
+// nothing was attempted. An attempt is made once for each schedule row that
+// falls due and covers everything due at that time. The wallet debit, the
+// verified Angsuran and its allocation are stored together; when the balance
+// does not cover the bill nothing is debited, the due rows become terlambat
+// and the member is notified.
+func (s *PinjamanService) autoDebet(id uint, aturanDenda *model.AturanDenda, now time.Time) (string, error) {
+	status := ""
+	err := s.uow.Do(func(repos *repository.Repositories) error {
+		p, err := repos.Pinjaman.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if p.Status != model.StatusPinjamanDicairkan && p.Status != model.StatusPinjamanMacet {
+			return nil
+		}
+		m, err := repos.AutoDebet.GetMandatByPinjamanForUpdate(p.ID)
+		if err != nil {
+			return err
+		}
+		if !m.IsActive {
+			return nil
+		}
+
+		jadwal, err := ensureJadwal(repos, p)
+		if err != nil {
+			return err
+		}
+		var due []int
+		var dueIDs []uint
+		for i := range jadwal {
+			if jadwal[i].SisaTagihan() > 0 && !jadwal[i].TanggalJatuhTempo.After(now) {
+				due = append(due, i)
+				dueIDs = append(dueIDs, jadwal[i].ID)
+			}
+		}
+		attempted, err := repos.AutoDebet.GetAttemptedJadwalIDs(dueIDs)
+		if err != nil {
+			return err
+		}
+		// The latest row that fell due without an attempt triggers one
+		var pemicu *model.JadwalAngsuran
+		for k := len(due) - 1; k >= 0; k-- {
+			if row := &jadwal[due[k]]; !attempted[row.ID] {
+				pemicu = row
+				break
+			}
+		}
+		if pemicu == nil {
+			return nil
+		}
+
+		changed := make(map[int]bool)
+		for _, i := range terapkanDenda(jadwal, aturanDenda, now) {
+			changed[i] = true
+		}
+		var tagihan money.Money
+		for _, i := range due {
+			tagihan += jadwal[i].SisaTagihan()
+		}
+
+		wallet, err := repos.Simpanan.GetWalletByIDForUpdate(m.SimpananID)
+		if err != nil {
+			return err
+		}
+		attempt := &model.AutoDebet{
+			MandatID:          m.ID,
+			PinjamanID:        p.ID,
+			JadwalAngsuranID:  pemicu.ID,
+			TanggalJatuhTempo: pemicu.TanggalJatuhTempo,
+			Tagihan:           tagihan,
+			Saldo:             wallet.SaldoTersedia(),
+			TanggalProses:     now,
+		}
+
+		if attempt.Saldo < tagihan {
+			for _, i := range due {
+				if jadwal[i].Status == model.JadwalBelumBayar {
+					jadwal[i].Status = model.JadwalTerlambat
+					changed[i] = true
+				}
+			}
+			if err := updateJadwalRows(repos, jadwal, changed); err != nil {
+				return err
+			}
+			attempt.Status = model.AutoDebetGagal
+			if err := repos.AutoDebet.CreateAttempt(attempt); err != nil {
+				return err
+			}
+			if err := repos.Notifikasi.Create(&model.Notifikasi{
+				UserID:         p.UserID,
+				Jenis:          model.NotifikasiAutoDebetGagal,
+				Judul:          "Auto debet angsuran gagal",
+				Pesan:          fmt.Sprintf("Saldo simpanan sukarela (Rp %s) tidak mencukupi tagihan angsuran pinjaman %s sebesar Rp %s. Angsuran tercatat terlambat, silakan lakukan pembayaran.", attempt.Saldo, p.KodePinjaman, tagihan),
+				ReferenceTable: "auto_debet",
+				ReferenceID:    attempt.ID,
+			}); err != nil {
+				return err
+			}
+			status = model.AutoDebetGagal
+			return nil
+		}
+
+		res := alokasikan(jadwal, tagihan, now, false, now)
+		for _, i := range res.Changed {
+			changed[i] = true
+		}
+		if err := updateJadwalRows(repos, jadwal, changed); err != nil {
+			return err
+		}
+		angsuranKe, err := repos.Angsuran.GetNextAngsuranKe(p.ID)
+		if err != nil {
+			return err
+		}
+		a := &model.Angsuran{
+			PinjamanID:        p.ID,
+			AngsuranKe:        angsuranKe,
+			TanggalBayar:      now,
+			Pokok:             res.Pokok,
+			Bunga:             res.Bunga,
+			Denda:             res.Denda,
+			TotalBayar:        tagihan,
+			UserID:            p.UserID,
+			Status:            "verified",
+			Jenis:             model.JenisAngsuranRutin,
+			TanggalVerifikasi: &now,
+		}
+		if err := repos.Angsuran.Create(a); err != nil {
+			return err
+		}
+		for i := range res.Rows {
+			res.Rows[i].AngsuranID = a.ID
+		}
+		if err := repos.Angsuran.CreateAlokasi(res.Rows); err != nil {
+			return err
+		}
+		journal, err := postWalletMovement(repos, wallet, walletMovement{
+			EntryType:      model.LedgerAutoDebet,
+			CounterAccount: model.AkunAngsuran,
+			Amount:         -tagihan,
+			ReferenceTable: "angsuran",
+			ReferenceID:    a.ID,
+			Description:    fmt.Sprintf("Auto debet angsuran pinjaman %s", p.KodePinjaman),
+			PostedAt:       now,
+		})
+		if err != nil {
+			return err
+		}
+
+		p.SisaAngsuran = sisaAngsuranTerbuka(jadwal)
+		if sisaPokokPinjaman(jadwal) == 0 {
+			if err := markLunas(p, now); err != nil {
+				return err
+			}
+			if err := repos.Agunan.KembalikanByPinjaman(p.ID, nil, now); err != nil {
+				return err
+			}
+		}
+		if err := repos.Pinjaman.Update(p); err != nil {
+			return err
+		}
+
+		attempt.Status = model.AutoDebetBerhasil
+		attempt.AngsuranID = &a.ID
+		attempt.LedgerJournalID = &journal.ID
+		if err := repos.AutoDebet.CreateAttempt(attempt); err != nil {
+			return err
+		}
+		status = model.AutoDebetBerhasil
+		return nil
+	})
+	return status, err
+}
+
+// updateJadwalRows stores the schedule rows whose index is in changed
+func updateJadwalRows(repos *repository.Repositories, jadwal []model.JadwalAngsuran, changed map[int]bool) error {
+	for i := range jadwal {
+		if !changed[i] {
+			continue
+		}
+		if err := repos.Jadwal.Update(&jadwal[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
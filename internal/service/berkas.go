@@ -0,0 +1,343 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png" // Registers the PNG decoder for image.Decode
+	"io"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+	"koperasi-service/internal/storage"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Limits on uploaded files
+const (
+	DefaultMaksUkuranBerkas = 5 << 20            // Bytes, when none is configured
+	maksPikselBerkas        = 40_000_000         // Larger images are refused before decoding
+	sisiThumbnail           = 320                // Longest side of a thumbnail, pixels
+	umurBerkasLepas         = 7 * 24 * time.Hour // Unattached uploads are removed after this
+)
+
+// tipeBerkas maps the accepted content types, detected from the content
+// itself, to the extension of the stored object
+var tipeBerkas = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+// errBerkasTerlampir is returned when deleting an upload that is attached to a record
+var errBerkasTerlampir = errors.New("berkas is attached and cannot be deleted")
+
+// BerkasService stores proof-of-transfer uploads and controls who can read them
+type BerkasService struct {
+	repo       *repository.BerkasRepository
+	uow        *repository.UnitOfWork
+	store      storage.Storage
+	maksUkuran int64
+}
+
+// NewBerkasService creates a new service instance. maksUkuran is the largest
+// accepted file in bytes; 0 uses DefaultMaksUkuranBerkas.
+func NewBerkasService(repo *repository.BerkasRepository, uow *repository.UnitOfWork, store storage.Storage, maksUkuran int64) *BerkasService {
+	if maksUkuran <= 0 {
+		maksUkuran = DefaultMaksUkuranBerkas
+	}
+	return &BerkasService{repo: repo, uow: uow, store: store, maksUkuran: maksUkuran}
+}
+
+// MaksUkuran returns the largest accepted file in bytes
+func (s *BerkasService) MaksUkuran() int64 {
+	return s.maksUkuran
+}
+
+// Unggah stores an uploaded file. The type is detected from the content, not
+// the name; JPEG and PNG images get a JPEG thumbnail. Content already stored
+// is not written again.
+func (s *BerkasService) Unggah(userID uint, namaAsli string, r io.Reader) (*model.Berkas, error) {
+	data, err := io.ReadAll(io.LimitReader(r, s.maksUkuran+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("file is empty")
+	}
+	if int64(len(data)) > s.maksUkuran {
+		return nil, fmt.Errorf("file must not be larger than %d bytes", s.maksUkuran)
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := tipeBerkas[contentType]
+	if !ok {
+		return nil, fmt.Errorf("file type %s is not allowed, use JPEG, PNG or PDF", contentType)
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	b := &model.Berkas{
+		Hash:         hash,
+		StorageKey:   "bukti/" + hash[:2] + "/" + hash + ext,
+		ContentType:  contentType,
+		Ukuran:       int64(len(data)),
+		NamaAsli:     filepath.Base(namaAsli),
+		DiunggahOleh: userID,
+	}
+
+	var thumbnail []byte
+	if contentType != "application/pdf" {
+		img, err := bacaGambar(data)
+		if err != nil {
+			return nil, err
+		}
+		b.Lebar, b.Tinggi = img.Bounds().Dx(), img.Bounds().Dy()
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, perkecil(img, sisiThumbnail), &jpeg.Options{Quality: 80}); err != nil {
+			return nil, err
+		}
+		thumbnail = buf.Bytes()
+		b.ThumbnailKey = "thumbnail/" + hash[:2] + "/" + hash + ".jpg"
+		b.UkuranThumbnail = int64(len(thumbnail))
+	}
+
+	// The hash lock keeps a concurrent delete of the same content from
+	// removing the objects between the existence check and the new record
+	err = s.uow.Do(func(repos *repository.Repositories) error {
+		if err := repos.Berkas.LockHash(hash); err != nil {
+			return err
+		}
+		if err := s.simpan(b.StorageKey, data, contentType); err != nil {
+			return err
+		}
+		if thumbnail != nil {
+			if err := s.simpan(b.ThumbnailKey, thumbnail, "image/jpeg"); err != nil {
+				return err
+			}
+		}
+		return repos.Berkas.Create(b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// simpan writes an object unless content-addressing says it is already there
+func (s *BerkasService) simpan(key string, data []byte, contentType string) error {
+	ada, err := s.store.Exists(key)
+	if err != nil || ada {
+		return err
+	}
+	return s.store.Put(key, data, contentType)
+}
+
+// bacaGambar decodes an image, refusing ones whose header promises more
+// pixels than maksPikselBerkas
+func bacaGambar(data []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("image file is damaged")
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > maksPikselBerkas {
+		return nil, errors.New("image dimensions are too large")
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("image file is damaged")
+	}
+	return img, nil
+}
+
+// perkecil scales img down so its longest side is at most sisi, averaging the
+// source pixels under each target pixel. Transparent areas become white.
+func perkecil(img image.Image, sisi int) image.Image {
+	src := img.Bounds()
+	w, h := src.Dx(), src.Dy()
+	tw, th := w, h
+	if w >= h && w > sisi {
+		tw, th = sisi, max(1, h*sisi/w)
+	} else if h > w && h > sisi {
+		tw, th = max(1, w*sisi/h), sisi
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := src.Min.Y+y*h/th, src.Min.Y+(y+1)*h/th
+		for x := 0; x < tw; x++ {
+			x0, x1 := src.Min.X+x*w/tw, src.Min.X+(x+1)*w/tw
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, bl, a, n = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			// Premultiplied colours over a white background
+			putih := 0xffff - a/n
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r/n + putih),
+				G: uint16(g/n + putih),
+				B: uint16(bl/n + putih),
+				A: 0xffff,
+			})
+		}
+	}
+	return dst
+}
+
+// bolehLihatBerkas reports whether a user may see an uploaded file: admins,
+// the uploader and the member of the record it is attached to
+func bolehLihatBerkas(b *model.Berkas, requestorID uint, requestorRole string) bool {
+	if requestorRole == "super_admin" || requestorRole == "admin" {
+		return true
+	}
+	return b.DiunggahOleh == requestorID || (b.PemilikID != nil && *b.PemilikID == requestorID)
+}
+
+// Get returns the details of an uploaded file
+func (s *BerkasService) Get(requestorID uint, requestorRole string, id uint) (*model.Berkas, error) {
+	b, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("berkas not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !bolehLihatBerkas(b, requestorID, requestorRole) {
+		return nil, errors.New("forbidden")
+	}
+	return b, nil
+}
+
+// Buka opens an uploaded file, or its thumbnail, for download. The caller
+// closes the returned reader; the size and content type come from the record.
+func (s *BerkasService) Buka(requestorID uint, requestorRole string, id uint, thumbnail bool) (*model.Berkas, io.ReadCloser, error) {
+	b, err := s.Get(requestorID, requestorRole, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	key := b.StorageKey
+	if thumbnail {
+		if b.ThumbnailKey == "" {
+			return nil, nil, errors.New("berkas has no thumbnail")
+		}
+		key = b.ThumbnailKey
+	}
+	rc, err := s.store.Get(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, rc, nil
+}
+
+// ListMilikSaya returns the files the user uploaded; with lepasOnly only those
+// not attached to a record yet
+func (s *BerkasService) ListMilikSaya(userID uint, lepasOnly bool) ([]model.Berkas, error) {
+	return s.repo.ListByUploader(userID, lepasOnly)
+}
+
+// Hapus deletes an upload that is not attached to a record (the uploader or
+// an admin). The stored content goes too unless another upload shares it.
+func (s *BerkasService) Hapus(requestorID uint, requestorRole string, id uint) error {
+	b, err := s.Get(requestorID, requestorRole, id)
+	if err != nil {
+		return err
+	}
+	if b.DiunggahOleh != requestorID && requestorRole != "super_admin" && requestorRole != "admin" {
+		return errors.New("forbidden")
+	}
+	if b.ReferenceID != nil {
+		return errBerkasTerlampir
+	}
+	return s.hapus(b.ID, b.Hash)
+}
+
+// hapus deletes an upload unless it was attached in the meantime, and its
+// objects when no other upload shares the content. The hash lock keeps a
+// concurrent upload of the same content from losing its objects.
+func (s *BerkasService) hapus(id uint, hash string) error {
+	return s.uow.Do(func(repos *repository.Repositories) error {
+		if err := repos.Berkas.LockHash(hash); err != nil {
+			return err
+		}
+		b, err := repos.Berkas.GetByIDForUpdate(id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("berkas not found")
+		}
+		if err != nil {
+			return err
+		}
+		if b.ReferenceID != nil {
+			return errBerkasTerlampir
+		}
+		if err := repos.Berkas.Delete(b.ID); err != nil {
+			return err
+		}
+		sisa, err := repos.Berkas.CountByHash(b.Hash)
+		if err != nil || sisa > 0 {
+			return err
+		}
+		if err := s.store.Delete(b.StorageKey); err != nil {
+			return err
+		}
+		if b.ThumbnailKey != "" {
+			return s.store.Delete(b.ThumbnailKey)
+		}
+		return nil
+	})
+}
+
+// HapusBerkasLepas removes uploads that were never attached to a record
+// within umurBerkasLepas. Safe to run every day.
+func (s *BerkasService) HapusBerkasLepas(now time.Time) (int, error) {
+	list, err := s.repo.ListLepasBefore(now.Add(-umurBerkasLepas))
+	if err != nil {
+		return 0, err
+	}
+	var errs []error
+	dihapus := 0
+	for i := range list {
+		err := s.hapus(list[i].ID, list[i].Hash)
+		if errors.Is(err, errBerkasTerlampir) {
+			continue // Attached since it was listed
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("berkas %d: %w", list[i].ID, err))
+			continue
+		}
+		dihapus++
+	}
+	return dihapus, errors.Join(errs...)
+}
+
+// lampirkanBukti attaches an uploaded file to a record inside a unit of work.
+// Only files the uploader owns and that are not attached yet can be used.
+func lampirkanBukti(repos *repository.Repositories, berkasID, uploaderID uint, table string, referenceID, pemilikID uint) error {
+	b, err := repos.Berkas.GetByIDForUpdate(berkasID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("bukti transfer not found")
+	}
+	if err != nil {
+		return err
+	}
+	if b.DiunggahOleh != uploaderID {
+		return errors.New("bukti transfer was uploaded by another user")
+	}
+	if b.ReferenceID != nil {
+		return errors.New("bukti transfer is already attached to another record")
+	}
+	b.ReferenceTable = table
+	b.ReferenceID = &referenceID
+	b.PemilikID = &pemilikID
+	return repos.Berkas.Update(b)
+}
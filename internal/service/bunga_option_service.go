@@ -4,16 +4,26 @@ import (
 	"errors"
 	"koperasi-service/internal/model"
 	"koperasi-service/internal/repository"
+	"time"
 )
 
 type BungaOptionService interface {
-	CreateBungaOption(userID uint, nama string, persen float64, deskripsi string) (*model.BungaOption, error)
+	CreateBungaOption(userID uint, nama string, persen float64, metodeBunga, deskripsi string, periode VersiPeriode) (*model.BungaOption, error)
 	GetBungaOptionByID(id uint) (*model.BungaOption, error)
 	GetAllBungaOptions() ([]model.BungaOption, error)
 	GetActiveBungaOptions() ([]model.BungaOption, error)
-	UpdateBungaOption(id uint, userID uint, nama string, persen float64, deskripsi string) error
+	UpdateBungaOption(id uint, userID uint, nama string, persen float64, metodeBunga, deskripsi string, periode VersiPeriode) error
 	DeleteBungaOption(id uint, userID uint) error
 	SetBungaOptionActive(id uint, userID uint, isActive bool) error
+	GetBungaOptionVersi(id uint) ([]model.BungaOptionVersi, error)
+	BackfillVersi() (int, error)
+}
+
+// VersiPeriode is the period a new rate version is in effect. A nil From means
+// now, a nil To leaves the version open-ended.
+type VersiPeriode struct {
+	From *time.Time
+	To   *time.Time
 }
 
 type bungaOptionService struct {
@@ -28,7 +38,7 @@ func NewBungaOptionService(bungaOptionRepo repository.BungaOptionRepository, use
 	}
 }
 
-func (s *bungaOptionService) CreateBungaOption(userID uint, nama string, persen float64, deskripsi string) (*model.BungaOption, error) {
+func (s *bungaOptionService) CreateBungaOption(userID uint, nama string, persen float64, metodeBunga, deskripsi string, periode VersiPeriode) (*model.BungaOption, error) {
 	// Check if user is admin or super_admin
 	user, err := s.userRepo.FindByIDWithRole(userID)
 	if err != nil {
@@ -44,15 +54,36 @@ func (s *bungaOptionService) CreateBungaOption(userID uint, nama string, persen
 		return nil, errors.New("percentage must be greater than 0")
 	}
 
+	if metodeBunga == "" {
+		metodeBunga = model.MetodeBungaFlat
+	}
+	if !model.ValidMetodeBunga[metodeBunga] {
+		return nil, errors.New("invalid metode bunga")
+	}
+
+	from, err := periode.mulai(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
 	bungaOption := &model.BungaOption{
-		Nama:      nama,
-		Persen:    persen,
-		Deskripsi: deskripsi,
-		IsActive:  true,
-		CreatedBy: userID,
+		Nama:        nama,
+		Persen:      persen,
+		MetodeBunga: metodeBunga,
+		Deskripsi:   deskripsi,
+		IsActive:    true,
+		CreatedBy:   userID,
+	}
+	versi := &model.BungaOptionVersi{
+		Versi:         1,
+		Persen:        persen,
+		MetodeBunga:   metodeBunga,
+		EffectiveFrom: from,
+		EffectiveTo:   periode.To,
+		CreatedBy:     userID,
 	}
 
-	err = s.bungaOptionRepo.Create(bungaOption)
+	err = s.bungaOptionRepo.CreateWithVersi(bungaOption, versi)
 	if err != nil {
 		return nil, err
 	}
@@ -61,18 +92,50 @@ func (s *bungaOptionService) CreateBungaOption(userID uint, nama string, persen
 }
 
 func (s *bungaOptionService) GetBungaOptionByID(id uint) (*model.BungaOption, error) {
-	return s.bungaOptionRepo.GetByID(id)
+	bungaOption, err := s.bungaOptionRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	s.isiVersiBerlaku(bungaOption, time.Now())
+	return bungaOption, nil
 }
 
 func (s *bungaOptionService) GetAllBungaOptions() ([]model.BungaOption, error) {
-	return s.bungaOptionRepo.GetAll()
+	return s.isiVersiBerlakuList(s.bungaOptionRepo.GetAll())
 }
 
 func (s *bungaOptionService) GetActiveBungaOptions() ([]model.BungaOption, error) {
-	return s.bungaOptionRepo.GetActiveOptions()
+	return s.isiVersiBerlakuList(s.bungaOptionRepo.GetActiveOptions())
+}
+
+// isiVersiBerlaku shows the rate and method of the version in effect at now,
+// so a version scheduled for later takes over on its start date. Without a
+// version in effect the stored values are kept.
+func (s *bungaOptionService) isiVersiBerlaku(bungaOption *model.BungaOption, now time.Time) {
+	versi, err := s.bungaOptionRepo.GetVersiAt(bungaOption.ID, now)
+	if err != nil {
+		return
+	}
+	bungaOption.Persen = versi.Persen
+	bungaOption.MetodeBunga = versi.MetodeBunga
+}
+
+func (s *bungaOptionService) isiVersiBerlakuList(list []model.BungaOption, err error) ([]model.BungaOption, error) {
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i := range list {
+		s.isiVersiBerlaku(&list[i], now)
+	}
+	return list, nil
 }
 
-func (s *bungaOptionService) UpdateBungaOption(id uint, userID uint, nama string, persen float64, deskripsi string) error {
+// UpdateBungaOption changes the name and description in place. A changed rate
+// or method adds a new version from periode.From instead of overwriting the
+// one loans were created with; the option itself keeps showing the version in
+// effect now.
+func (s *bungaOptionService) UpdateBungaOption(id uint, userID uint, nama string, persen float64, metodeBunga, deskripsi string, periode VersiPeriode) error {
 	// Check if user is admin or super_admin
 	user, err := s.userRepo.FindByIDWithRole(userID)
 	if err != nil {
@@ -88,11 +151,50 @@ func (s *bungaOptionService) UpdateBungaOption(id uint, userID uint, nama string
 		return errors.New("percentage must be greater than 0")
 	}
 
+	// Empty keeps the current method
+	if metodeBunga != "" && !model.ValidMetodeBunga[metodeBunga] {
+		return errors.New("invalid metode bunga")
+	}
+
+	latest, err := s.bungaOptionRepo.GetLatestVersi(id)
+	if err != nil {
+		return err
+	}
+	if metodeBunga == "" {
+		metodeBunga = latest.MetodeBunga
+	}
+	if persen != latest.Persen || metodeBunga != latest.MetodeBunga || periode.From != nil || periode.To != nil {
+		from, err := periode.mulai(time.Now())
+		if err != nil {
+			return err
+		}
+		if !from.After(latest.EffectiveFrom) {
+			return errors.New("effective_from must be after the start of the latest version")
+		}
+		versi := &model.BungaOptionVersi{
+			BungaOptionID: id,
+			Versi:         latest.Versi + 1,
+			Persen:        persen,
+			MetodeBunga:   metodeBunga,
+			EffectiveFrom: from,
+			EffectiveTo:   periode.To,
+			CreatedBy:     userID,
+		}
+		if err := s.bungaOptionRepo.AddVersi(versi); err != nil {
+			return err
+		}
+	}
+
+	// Zero values are not written, so without a version in effect now the
+	// stored rate and method stay
 	bungaOption := &model.BungaOption{
 		Nama:      nama,
-		Persen:    persen,
 		Deskripsi: deskripsi,
 	}
+	if berlaku, err := s.bungaOptionRepo.GetVersiAt(id, time.Now()); err == nil {
+		bungaOption.Persen = berlaku.Persen
+		bungaOption.MetodeBunga = berlaku.MetodeBunga
+	}
 
 	return s.bungaOptionRepo.Update(id, bungaOption)
 }
@@ -124,3 +226,37 @@ func (s *bungaOptionService) SetBungaOptionActive(id uint, userID uint, isActive
 
 	return s.bungaOptionRepo.SetActive(id, isActive)
 }
+
+// GetBungaOptionVersi returns the rate history of an option, oldest first
+func (s *bungaOptionService) GetBungaOptionVersi(id uint) ([]model.BungaOptionVersi, error) {
+	if _, err := s.bungaOptionRepo.GetByID(id); err != nil {
+		return nil, err
+	}
+	return s.bungaOptionRepo.GetVersiHistory(id)
+}
+
+// BackfillVersi gives options created before versioning their first version
+func (s *bungaOptionService) BackfillVersi() (int, error) {
+	return s.bungaOptionRepo.BackfillVersi()
+}
+
+// mulai returns the start of the period, now when not given. A version may not
+// start in the past, so rates already offered cannot be rewritten.
+func (p VersiPeriode) mulai(now time.Time) (time.Time, error) {
+	from := now
+	if p.From != nil {
+		from = *p.From
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		if from.Before(today) {
+			return time.Time{}, errors.New("effective_from must not be in the past")
+		}
+		// Today means from now on, loans created earlier today keep their rate
+		if from.Before(now) {
+			from = now
+		}
+	}
+	if p.To != nil && !p.To.After(from) {
+		return time.Time{}, errors.New("effective_to must be after effective_from")
+	}
+	return from, nil
+}
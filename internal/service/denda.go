@@ -0,0 +1,84 @@
+package service
+
+import (
+	"koperasi-service/internal/model"
+	"koperasi-service/pkg/money"
+	"math"
+	"time"
+)
+
+// hariTerlambat returns the number of calendar days tanggal is past jatuhTempo
+func hariTerlambat(jatuhTempo, tanggal time.Time) int {
+	loc := tanggal.Location()
+	due := jatuhTempo.In(loc)
+	from := time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, loc)
+	to := time.Date(tanggal.Year(), tanggal.Month(), tanggal.Day(), 0, 0, 0, 0, loc)
+	days := int(math.Round(to.Sub(from).Hours() / 24))
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+// hitungDenda computes the penalty of one schedule row as of tanggal and the
+// day it is accrued up to. Denda accrues in steps: only the days since
+// DendaDihitungSampai are added, each costing PersenPerHari of the arrears
+// (unpaid bunga + pokok) at that point, so a partial payment lowers the rate
+// from then on without touching what was already charged. Days within the
+// grace period are free and BiayaTetap is added with the first charge. The
+// total is capped at MaksimalDenda.
+func hitungDenda(row *model.JadwalAngsuran, aturan *model.AturanDenda, tanggal time.Time) (int, money.Money, *time.Time) {
+	hari := hariTerlambat(row.TanggalJatuhTempo, tanggal)
+	if aturan == nil || row.Status == model.JadwalLunas {
+		return hari, row.Denda, row.DendaDihitungSampai
+	}
+
+	hariDenda := hari - aturan.MasaTenggangHari
+	if row.DendaDihitungSampai != nil {
+		hariDenda = min(hariDenda, hariTerlambat(*row.DendaDihitungSampai, tanggal))
+	}
+	if hariDenda <= 0 {
+		return hari, row.Denda, row.DendaDihitungSampai
+	}
+
+	sampai := time.Date(tanggal.Year(), tanggal.Month(), tanggal.Day(), 0, 0, 0, 0, tanggal.Location())
+	tunggakan := row.SisaBunga() + row.SisaPokokAngsuran()
+	if tunggakan <= 0 {
+		return hari, row.Denda, &sampai
+	}
+
+	tambah := tunggakan.MulPercentTimes(aturan.PersenPerHari, int64(hariDenda))
+	if row.DendaDihitungSampai == nil {
+		tambah += aturan.BiayaTetap
+	}
+	denda := row.Denda + tambah
+	if aturan.MaksimalDenda > 0 {
+		denda = money.Max(money.Min(denda, aturan.MaksimalDenda), row.Denda)
+	}
+	return hari, denda, &sampai
+}
+
+// terapkanDenda charges the penalties due as of tanggal on the schedule rows
+// and returns the indexes of the rows whose denda changed.
+func terapkanDenda(jadwal []model.JadwalAngsuran, aturan *model.AturanDenda, tanggal time.Time) []int {
+	var changed []int
+	for i := range jadwal {
+		row := &jadwal[i]
+		_, denda, sampai := hitungDenda(row, aturan, tanggal)
+		if denda != row.Denda || sampai != row.DendaDihitungSampai {
+			row.Denda = denda
+			row.DendaDihitungSampai = sampai
+			changed = append(changed, i)
+		}
+	}
+	return changed
+}
+
+// sisaDendaPinjaman returns the unpaid denda of the schedule
+func sisaDendaPinjaman(jadwal []model.JadwalAngsuran) money.Money {
+	var total money.Money
+	for i := range jadwal {
+		total += jadwal[i].SisaDenda()
+	}
+	return total
+}
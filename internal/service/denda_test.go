@@ -0,0 +1,85 @@
+package service
+
+import (
+	"koperasi-service/internal/model"
+	"koperasi-service/pkg/money"
+	"testing"
+	"time"
+)
+
+func TestTerapkanDendaBertahap(t *testing.T) {
+	rp := money.FromRupiah
+	hari := func(d int) time.Time {
+		return time.Date(2024, time.January, d, 15, 0, 0, 0, time.UTC)
+	}
+	type langkah struct {
+		tanggal      time.Time
+		pokokDibayar money.Money // Paid on the row before this step
+		want         money.Money
+		wantBerubah  bool // The row is to be saved, denda or DendaDihitungSampai changed
+	}
+	skenario := []struct {
+		name    string
+		maks    money.Money
+		langkah []langkah
+	}{
+		{
+			name: "accrues only the days since the last charge on the current arrears",
+			langkah: []langkah{
+				{tanggal: hari(3), want: 0},
+				{tanggal: hari(11), want: rp(75), wantBerubah: true}, // 7 days on 1000 plus the fixed fee
+				{tanggal: hari(11), want: rp(75)},
+				{tanggal: hari(21), pokokDibayar: rp(500), want: rp(125), wantBerubah: true}, // 10 days on 500
+				{tanggal: hari(15), want: rp(125)},
+			},
+		},
+		{
+			name: "caps the total",
+			maks: rp(100),
+			langkah: []langkah{
+				{tanggal: hari(11), want: rp(75), wantBerubah: true},
+				{tanggal: hari(21), pokokDibayar: rp(500), want: rp(100), wantBerubah: true},
+				{tanggal: hari(31), want: rp(100), wantBerubah: true},
+			},
+		},
+	}
+	for _, sk := range skenario {
+		t.Run(sk.name, func(t *testing.T) {
+			aturan := &model.AturanDenda{PersenPerHari: 1, MasaTenggangHari: 3, BiayaTetap: rp(5), MaksimalDenda: sk.maks}
+			jadwal := []model.JadwalAngsuran{{
+				TanggalJatuhTempo: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+				Pokok:             rp(1000),
+				Status:            model.JadwalBelumBayar,
+			}}
+			for i, l := range sk.langkah {
+				jadwal[0].PokokDibayar += l.pokokDibayar
+				changed := terapkanDenda(jadwal, aturan, l.tanggal)
+				if jadwal[0].Denda != l.want || (len(changed) > 0) != l.wantBerubah {
+					t.Errorf("step %d: denda %s changed %v, want %s %v", i, jadwal[0].Denda, changed, l.want, l.wantBerubah)
+				}
+			}
+		})
+	}
+}
+
+func TestHitungDendaTidakBerubah(t *testing.T) {
+	rp := money.FromRupiah
+	sampai := time.Date(2024, time.January, 11, 0, 0, 0, 0, time.UTC)
+	tanggal := time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)
+	aturan := &model.AturanDenda{PersenPerHari: 1, MaksimalDenda: rp(100)}
+	tests := []struct {
+		name   string
+		row    model.JadwalAngsuran
+		aturan *model.AturanDenda
+	}{
+		{"without a rule", model.JadwalAngsuran{Pokok: rp(1000), Denda: rp(20), DendaDihitungSampai: &sampai}, nil},
+		{"lunas", model.JadwalAngsuran{Pokok: rp(1000), PokokDibayar: rp(1000), Denda: rp(20), DendaDibayar: rp(20), Status: model.JadwalLunas, DendaDihitungSampai: &sampai}, aturan},
+		{"already above a lowered cap", model.JadwalAngsuran{Pokok: rp(1000), Denda: rp(150), DendaDihitungSampai: &sampai}, aturan},
+	}
+	for _, tt := range tests {
+		tt.row.TanggalJatuhTempo = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+		if _, denda, _ := hitungDenda(&tt.row, tt.aturan, tanggal); denda != tt.row.Denda {
+			t.Errorf("%s: denda %s, want %s", tt.name, denda, tt.row.Denda)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"koperasi-service/internal/model"
+	"koperasi-service/pkg/money"
+	"math"
+	"time"
+)
+
+// generateJadwal computes the amortization schedule of a loan. bungaPersen is
+// the monthly rate in percent and the first installment falls due one month
+// after mulai. Amounts are whole rupiah; the last row absorbs the rounding so
+// the principal always adds up to pokok.
+func generateJadwal(pokok money.Money, bungaPersen float64, lamaBulan int, metode string, mulai time.Time) ([]model.JadwalAngsuran, error) {
+	if pokok <= 0 {
+		return nil, errors.New("jumlah pinjaman must be positive")
+	}
+	if lamaBulan <= 0 {
+		return nil, errors.New("lama bulan must be positive")
+	}
+	if bungaPersen < 0 {
+		return nil, errors.New("bunga persen must not be negative")
+	}
+	if !model.ValidMetodeBunga[metode] {
+		return nil, errors.New("invalid metode bunga")
+	}
+
+	// Equal principal part for flat and efektif
+	pokokPerBulan := pokok.MulRatio(1, int64(lamaBulan))
+	// Fixed installment for anuitas
+	angsuranAnuitas := anuitas(pokok, bungaPersen, lamaBulan)
+	bungaFlat := pokok.MulPercent(bungaPersen)
+
+	rows := make([]model.JadwalAngsuran, 0, lamaBulan)
+	sisa := pokok
+	for ke := 1; ke <= lamaBulan; ke++ {
+		var pokokBulan, bungaBulan money.Money
+		switch metode {
+		case model.MetodeBungaFlat:
+			pokokBulan = pokokPerBulan
+			bungaBulan = bungaFlat
+		case model.MetodeBungaEfektif:
+			pokokBulan = pokokPerBulan
+			bungaBulan = sisa.MulPercent(bungaPersen)
+		case model.MetodeBungaAnuitas:
+			bungaBulan = sisa.MulPercent(bungaPersen)
+			pokokBulan = angsuranAnuitas - bungaBulan
+		}
+
+		// Last installment settles whatever principal is left
+		if ke == lamaBulan || pokokBulan > sisa {
+			pokokBulan = sisa
+		}
+		sisa -= pokokBulan
+
+		rows = append(rows, model.JadwalAngsuran{
+			AngsuranKe:        ke,
+			TanggalJatuhTempo: addMonths(mulai, ke),
+			Pokok:             pokokBulan,
+			Bunga:             bungaBulan,
+			TotalAngsuran:     pokokBulan + bungaBulan,
+			SisaPokok:         sisa,
+			Status:            "belum_bayar",
+		})
+	}
+	return rows, nil
+}
+
+// anuitas returns the fixed monthly installment P·r / (1 − (1+r)^−n) rounded
+// half-up to the rupiah. The power is computed in float64; the result is
+// rounded before use so the schedule itself stays exact.
+func anuitas(pokok money.Money, bungaPersen float64, lamaBulan int) money.Money {
+	if bungaPersen == 0 {
+		return pokok.MulRatio(1, int64(lamaBulan))
+	}
+	r := bungaPersen / 100
+	factor := r / (1 - math.Pow(1+r, -float64(lamaBulan)))
+	return money.FromFloat(pokok.Float64() * factor).RoundRupiah()
+}
+
+// addMonths adds n months to t, clamping to the last day of the target month
+// (Jan 31 + 1 month = Feb 28/29 instead of early March).
+func addMonths(t time.Time, n int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month()+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"koperasi-service/internal/model"
+	"koperasi-service/pkg/money"
+	"testing"
+	"time"
+)
+
+func TestGenerateJadwal(t *testing.T) {
+	rp := money.FromRupiah
+	tests := []struct {
+		name      string
+		pokok     money.Money
+		bunga     float64
+		lamaBulan int
+		metode    string
+		wantPokok []money.Money
+		wantBunga []money.Money
+	}{
+		{
+			name:  "flat",
+			pokok: rp(1000000), bunga: 1.5, lamaBulan: 3, metode: model.MetodeBungaFlat,
+			wantPokok: []money.Money{rp(333333), rp(333333), rp(333334)},
+			wantBunga: []money.Money{rp(15000), rp(15000), rp(15000)},
+		},
+		{
+			name:  "efektif",
+			pokok: rp(1000000), bunga: 1.5, lamaBulan: 3, metode: model.MetodeBungaEfektif,
+			wantPokok: []money.Money{rp(333333), rp(333333), rp(333334)},
+			wantBunga: []money.Money{rp(15000), rp(10000), rp(5000)},
+		},
+		{
+			// installment 340022; the last row pays the 336656 still left
+			name:  "anuitas",
+			pokok: rp(1000000), bunga: 1, lamaBulan: 3, metode: model.MetodeBungaAnuitas,
+			wantPokok: []money.Money{rp(330022), rp(333322), rp(336656)},
+			wantBunga: []money.Money{rp(10000), rp(6700), rp(3367)},
+		},
+		{
+			name:  "anuitas without bunga",
+			pokok: rp(100), bunga: 0, lamaBulan: 3, metode: model.MetodeBungaAnuitas,
+			wantPokok: []money.Money{rp(33), rp(33), rp(34)},
+			wantBunga: []money.Money{0, 0, 0},
+		},
+	}
+	mulai := time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := generateJadwal(tt.pokok, tt.bunga, tt.lamaBulan, tt.metode, mulai)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != tt.lamaBulan {
+				t.Fatalf("got %d rows, want %d", len(rows), tt.lamaBulan)
+			}
+			var total money.Money
+			for i, row := range rows {
+				if row.AngsuranKe != i+1 {
+					t.Errorf("row %d: angsuran_ke = %d", i, row.AngsuranKe)
+				}
+				if row.Pokok != tt.wantPokok[i] || row.Bunga != tt.wantBunga[i] {
+					t.Errorf("row %d: pokok %s bunga %s, want %s %s", i, row.Pokok, row.Bunga, tt.wantPokok[i], tt.wantBunga[i])
+				}
+				if row.TotalAngsuran != row.Pokok+row.Bunga {
+					t.Errorf("row %d: total %s != pokok + bunga", i, row.TotalAngsuran)
+				}
+				total += row.Pokok
+				if row.SisaPokok != tt.pokok-total {
+					t.Errorf("row %d: sisa pokok %s, want %s", i, row.SisaPokok, tt.pokok-total)
+				}
+			}
+			if total != tt.pokok {
+				t.Errorf("pokok adds up to %s, want %s", total, tt.pokok)
+			}
+		})
+	}
+}
+
+func TestGenerateJadwalJatuhTempo(t *testing.T) {
+	mulai := time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC)
+	rows, err := generateJadwal(money.FromRupiah(300000), 1, 3, model.MetodeBungaFlat, mulai)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Time{
+		time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.March, 31, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.April, 30, 9, 0, 0, 0, time.UTC),
+	}
+	for i, row := range rows {
+		if !row.TanggalJatuhTempo.Equal(want[i]) {
+			t.Errorf("row %d: jatuh tempo %s, want %s", i, row.TanggalJatuhTempo, want[i])
+		}
+	}
+}
+
+func TestGenerateJadwalInvalid(t *testing.T) {
+	mulai := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		pokok     money.Money
+		bunga     float64
+		lamaBulan int
+		metode    string
+	}{
+		{"zero pokok", 0, 1, 12, model.MetodeBungaFlat},
+		{"zero lama bulan", money.FromRupiah(1000), 1, 0, model.MetodeBungaFlat},
+		{"negative bunga", money.FromRupiah(1000), -1, 12, model.MetodeBungaFlat},
+		{"unknown metode", money.FromRupiah(1000), 1, 12, "bulanan"},
+	}
+	for _, tt := range tests {
+		if _, err := generateJadwal(tt.pokok, tt.bunga, tt.lamaBulan, tt.metode, mulai); err == nil {
+			t.Errorf("%s: want error", tt.name)
+		}
+	}
+}
+
+func TestAddMonths(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 10, 30, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		from time.Time
+		n    int
+		want time.Time
+	}{
+		{date(2024, time.January, 31), 1, date(2024, time.February, 29)},
+		{date(2023, time.January, 31), 1, date(2023, time.February, 28)},
+		{date(2024, time.January, 31), 2, date(2024, time.March, 31)},
+		{date(2024, time.January, 31), 3, date(2024, time.April, 30)},
+		{date(2023, time.November, 30), 3, date(2024, time.February, 29)},
+		{date(2024, time.February, 29), 12, date(2025, time.February, 28)},
+		{date(2024, time.February, 29), 48, date(2028, time.February, 29)},
+		{date(2023, time.December, 15), 1, date(2024, time.January, 15)},
+		{date(2024, time.March, 31), 0, date(2024, time.March, 31)},
+	}
+	for _, tt := range tests {
+		if got := addMonths(tt.from, tt.n); !got.Equal(tt.want) {
+			t.Errorf("addMonths(%s, %d) = %s, want %s", tt.from.Format("2006-01-02"), tt.n, got.Format("2006-01-02 15:04"), tt.want.Format("2006-01-02 15:04"))
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+	"koperasi-service/pkg/money"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrTanpaAturanJasa is returned when jasa simpanan is previewed or posted
+// without an active rule
+var ErrTanpaAturanJasa = errors.New("no active aturan jasa simpanan")
+
+// RekapJasaSimpanan is the jasa simpanan of the sukarela wallets for one month
+type RekapJasaSimpanan struct {
+	Periode        time.Time                 `json:"periode"`
+	JumlahHari     int                       `json:"jumlah_hari"` // Days counted; less than the month in a preview of the current month
+	DryRun         bool                      `json:"dry_run"`
+	Aturan         *model.AturanJasaSimpanan `json:"aturan"`
+	JumlahRekening int                       `json:"jumlah_rekening"`
+	TotalBruto     money.Money               `json:"total_bruto"`
+	TotalPajak     money.Money               `json:"total_pajak"`
+	TotalBersih    money.Money               `json:"total_bersih"`
+	Rincian        []model.JasaSimpanan      `json:"rincian"`
+}
+
+func (r *RekapJasaSimpanan) tambah(j *model.JasaSimpanan) {
+	r.JumlahRekening++
+	r.TotalBruto += j.JasaBruto
+	r.TotalPajak += j.Pajak
+	r.TotalBersih += j.JasaBersih
+	r.Rincian = append(r.Rincian, *j)
+}
+
+// hariJasa returns how many days of periode count on now: the whole month once
+// it has ended, up to and including today for the current month
+func hariJasa(periode, now time.Time) (int, error) {
+	bulanIni := periodeBulan(now)
+	if periode.After(bulanIni) {
+		return 0, errors.New("periode must not be in the future")
+	}
+	if periode.Equal(bulanIni) {
+		return now.Day(), nil
+	}
+	return periode.AddDate(0, 1, -1).Day(), nil
+}
+
+// bungaHarian returns the interest of hari days on dasar at an annual rate,
+// counted per day of a 365-day year and rounded to the rupiah
+func bungaHarian(dasar money.Money, persenTahunan float64, hari int) money.Money {
+	// Rates have two decimals (decimal(5,2)), so persen × 100 is whole
+	return dasar.MulRatio(int64(math.Round(persenTahunan*100))*int64(hari), 100*100*365)
+}
+
+// hitungJasaSimpanan computes the jasa of a wallet over the first hari days of
+// periode from its ledger. Balances are taken at the end of each day; the
+// rule's annual rate is applied to the basis per day of a 365-day year.
+func hitungJasaSimpanan(ledger *repository.LedgerRepository, wallet *model.Simpanan, aturan *model.AturanJasaSimpanan, periode time.Time, hari int) (*model.JasaSimpanan, error) {
+	saldo, err := ledger.GetWalletBalanceBefore(wallet.ID, periode)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ledger.GetWalletEntriesBetween(wallet.ID, periode, periode.AddDate(0, 0, hari))
+	if err != nil {
+		return nil, err
+	}
+
+	var terendah, jumlah money.Money
+	i := 0
+	for d := 0; d < hari; d++ {
+		akhirHari := periode.AddDate(0, 0, d+1)
+		for ; i < len(entries) && entries[i].PostedAt.Before(akhirHari); i++ {
+			saldo += entries[i].Credit - entries[i].Debit
+		}
+		if d == 0 || saldo < terendah {
+			terendah = saldo
+		}
+		jumlah += saldo
+	}
+
+	var dasar money.Money
+	switch aturan.DasarPerhitungan {
+	case model.DasarSaldoTerendah:
+		dasar = terendah
+	case model.DasarSaldoAkhir:
+		dasar = saldo
+	default:
+		dasar = jumlah.MulRatio(1, int64(hari))
+	}
+	dasar = money.Max(dasar, 0)
+
+	bruto := bungaHarian(dasar, aturan.PersenTahunan, hari)
+	var pajak money.Money
+	if aturan.PersenPajak > 0 && bruto > aturan.BatasBebasPajak {
+		pajak = bruto.MulPercent(aturan.PersenPajak)
+	}
+
+	return &model.JasaSimpanan{
+		SimpananID:       wallet.ID,
+		UserID:           wallet.UserID,
+		Periode:          periode,
+		AturanID:         aturan.ID,
+		DasarPerhitungan: aturan.DasarPerhitungan,
+		PersenTahunan:    aturan.PersenTahunan,
+		JumlahHari:       hari,
+		SaldoDasar:       dasar,
+		JasaBruto:        bruto,
+		Pajak:            pajak,
+		JasaBersih:       bruto - pajak,
+	}, nil
+}
+
+// kreditJasaSimpanan credits j to a wallet locked by the caller: a verified
+// jasa transaction in the wallet history and one journal with the gross
+// amount as expense, the net amount to the wallet and the tax as a liability
+func kreditJasaSimpanan(repos *repository.Repositories, wallet *model.Simpanan, j *model.JasaSimpanan, postedBy *uint, now time.Time) error {
+	description := "Jasa simpanan " + j.Periode.Format("2006-01")
+	transaction := &model.SimpananTransaction{
+		SimpananID:   wallet.ID,
+		Type:         "jasa",
+		Amount:       j.JasaBersih,
+		Description:  description,
+		Status:       "verified",
+		VerifiedByID: postedBy,
+		VerifiedAt:   &gorm.DeletedAt{Time: now, Valid: true},
+	}
+	if err := repos.Simpanan.CreateTransaction(transaction); err != nil {
+		return err
+	}
+
+	walletID := wallet.ID
+	entries := []model.LedgerEntry{
+		{Account: model.AkunBebanJasa, Debit: j.JasaBruto},
+		{Account: model.AkunSimpananAnggota, SimpananID: &walletID, Credit: j.JasaBersih},
+	}
+	if j.Pajak > 0 {
+		entries = append(entries, model.LedgerEntry{Account: model.AkunUtangPajak, Credit: j.Pajak})
+	}
+	journal := &model.LedgerJournal{
+		EntryType:      model.LedgerJasaSimpanan,
+		ReferenceTable: "simpanan_transactions",
+		ReferenceID:    transaction.ID,
+		Description:    description,
+		PostedAt:       now,
+		PostedBy:       postedBy,
+		Entries:        entries,
+	}
+	if err := repos.Ledger.PostJournal(journal); err != nil {
+		return err
+	}
+	wallet.Balance += j.JasaBersih
+	if err := repos.Simpanan.UpdateWallet(wallet); err != nil {
+		return err
+	}
+	if err := repos.Simpanan.SetTransactionJournal(transaction.ID, journal.ID); err != nil {
+		return err
+	}
+
+	j.SimpananTransactionID = &transaction.ID
+	j.PostedBy = postedBy
+	return repos.JasaSimpanan.Create(j)
+}
+
+// PreviewJasaSimpanan computes the jasa of every sukarela wallet for periode
+// under the active rule without posting anything (admin only). Wallets already
+// credited for periode are left out. For the current month the balances up to
+// today are used.
+func (s *SimpananService) PreviewJasaSimpanan(requestorRole string, periode time.Time) (*RekapJasaSimpanan, error) {
+	if requestorRole != "super_admin" && requestorRole != "admin" {
+		return nil, errors.New("forbidden")
+	}
+	aturan, err := s.aturanJasaRepo.GetActive()
+	if err != nil {
+		return nil, err
+	}
+	if aturan == nil {
+		return nil, ErrTanpaAturanJasa
+	}
+	periode = periodeBulan(periode)
+	hari, err := hariJasa(periode, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	wallets, err := s.repo.GetWalletsByType("sukarela")
+	if err != nil {
+		return nil, err
+	}
+
+	rekap := &RekapJasaSimpanan{Periode: periode, JumlahHari: hari, DryRun: true, Aturan: aturan, Rincian: []model.JasaSimpanan{}}
+	for i := range wallets {
+		exists, err := s.jasaRepo.ExistsForPeriode(wallets[i].ID, periode)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			continue
+		}
+		j, err := hitungJasaSimpanan(s.ledgerRepo, &wallets[i], aturan, periode, hari)
+		if err != nil {
+			return nil, err
+		}
+		if j.JasaBersih > 0 {
+			rekap.tambah(j)
+		}
+	}
+	return rekap, nil
+}
+
+// PostJasaSimpanan credits last month's jasa to every sukarela wallet not yet
+// credited for it. Without an active rule nothing is posted. Safe to run every day.
+func (s *SimpananService) PostJasaSimpanan(now time.Time) (int, error) {
+	aturan, err := s.aturanJasaRepo.GetActive()
+	if err != nil {
+		return 0, err
+	}
+	if aturan == nil {
+		return 0, nil
+	}
+	rekap, err := s.postJasaSimpanan(aturan, nil, periodeBulan(now).AddDate(0, -1, 0), now)
+	return rekap.JumlahRekening, err
+}
+
+// RunJasaSimpanan credits the jasa of an ended month now (admin only)
+func (s *SimpananService) RunJasaSimpanan(adminID uint, adminRole string, periode time.Time) (*RekapJasaSimpanan, error) {
+	if adminRole != "super_admin" && adminRole != "admin" {
+		return nil, errors.New("forbidden")
+	}
+	aturan, err := s.aturanJasaRepo.GetActive()
+	if err != nil {
+		return nil, err
+	}
+	if aturan == nil {
+		return nil, ErrTanpaAturanJasa
+	}
+	return s.postJasaSimpanan(aturan, &adminID, periodeBulan(periode), time.Now())
+}
+
+// postJasaSimpanan credits the jasa of periode to each sukarela wallet in its
+// own unit of work, so one failing wallet does not hold back the others
+func (s *SimpananService) postJasaSimpanan(aturan *model.AturanJasaSimpanan, postedBy *uint, periode, now time.Time) (*RekapJasaSimpanan, error) {
+	rekap := &RekapJasaSimpanan{Periode: periode, Aturan: aturan, Rincian: []model.JasaSimpanan{}}
+	if !periode.Before(periodeBulan(now)) {
+		return rekap, errors.New("jasa simpanan can only be posted after the month has ended")
+	}
+	rekap.JumlahHari, _ = hariJasa(periode, now)
+	wallets, err := s.repo.GetWalletsByType("sukarela")
+	if err != nil {
+		return rekap, err
+	}
+
+	var errs []error
+	for _, w := range wallets {
+		exists, err := s.jasaRepo.ExistsForPeriode(w.ID, periode)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("simpanan %d: %w", w.ID, err))
+			continue
+		}
+		if exists {
+			continue
+		}
+		var posted *model.JasaSimpanan
+		err = s.uow.Do(func(repos *repository.Repositories) error {
+			wallet, err := repos.Simpanan.GetWalletByIDForUpdate(w.ID)
+			if err != nil {
+				return err
+			}
+			// Re-checked under the lock against a concurrent run
+			exists, err := repos.JasaSimpanan.ExistsForPeriode(wallet.ID, periode)
+			if err != nil || exists {
+				return err
+			}
+			j, err := hitungJasaSimpanan(repos.Ledger, wallet, aturan, periode, rekap.JumlahHari)
+			if err != nil || j.JasaBersih <= 0 {
+				return err
+			}
+			if err := kreditJasaSimpanan(repos, wallet, j, postedBy, now); err != nil {
+				return err
+			}
+			posted = j
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("simpanan %d: %w", w.ID, err))
+			continue
+		}
+		if posted != nil {
+			rekap.tambah(posted)
+		}
+	}
+	return rekap, errors.Join(errs...)
+}
+
+// ListJasaSimpanan returns posted jasa simpanan, newest month first. Members
+// only see their own; admins see everyone's unless userID is set.
+func (s *SimpananService) ListJasaSimpanan(requestorID uint, requestorRole string, userID uint, periode *time.Time) ([]model.JasaSimpanan, error) {
+	if requestorRole != "super_admin" && requestorRole != "admin" {
+		userID = requestorID
+	}
+	return s.jasaRepo.List(userID, periode)
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"errors"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+)
+
+type JenisPinjamanService interface {
+	CreateJenisPinjaman(userID uint, jenis *model.JenisPinjaman) (*model.JenisPinjaman, error)
+	GetJenisPinjamanByID(id uint) (*model.JenisPinjaman, error)
+	GetAllJenisPinjaman() ([]model.JenisPinjaman, error)
+	GetActiveJenisPinjaman() ([]model.JenisPinjaman, error)
+	UpdateJenisPinjaman(id uint, userID uint, payload *model.JenisPinjaman) (*model.JenisPinjaman, error)
+	DeleteJenisPinjaman(id uint, userID uint) error
+	SetJenisPinjamanActive(id uint, userID uint, isActive bool) error
+}
+
+type jenisPinjamanService struct {
+	jenisPinjamanRepo   repository.JenisPinjamanRepository
+	bungaOptionRepo     repository.BungaOptionRepository
+	aturanKelayakanRepo repository.AturanRepository[model.AturanKelayakan]
+	userRepo            *repository.UserRepository
+}
+
+func NewJenisPinjamanService(jenisPinjamanRepo repository.JenisPinjamanRepository, bungaOptionRepo repository.BungaOptionRepository, aturanKelayakanRepo repository.AturanRepository[model.AturanKelayakan], userRepo *repository.UserRepository) JenisPinjamanService {
+	return &jenisPinjamanService{
+		jenisPinjamanRepo:   jenisPinjamanRepo,
+		bungaOptionRepo:     bungaOptionRepo,
+		aturanKelayakanRepo: aturanKelayakanRepo,
+		userRepo:            userRepo,
+	}
+}
+
+func (s *jenisPinjamanService) CreateJenisPinjaman(userID uint, jenis *model.JenisPinjaman) (*model.JenisPinjaman, error) {
+	if err := s.checkAdmin(userID, "only admin can create jenis pinjaman"); err != nil {
+		return nil, err
+	}
+
+	if err := s.validate(jenis); err != nil {
+		return nil, err
+	}
+
+	jenis.IsActive = true
+	jenis.CreatedBy = userID
+	if err := s.jenisPinjamanRepo.Create(jenis); err != nil {
+		return nil, err
+	}
+
+	return jenis, nil
+}
+
+func (s *jenisPinjamanService) GetJenisPinjamanByID(id uint) (*model.JenisPinjaman, error) {
+	return s.jenisPinjamanRepo.GetByID(id)
+}
+
+func (s *jenisPinjamanService) GetAllJenisPinjaman() ([]model.JenisPinjaman, error) {
+	return s.jenisPinjamanRepo.GetAll()
+}
+
+func (s *jenisPinjamanService) GetActiveJenisPinjaman() ([]model.JenisPinjaman, error) {
+	return s.jenisPinjamanRepo.GetActive()
+}
+
+// UpdateJenisPinjaman changes a product. Loans already created keep the
+// rate and fees they were given.
+func (s *jenisPinjamanService) UpdateJenisPinjaman(id uint, userID uint, payload *model.JenisPinjaman) (*model.JenisPinjaman, error) {
+	if err := s.checkAdmin(userID, "only admin can update jenis pinjaman"); err != nil {
+		return nil, err
+	}
+
+	if err := s.validate(payload); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.jenisPinjamanRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	existing.Nama = payload.Nama
+	existing.Deskripsi = payload.Deskripsi
+	existing.MinimalJumlah = payload.MinimalJumlah
+	existing.MaksimalJumlah = payload.MaksimalJumlah
+	existing.MinimalBulan = payload.MinimalBulan
+	existing.MaksimalBulan = payload.MaksimalBulan
+	existing.BungaOptionID = payload.BungaOptionID
+	existing.BungaPersen = payload.BungaPersen
+	existing.MetodeBunga = payload.MetodeBunga
+	existing.BiayaAdmin = payload.BiayaAdmin
+	existing.BiayaProvisiPersen = payload.BiayaProvisiPersen
+	existing.WajibPenjamin = payload.WajibPenjamin
+	existing.WajibAgunan = payload.WajibAgunan
+	existing.MinimalNilaiAgunan = payload.MinimalNilaiAgunan
+	existing.AturanKelayakanID = payload.AturanKelayakanID
+
+	if err := s.jenisPinjamanRepo.Update(existing); err != nil {
+		return nil, err
+	}
+
+	return s.jenisPinjamanRepo.GetByID(id)
+}
+
+func (s *jenisPinjamanService) DeleteJenisPinjaman(id uint, userID uint) error {
+	if err := s.checkAdmin(userID, "only admin can delete jenis pinjaman"); err != nil {
+		return err
+	}
+
+	return s.jenisPinjamanRepo.Delete(id)
+}
+
+func (s *jenisPinjamanService) SetJenisPinjamanActive(id uint, userID uint, isActive bool) error {
+	if err := s.checkAdmin(userID, "only admin can modify jenis pinjaman status"); err != nil {
+		return err
+	}
+
+	return s.jenisPinjamanRepo.SetActive(id, isActive)
+}
+
+// checkAdmin verifies that the user is admin or super_admin
+func (s *jenisPinjamanService) checkAdmin(userID uint, message string) error {
+	user, err := s.userRepo.FindByIDWithRole(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if user.Role.Name != "admin" && user.Role.Name != "super_admin" {
+		return errors.New(message)
+	}
+	return nil
+}
+
+// validate checks the product values and that the linked option and rule exist.
+// An empty method defaults to the linked option's method, or flat.
+func (s *jenisPinjamanService) validate(jenis *model.JenisPinjaman) error {
+	if jenis.MinimalJumlah < 0 || jenis.MaksimalJumlah < 0 || jenis.BiayaAdmin < 0 {
+		return errors.New("jenis pinjaman amounts must not be negative")
+	}
+	if jenis.MaksimalJumlah > 0 && jenis.MaksimalJumlah < jenis.MinimalJumlah {
+		return errors.New("maksimal jumlah must not be below minimal jumlah")
+	}
+	if jenis.MinimalBulan <= 0 {
+		jenis.MinimalBulan = 1
+	}
+	if jenis.MaksimalBulan < 0 || (jenis.MaksimalBulan > 0 && jenis.MaksimalBulan < jenis.MinimalBulan) {
+		return errors.New("maksimal bulan must not be below minimal bulan")
+	}
+	if jenis.BungaPersen < 0 || jenis.BiayaProvisiPersen < 0 || jenis.MinimalNilaiAgunan < 0 {
+		return errors.New("jenis pinjaman percentages must not be negative")
+	}
+	if jenis.BiayaProvisiPersen > 100 {
+		return errors.New("biaya provisi persen must not exceed 100")
+	}
+
+	if jenis.BungaOptionID != nil {
+		option, err := s.bungaOptionRepo.GetByID(*jenis.BungaOptionID)
+		if err != nil {
+			return errors.New("bunga option not found")
+		}
+		if jenis.MetodeBunga == "" {
+			jenis.MetodeBunga = option.MetodeBunga
+		}
+	} else if jenis.BungaPersen <= 0 {
+		return errors.New("bunga_option_id or bunga_persen is required")
+	}
+	if jenis.MetodeBunga == "" {
+		jenis.MetodeBunga = model.MetodeBungaFlat
+	}
+	if !model.ValidMetodeBunga[jenis.MetodeBunga] {
+		return errors.New("invalid metode bunga")
+	}
+
+	if jenis.AturanKelayakanID != nil {
+		if _, err := s.aturanKelayakanRepo.GetByID(*jenis.AturanKelayakanID); err != nil {
+			return errors.New("aturan kelayakan not found")
+		}
+	}
+	return nil
+}
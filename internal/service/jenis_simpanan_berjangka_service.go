@@ -0,0 +1,134 @@
+package service
+
+import (
+	"errors"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+)
+
+type JenisSimpananBerjangkaService interface {
+	CreateJenisSimpananBerjangka(userID uint, jenis *model.JenisSimpananBerjangka) (*model.JenisSimpananBerjangka, error)
+	GetJenisSimpananBerjangkaByID(id uint) (*model.JenisSimpananBerjangka, error)
+	GetAllJenisSimpananBerjangka() ([]model.JenisSimpananBerjangka, error)
+	GetActiveJenisSimpananBerjangka() ([]model.JenisSimpananBerjangka, error)
+	UpdateJenisSimpananBerjangka(id uint, userID uint, payload *model.JenisSimpananBerjangka) (*model.JenisSimpananBerjangka, error)
+	DeleteJenisSimpananBerjangka(id uint, userID uint) error
+	SetJenisSimpananBerjangkaActive(id uint, userID uint, isActive bool) error
+}
+
+type jenisSimpananBerjangkaService struct {
+	jenisRepo repository.JenisSimpananBerjangkaRepository
+	userRepo  *repository.UserRepository
+}
+
+func NewJenisSimpananBerjangkaService(jenisRepo repository.JenisSimpananBerjangkaRepository, userRepo *repository.UserRepository) JenisSimpananBerjangkaService {
+	return &jenisSimpananBerjangkaService{
+		jenisRepo: jenisRepo,
+		userRepo:  userRepo,
+	}
+}
+
+func (s *jenisSimpananBerjangkaService) CreateJenisSimpananBerjangka(userID uint, jenis *model.JenisSimpananBerjangka) (*model.JenisSimpananBerjangka, error) {
+	if err := s.checkAdmin(userID, "only admin can create jenis simpanan berjangka"); err != nil {
+		return nil, err
+	}
+
+	if err := validateJenisSimpananBerjangka(jenis); err != nil {
+		return nil, err
+	}
+
+	jenis.IsActive = true
+	jenis.CreatedBy = userID
+	if err := s.jenisRepo.Create(jenis); err != nil {
+		return nil, err
+	}
+
+	return jenis, nil
+}
+
+func (s *jenisSimpananBerjangkaService) GetJenisSimpananBerjangkaByID(id uint) (*model.JenisSimpananBerjangka, error) {
+	return s.jenisRepo.GetByID(id)
+}
+
+func (s *jenisSimpananBerjangkaService) GetAllJenisSimpananBerjangka() ([]model.JenisSimpananBerjangka, error) {
+	return s.jenisRepo.GetAll()
+}
+
+func (s *jenisSimpananBerjangkaService) GetActiveJenisSimpananBerjangka() ([]model.JenisSimpananBerjangka, error) {
+	return s.jenisRepo.GetActive()
+}
+
+// UpdateJenisSimpananBerjangka changes a product. Running deposits keep the
+// rate and penalty they started with; rollovers take the new values.
+func (s *jenisSimpananBerjangkaService) UpdateJenisSimpananBerjangka(id uint, userID uint, payload *model.JenisSimpananBerjangka) (*model.JenisSimpananBerjangka, error) {
+	if err := s.checkAdmin(userID, "only admin can update jenis simpanan berjangka"); err != nil {
+		return nil, err
+	}
+
+	if err := validateJenisSimpananBerjangka(payload); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.jenisRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	existing.Nama = payload.Nama
+	existing.Deskripsi = payload.Deskripsi
+	existing.TenorBulan = payload.TenorBulan
+	existing.PersenTahunan = payload.PersenTahunan
+	existing.MinimalNominal = payload.MinimalNominal
+	existing.PersenPenalti = payload.PersenPenalti
+
+	if err := s.jenisRepo.Update(existing); err != nil {
+		return nil, err
+	}
+
+	return s.jenisRepo.GetByID(id)
+}
+
+func (s *jenisSimpananBerjangkaService) DeleteJenisSimpananBerjangka(id uint, userID uint) error {
+	if err := s.checkAdmin(userID, "only admin can delete jenis simpanan berjangka"); err != nil {
+		return err
+	}
+
+	return s.jenisRepo.Delete(id)
+}
+
+func (s *jenisSimpananBerjangkaService) SetJenisSimpananBerjangkaActive(id uint, userID uint, isActive bool) error {
+	if err := s.checkAdmin(userID, "only admin can modify jenis simpanan berjangka status"); err != nil {
+		return err
+	}
+
+	return s.jenisRepo.SetActive(id, isActive)
+}
+
+// checkAdmin verifies that the user is admin or super_admin
+func (s *jenisSimpananBerjangkaService) checkAdmin(userID uint, message string) error {
+	user, err := s.userRepo.FindByIDWithRole(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if user.Role.Name != "admin" && user.Role.Name != "super_admin" {
+		return errors.New(message)
+	}
+	return nil
+}
+
+func validateJenisSimpananBerjangka(jenis *model.JenisSimpananBerjangka) error {
+	if jenis.TenorBulan != 3 && jenis.TenorBulan != 6 && jenis.TenorBulan != 12 {
+		return errors.New("tenor bulan must be 3, 6 or 12")
+	}
+	if jenis.PersenTahunan <= 0 || jenis.PersenTahunan > 100 {
+		return errors.New("persen tahunan must be above 0 and at most 100")
+	}
+	if jenis.PersenPenalti < 0 || jenis.PersenPenalti > 100 {
+		return errors.New("persen penalti must be between 0 and 100")
+	}
+	if jenis.MinimalNominal < 0 {
+		return errors.New("minimal nominal must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+	"regexp"
+)
+
+type JenisSimpananService interface {
+	CreateJenisSimpanan(userID uint, jenis *model.JenisSimpanan) (*model.JenisSimpanan, error)
+	GetJenisSimpananByID(id uint) (*model.JenisSimpanan, error)
+	GetAllJenisSimpanan() ([]model.JenisSimpanan, error)
+	GetActiveJenisSimpanan() ([]model.JenisSimpanan, error)
+	UpdateJenisSimpanan(id uint, userID uint, payload *model.JenisSimpanan) (*model.JenisSimpanan, error)
+	DeleteJenisSimpanan(id uint, userID uint) error
+	SetJenisSimpananActive(id uint, userID uint, isActive bool) error
+	BackfillWallets() (int64, error)
+}
+
+// kodeJenisSimpanan is the format of a wallet type code
+var kodeJenisSimpanan = regexp.MustCompile(`^[a-z][a-z0-9_]{1,29}$`)
+
+type jenisSimpananService struct {
+	jenisSimpananRepo repository.JenisSimpananRepository
+	simpananRepo      *repository.SimpananRepository
+	userRepo          *repository.UserRepository
+}
+
+func NewJenisSimpananService(jenisSimpananRepo repository.JenisSimpananRepository, simpananRepo *repository.SimpananRepository, userRepo *repository.UserRepository) JenisSimpananService {
+	return &jenisSimpananService{
+		jenisSimpananRepo: jenisSimpananRepo,
+		simpananRepo:      simpananRepo,
+		userRepo:          userRepo,
+	}
+}
+
+// CreateJenisSimpanan adds an active wallet type and gives every member a
+// wallet of it
+func (s *jenisSimpananService) CreateJenisSimpanan(userID uint, jenis *model.JenisSimpanan) (*model.JenisSimpanan, error) {
+	if err := s.checkAdmin(userID, "only admin can create jenis simpanan"); err != nil {
+		return nil, err
+	}
+
+	if !kodeJenisSimpanan.MatchString(jenis.Kode) {
+		return nil, errors.New("kode must be 2-30 lowercase letters, digits or underscores, starting with a letter")
+	}
+	if jenis.Kode == "berjangka" {
+		return nil, errors.New("kode berjangka is reserved for simpanan berjangka")
+	}
+	if _, err := s.jenisSimpananRepo.GetByKode(jenis.Kode); err == nil {
+		return nil, errors.New("kode is already used")
+	}
+	if err := validateJenisSimpanan(jenis); err != nil {
+		return nil, err
+	}
+
+	jenis.IsActive = true
+	jenis.Bawaan = false
+	jenis.CreatedBy = &userID
+	if err := s.jenisSimpananRepo.Create(jenis); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.simpananRepo.BackfillWallets(jenis.Kode); err != nil {
+		return nil, err
+	}
+	return jenis, nil
+}
+
+func (s *jenisSimpananService) GetJenisSimpananByID(id uint) (*model.JenisSimpanan, error) {
+	return s.jenisSimpananRepo.GetByID(id)
+}
+
+func (s *jenisSimpananService) GetAllJenisSimpanan() ([]model.JenisSimpanan, error) {
+	return s.jenisSimpananRepo.GetAll()
+}
+
+func (s *jenisSimpananService) GetActiveJenisSimpanan() ([]model.JenisSimpanan, error) {
+	return s.jenisSimpananRepo.GetActive()
+}
+
+// UpdateJenisSimpanan changes a wallet type. The code stays as it is, since
+// wallets refer to it.
+func (s *jenisSimpananService) UpdateJenisSimpanan(id uint, userID uint, payload *model.JenisSimpanan) (*model.JenisSimpanan, error) {
+	if err := s.checkAdmin(userID, "only admin can update jenis simpanan"); err != nil {
+		return nil, err
+	}
+
+	if err := validateJenisSimpanan(payload); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.jenisSimpananRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if payload.BisaDitarik && existing.HanyaSaatKeluar() {
+		return nil, fmt.Errorf("simpanan %s is only refunded on resignation and cannot be made withdrawable", existing.Kode)
+	}
+
+	existing.Nama = payload.Nama
+	existing.Deskripsi = payload.Deskripsi
+	existing.BisaDitarik = payload.BisaDitarik
+	existing.Wajib = payload.Wajib
+	existing.SaldoMinimal = payload.SaldoMinimal
+	existing.NominalDefault = payload.NominalDefault
+
+	if err := s.jenisSimpananRepo.Update(existing); err != nil {
+		return nil, err
+	}
+
+	return existing, nil
+}
+
+// DeleteJenisSimpanan removes a wallet type nobody holds money in
+func (s *jenisSimpananService) DeleteJenisSimpanan(id uint, userID uint) error {
+	if err := s.checkAdmin(userID, "only admin can delete jenis simpanan"); err != nil {
+		return err
+	}
+
+	jenis, err := s.jenisSimpananRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if jenis.Bawaan {
+		return errors.New("a built-in jenis simpanan cannot be deleted")
+	}
+	hasBalance, err := s.simpananRepo.HasWalletsWithBalance(jenis.Kode)
+	if err != nil {
+		return err
+	}
+	if hasBalance {
+		return errors.New("jenis simpanan still has wallets with a balance")
+	}
+
+	return s.jenisSimpananRepo.Delete(id)
+}
+
+// SetJenisSimpananActive activates or deactivates a wallet type. Activating
+// one gives members who have no wallet of it one.
+func (s *jenisSimpananService) SetJenisSimpananActive(id uint, userID uint, isActive bool) error {
+	if err := s.checkAdmin(userID, "only admin can modify jenis simpanan status"); err != nil {
+		return err
+	}
+
+	jenis, err := s.jenisSimpananRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if jenis.Bawaan && !isActive {
+		return errors.New("a built-in jenis simpanan cannot be deactivated")
+	}
+	if err := s.jenisSimpananRepo.SetActive(id, isActive); err != nil {
+		return err
+	}
+
+	if isActive {
+		_, err = s.simpananRepo.BackfillWallets(jenis.Kode)
+	}
+	return err
+}
+
+// BackfillWallets gives every member a wallet of each active type they lack
+func (s *jenisSimpananService) BackfillWallets() (int64, error) {
+	list, err := s.jenisSimpananRepo.GetActive()
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, jenis := range list {
+		n, err := s.simpananRepo.BackfillWallets(jenis.Kode)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// checkAdmin verifies that the user is admin or super_admin
+func (s *jenisSimpananService) checkAdmin(userID uint, message string) error {
+	user, err := s.userRepo.FindByIDWithRole(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if user.Role.Name != "admin" && user.Role.Name != "super_admin" {
+		return errors.New(message)
+	}
+	return nil
+}
+
+func validateJenisSimpanan(jenis *model.JenisSimpanan) error {
+	if jenis.Nama == "" {
+		return errors.New("nama is required")
+	}
+	if jenis.SaldoMinimal < 0 || jenis.NominalDefault < 0 {
+		return errors.New("jenis simpanan amounts must not be negative")
+	}
+	return nil
+}
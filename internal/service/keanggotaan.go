@@ -0,0 +1,186 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+	"koperasi-service/pkg/money"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PengembalianSimpanan is what a resigning member gets back
+type PengembalianSimpanan struct {
+	UserID        uint                        `json:"user_id"`
+	TanggalKeluar time.Time                   `json:"tanggal_keluar"`
+	Total         money.Money                 `json:"total"`
+	Rincian       []model.SimpananTransaction `json:"rincian"` // One withdrawal per wallet with a balance
+}
+
+// aktifkanAnggota makes a calon anggota an active anggota once their simpanan
+// pokok is verified. Other states are left alone.
+func aktifkanAnggota(repos *repository.Repositories, userID uint, now time.Time) error {
+	user, err := repos.Users.FindByIDForUpdate(userID)
+	if err != nil {
+		return err
+	}
+	if user.StatusKeanggotaan != model.StatusCalonAnggota {
+		return nil
+	}
+	user.StatusKeanggotaan = model.StatusAnggotaAktif
+	user.TanggalAktif = &now
+	return repos.Users.Update(user)
+}
+
+// KeluarAnggota processes a member's resignation (admin only). The member must
+// have no running or macet loan, guarantee none, and have no open withdrawal or
+// pending top-up. Every wallet balance, simpanan pokok included, is refunded
+// through the ledger, unpaid wajib months are dropped, and the member becomes
+// keluar. This is the only way simpanan pokok leaves the koperasi.
+func (s *SimpananService) KeluarAnggota(adminID uint, adminRole string, userID uint, alasan string) (*PengembalianSimpanan, error) {
+	if adminRole != "super_admin" && adminRole != "admin" {
+		return nil, errors.New("forbidden")
+	}
+	if alasan == "" {
+		return nil, errors.New("alasan keluar is required")
+	}
+
+	var result *PengembalianSimpanan
+	err := s.uow.Do(func(repos *repository.Repositories) error {
+		wallets, err := repos.Simpanan.GetUserWallets(userID)
+		if err != nil {
+			return err
+		}
+		// Wallets before the user, the order top-up verification locks them in
+		sort.Slice(wallets, func(i, j int) bool { return wallets[i].ID < wallets[j].ID })
+		for i := range wallets {
+			wallet, err := repos.Simpanan.GetWalletByIDForUpdate(wallets[i].ID)
+			if err != nil {
+				return err
+			}
+			wallets[i] = *wallet
+		}
+
+		user, err := repos.Users.FindByIDForUpdate(userID)
+		if err != nil {
+			return err
+		}
+		if user.StatusKeanggotaan == model.StatusAnggotaKeluar {
+			return errors.New("member has already resigned")
+		}
+		if err := checkBolehKeluar(repos, userID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		result = &PengembalianSimpanan{UserID: userID, TanggalKeluar: now, Rincian: []model.SimpananTransaction{}}
+		for i := range wallets {
+			wallet := &wallets[i]
+			pending, err := repos.Simpanan.HasTransaction(wallet.ID, "topup", "pending")
+			if err != nil {
+				return err
+			}
+			if pending {
+				return errors.New("member has pending top-ups, verify or reject them first")
+			}
+			if wallet.Balance <= 0 {
+				continue
+			}
+
+			description := fmt.Sprintf("Pengembalian simpanan %s, keluar anggota", wallet.Type)
+			transaction := &model.SimpananTransaction{
+				SimpananID:   wallet.ID,
+				Type:         "withdrawal",
+				Amount:       -wallet.Balance,
+				Description:  description,
+				Status:       "verified",
+				VerifiedByID: &adminID,
+				VerifiedAt:   &gorm.DeletedAt{Time: now, Valid: true},
+			}
+			if err := repos.Simpanan.CreateTransaction(transaction); err != nil {
+				return err
+			}
+			journal, err := postWalletMovement(repos, wallet, walletMovement{
+				EntryType:      model.LedgerWithdrawal,
+				CounterAccount: model.AkunKas,
+				Amount:         transaction.Amount,
+				ReferenceTable: "simpanan_transactions",
+				ReferenceID:    transaction.ID,
+				Description:    description,
+				PostedBy:       &adminID,
+				PostedAt:       now,
+			})
+			if err != nil {
+				return err
+			}
+			if err := repos.Simpanan.SetTransactionJournal(transaction.ID, journal.ID); err != nil {
+				return err
+			}
+			transaction.LedgerJournalID = &journal.ID
+
+			result.Total -= transaction.Amount
+			result.Rincian = append(result.Rincian, *transaction)
+		}
+
+		if err := repos.KewajibanWajib.DeleteOpenByUser(userID); err != nil {
+			return err
+		}
+
+		user.StatusKeanggotaan = model.StatusAnggotaKeluar
+		user.TanggalKeluar = &now
+		user.AlasanKeluar = alasan
+		return repos.Users.Update(user)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkBolehKeluar verifies that nothing of the member is still running
+func checkBolehKeluar(repos *repository.Repositories, userID uint) error {
+	terbuka := append([]string{model.StatusPinjamanMacet}, pinjamanBerjalan...)
+	pinjaman, err := repos.Pinjaman.GetByUserAndStatus(userID, terbuka...)
+	if err != nil {
+		return err
+	}
+	if len(pinjaman) > 0 {
+		return errors.New("member still has a running pinjaman")
+	}
+	dijamin, err := repos.Penjamin.GetPinjamanDijamin(userID, terbuka...)
+	if err != nil {
+		return err
+	}
+	if len(dijamin) > 0 {
+		return errors.New("member still guarantees a running pinjaman")
+	}
+	penarikan, err := repos.Penarikan.List(userID, model.PenarikanPending)
+	if err != nil {
+		return err
+	}
+	disetujui, err := repos.Penarikan.List(userID, model.PenarikanApproved)
+	if err != nil {
+		return err
+	}
+	if len(penarikan)+len(disetujui) > 0 {
+		return errors.New("member still has an open penarikan")
+	}
+	berjangka, err := repos.Berjangka.List(userID, model.BerjangkaAktif)
+	if err != nil {
+		return err
+	}
+	if len(berjangka) > 0 {
+		return errors.New("member still has an active simpanan berjangka")
+	}
+	transfer, err := repos.Transfer.CountMenungguPersetujuan(userID)
+	if err != nil {
+		return err
+	}
+	if transfer > 0 {
+		return errors.New("member still has a transfer waiting for approval")
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"errors"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+	"koperasi-service/pkg/money"
+	"strings"
+	"time"
+)
+
+// Reason codes of a failed eligibility check
+const (
+	AlasanPinjamanMacet   = "pinjaman_macet"   // The member has a loan in macet
+	AlasanKeanggotaan     = "keanggotaan"      // The member is a calon anggota or has resigned
+	AlasanMasaKeanggotaan = "masa_keanggotaan" // Membership is younger than the minimum
+	AlasanPinjamanAktif   = "pinjaman_aktif"   // Too many loans running at the same time
+	AlasanPlafon          = "plafon"           // Outstanding principal would exceed the plafon
+	AlasanPenghasilan     = "penghasilan"      // Income is needed for the debt-service ratio
+	AlasanRasioAngsuran   = "rasio_angsuran"   // Installments would take too much of the income
+	AlasanTunggakanWajib  = "tunggakan_wajib"  // Simpanan wajib has an overdue month
+)
+
+// pinjamanBerjalan lists the statuses of loans that count as running and whose
+// principal is still owed. Macet loans are owed too but refuse the loan outright.
+var pinjamanBerjalan = []string{model.StatusPinjamanProses, model.StatusPinjamanDisetujui, model.StatusPinjamanDicairkan}
+
+// AlasanKelayakan is one reason a loan application is not eligible
+type AlasanKelayakan struct {
+	Kode  string `json:"kode"`
+	Pesan string `json:"pesan"`
+}
+
+// HasilKelayakan is the outcome of the eligibility check of a loan application
+type HasilKelayakan struct {
+	UserID             uint                   `json:"user_id"`
+	Layak              bool                   `json:"layak"`
+	Aturan             *model.AturanKelayakan `json:"aturan"`
+	JumlahPinjaman     money.Money            `json:"jumlah_pinjaman"`
+	JumlahAngsuran     money.Money            `json:"jumlah_angsuran"`     // First installment of the requested loan
+	TotalSimpanan      money.Money            `json:"total_simpanan"`      // Available balance of all the member's simpanan wallets
+	Plafon             *money.Money           `json:"plafon"`              // nil when the rule sets no plafon
+	SisaPokokBerjalan  money.Money            `json:"sisa_pokok_berjalan"` // Principal still owed on running loans
+	SisaPlafon         *money.Money           `json:"sisa_plafon"`         // Plafon left for this loan
+	PinjamanAktif      int                    `json:"pinjaman_aktif"`      // Running loans besides this one
+	PinjamanMacet      int                    `json:"pinjaman_macet"`
+	BulanKeanggotaan   int                    `json:"bulan_keanggotaan"`
+	PenghasilanBulanan money.Money            `json:"penghasilan_bulanan"`
+	AngsuranBulanan    money.Money            `json:"angsuran_bulanan"` // Installments of running loans plus this one
+	RasioAngsuran      *float64               `json:"rasio_angsuran"`   // Percent of income, nil without income
+	TunggakanWajib     *TunggakanWajib        `json:"tunggakan_wajib"`
+	Alasan             []AlasanKelayakan      `json:"alasan"`
+}
+
+// KelayakanError is returned by Create and Update when the application fails
+// the eligibility check. Hasil carries the reasons.
+type KelayakanError struct {
+	Hasil *HasilKelayakan
+}
+
+func (e *KelayakanError) Error() string {
+	pesan := make([]string, 0, len(e.Hasil.Alasan))
+	for _, a := range e.Hasil.Alasan {
+		pesan = append(pesan, a.Pesan)
+	}
+	return "pinjaman is not eligible: " + strings.Join(pesan, "; ")
+}
+
+// CekKelayakan checks a loan application without saving it. Access rules are
+// the same as Create.
+func (s *PinjamanService) CekKelayakan(requestorID uint, requestorRole string, p *model.Pinjaman) (*HasilKelayakan, error) {
+	if requestorRole == "member" && p.UserID != requestorID {
+		return nil, errors.New("forbidden")
+	}
+	if p.TanggalPinjam.IsZero() {
+		p.TanggalPinjam = time.Now()
+	}
+	if _, err := s.hitungJadwalBaru(p); err != nil {
+		return nil, err
+	}
+
+	aturan, err := s.aturanKelayakanUntuk(p)
+	if err != nil {
+		return nil, err
+	}
+	var hasil *HasilKelayakan
+	err = s.uow.Do(func(repos *repository.Repositories) error {
+		hasil, err = cekKelayakan(repos, aturan, p, time.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hasil, nil
+}
+
+// kelayakanTerpenuhi locks the borrower and runs the eligibility check inside
+// the caller's unit of work, so two applications of the same member are
+// checked one after the other. It returns a *KelayakanError when not eligible.
+func (s *PinjamanService) kelayakanTerpenuhi(repos *repository.Repositories, p *model.Pinjaman) error {
+	if _, err := repos.Users.FindByIDForUpdate(p.UserID); err != nil {
+		return err
+	}
+	aturan, err := s.aturanKelayakanUntuk(p)
+	if err != nil {
+		return err
+	}
+	hasil, err := cekKelayakan(repos, aturan, p, time.Now())
+	if err != nil {
+		return err
+	}
+	if !hasil.Layak {
+		return &KelayakanError{Hasil: hasil}
+	}
+	return nil
+}
+
+// aturanKelayakanUntuk returns the eligibility rule of the loan's product, or
+// the active rule when the loan has no product or the product sets none
+func (s *PinjamanService) aturanKelayakanUntuk(p *model.Pinjaman) (*model.AturanKelayakan, error) {
+	if p.JenisPinjamanID != nil {
+		jenis, err := s.jenisPinjamanRepo.GetByID(*p.JenisPinjamanID)
+		if err != nil {
+			return nil, err
+		}
+		if jenis.AturanKelayakan != nil {
+			return jenis.AturanKelayakan, nil
+		}
+	}
+	return s.aturanKelayakanRepo.GetActive()
+}
+
+// cekKelayakan checks p against the rule. p.JumlahAngsuran must already be
+// derived from its schedule; a saved p (ID set) is left out of the member's
+// running loans. Without a rule only the macet check applies.
+func cekKelayakan(repos *repository.Repositories, aturan *model.AturanKelayakan, p *model.Pinjaman, now time.Time) (*HasilKelayakan, error) {
+	user, err := repos.Users.FindByID(p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	wallets, err := repos.Simpanan.GetUserWallets(p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	macet, err := repos.Pinjaman.GetByUserAndStatus(p.UserID, model.StatusPinjamanMacet)
+	if err != nil {
+		return nil, err
+	}
+	berjalan, err := repos.Pinjaman.GetByUserAndStatus(p.UserID, pinjamanBerjalan...)
+	if err != nil {
+		return nil, err
+	}
+	tunggakanWajib, err := hitungTunggakanWajib(repos.KewajibanWajib, p.UserID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// Membership starts when the simpanan pokok is verified; users that
+	// predate membership states count from their registration
+	mulai := user.CreatedAt
+	if user.TanggalAktif != nil {
+		mulai = *user.TanggalAktif
+	}
+
+	hasil := &HasilKelayakan{
+		UserID:             p.UserID,
+		Aturan:             aturan,
+		JumlahPinjaman:     p.JumlahPinjaman,
+		JumlahAngsuran:     p.JumlahAngsuran,
+		PinjamanMacet:      len(macet),
+		BulanKeanggotaan:   bulanSejak(mulai, now),
+		PenghasilanBulanan: p.PenghasilanBulanan,
+		AngsuranBulanan:    p.JumlahAngsuran,
+		TunggakanWajib:     tunggakanWajib,
+		Alasan:             []AlasanKelayakan{},
+	}
+	// Amounts held for pending withdrawals do not back a plafon
+	for i := range wallets {
+		hasil.TotalSimpanan += wallets[i].SaldoTersedia()
+	}
+	for i := range berjalan {
+		other := &berjalan[i]
+		if other.ID == p.ID {
+			continue
+		}
+		sisa, err := sisaPokokBerjalan(repos, other)
+		if err != nil {
+			return nil, err
+		}
+		hasil.PinjamanAktif++
+		hasil.SisaPokokBerjalan += sisa
+		hasil.AngsuranBulanan += other.JumlahAngsuran
+	}
+
+	tolak := func(kode, pesan string) {
+		hasil.Alasan = append(hasil.Alasan, AlasanKelayakan{Kode: kode, Pesan: pesan})
+	}
+
+	if user.StatusKeanggotaan != model.StatusAnggotaAktif {
+		tolak(AlasanKeanggotaan, "member is not an active anggota")
+	}
+	if hasil.PinjamanMacet > 0 {
+		tolak(AlasanPinjamanMacet, "member has a pinjaman in macet")
+	}
+	if aturan != nil {
+		if aturan.MinimalBulanKeanggotaan > 0 && hasil.BulanKeanggotaan < aturan.MinimalBulanKeanggotaan {
+			tolak(AlasanMasaKeanggotaan, "membership is shorter than the minimum months")
+		}
+		if aturan.WajibLancar && !tunggakanWajib.Lancar {
+			tolak(AlasanTunggakanWajib, "member has overdue simpanan wajib")
+		}
+		if aturan.MaksimalPinjamanAktif > 0 && hasil.PinjamanAktif >= aturan.MaksimalPinjamanAktif {
+			tolak(AlasanPinjamanAktif, "member already has the maximum number of active pinjaman")
+		}
+		if aturan.KelipatanSimpanan > 0 {
+			plafon := hasil.TotalSimpanan.MulFactor(aturan.KelipatanSimpanan)
+			sisaPlafon := money.Max(plafon-hasil.SisaPokokBerjalan, 0)
+			hasil.Plafon = &plafon
+			hasil.SisaPlafon = &sisaPlafon
+			if p.JumlahPinjaman > sisaPlafon {
+				tolak(AlasanPlafon, "jumlah pinjaman exceeds the remaining plafon")
+			}
+		}
+	}
+	if p.PenghasilanBulanan > 0 {
+		rasio := float64(hasil.AngsuranBulanan) / float64(p.PenghasilanBulanan) * 100
+		hasil.RasioAngsuran = &rasio
+	}
+	if aturan != nil && aturan.MaksimalRasioAngsuran > 0 {
+		if hasil.RasioAngsuran == nil {
+			tolak(AlasanPenghasilan, "penghasilan bulanan is required")
+		} else if *hasil.RasioAngsuran > aturan.MaksimalRasioAngsuran {
+			tolak(AlasanRasioAngsuran, "installments exceed the maximum share of monthly income")
+		}
+	}
+
+	hasil.Layak = len(hasil.Alasan) == 0
+	return hasil, nil
+}
+
+// sisaPokokBerjalan returns the principal still owed on a running loan. Loans
+// that have not been disbursed owe their full amount.
+func sisaPokokBerjalan(repos *repository.Repositories, p *model.Pinjaman) (money.Money, error) {
+	if p.Status != model.StatusPinjamanDicairkan {
+		return p.JumlahPinjaman, nil
+	}
+	jadwal, err := repos.Jadwal.GetByPinjaman(p.ID)
+	if err != nil {
+		return 0, err
+	}
+	if len(jadwal) == 0 {
+		return p.JumlahPinjaman, nil
+	}
+	return sisaPokokPinjaman(jadwal), nil
+}
+
+// bulanSejak returns the number of whole months from since to now
+func bulanSejak(since, now time.Time) int {
+	if !now.After(since) {
+		return 0
+	}
+	months := (now.Year()-since.Year())*12 + int(now.Month()-since.Month())
+	if addMonths(since, months).After(now) {
+		months--
+	}
+	return months
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+	"koperasi-service/pkg/money"
+	"time"
+)
+
+// kolektibilitasDari maps days past due to a collectibility bucket
+func kolektibilitasDari(hariTunggakan int) int {
+	switch {
+	case hariTunggakan <= 0:
+		return model.KolektibilitasLancar
+	case hariTunggakan <= 90:
+		return model.KolektibilitasDalamPerhatian
+	case hariTunggakan <= 120:
+		return model.KolektibilitasKurangLancar
+	case hariTunggakan <= 180:
+		return model.KolektibilitasDiragukan
+	}
+	return model.KolektibilitasMacet
+}
+
+// hariTunggakanJadwal returns the days past due of the oldest installment
+// whose bunga or pokok is still unpaid on tanggal. Unpaid denda alone does not
+// count as arrears.
+func hariTunggakanJadwal(jadwal []model.JadwalAngsuran, tanggal time.Time) int {
+	for i := range jadwal {
+		row := &jadwal[i]
+		if row.SisaBunga()+row.SisaPokokAngsuran() <= 0 {
+			continue
+		}
+		return hariTerlambat(row.TanggalJatuhTempo, tanggal)
+	}
+	return 0
+}
+
+// KlasifikasiKolektibilitas classifies every disbursed or written-off loan as
+// of tanggal, records bucket changes and writes off loans that reach bucket 5.
+// Each loan is handled in its own transaction so one failure does not stop the
+// run; it returns the number of loans whose bucket changed.
+func (s *PinjamanService) KlasifikasiKolektibilitas(tanggal time.Time) (int, error) {
+	ids, err := s.repo.GetIDsByStatus(model.StatusPinjamanDicairkan, model.StatusPinjamanMacet)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	var errs []error
+	for _, id := range ids {
+		berubah, err := s.klasifikasi(id, tanggal)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pinjaman %d: %w", id, err))
+			continue
+		}
+		if berubah {
+			changed++
+		}
+	}
+	return changed, errors.Join(errs...)
+}
+
+// klasifikasi classifies one loan and reports whether its bucket changed
+func (s *PinjamanService) klasifikasi(id uint, tanggal time.Time) (bool, error) {
+	berubah := false
+	err := s.uow.Do(func(repos *repository.Repositories) error {
+		p, err := repos.Pinjaman.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if p.Status != model.StatusPinjamanDicairkan && p.Status != model.StatusPinjamanMacet {
+			return nil
+		}
+		jadwal, err := ensureJadwal(repos, p)
+		if err != nil {
+			return err
+		}
+
+		hari := hariTunggakanJadwal(jadwal, tanggal)
+		ke := kolektibilitasDari(hari)
+		dari := p.Kolektibilitas
+		if dari == 0 {
+			dari = model.KolektibilitasLancar
+		}
+
+		p.HariTunggakan = hari
+		p.Kolektibilitas = ke
+		p.TanggalKlasifikasi = &tanggal
+		if ke != dari {
+			berubah = true
+			if err := repos.Kolektibilitas.CreateRiwayat(&model.RiwayatKolektibilitas{
+				PinjamanID:    p.ID,
+				Dari:          dari,
+				Ke:            ke,
+				HariTunggakan: hari,
+				Tanggal:       tanggal,
+			}); err != nil {
+				return err
+			}
+		}
+
+		// Bucket 5 makes the loan macet; it stays macet even if its arrears are
+		// later reduced, until it is fully repaid. The history above records
+		// when, and the hapus buku fields are left to a super admin's write-off.
+		if ke == model.KolektibilitasMacet && p.Status == model.StatusPinjamanDicairkan {
+			p.Status = model.StatusPinjamanMacet
+		}
+
+		return repos.Pinjaman.Update(p)
+	})
+	return berubah, err
+}
+
+// RunKlasifikasiKolektibilitas runs the classification on demand (super admin only)
+func (s *PinjamanService) RunKlasifikasiKolektibilitas(requestorRole string) (int, error) {
+	if requestorRole != "super_admin" {
+		return 0, errors.New("forbidden")
+	}
+	return s.KlasifikasiKolektibilitas(time.Now())
+}
+
+// AgingBucket is one collectibility bucket of the aging report
+type AgingBucket struct {
+	Kolektibilitas int         `json:"kolektibilitas"`
+	Nama           string      `json:"nama"`
+	JumlahPinjaman int64       `json:"jumlah_pinjaman"`
+	SisaPokok      money.Money `json:"sisa_pokok"`
+}
+
+// AgingReport groups active loans by collectibility bucket
+type AgingReport struct {
+	Buckets        []AgingBucket `json:"buckets"`
+	JumlahPinjaman int64         `json:"jumlah_pinjaman"`
+	SisaPokok      money.Money   `json:"sisa_pokok"`
+}
+
+// GetAging returns the aging report. Super admins see all loans, admins the
+// loans of members they registered.
+func (s *PinjamanService) GetAging(requestorID uint, requestorRole string) (*AgingReport, error) {
+	var adminID uint
+	switch requestorRole {
+	case "super_admin":
+	case "admin":
+		adminID = requestorID
+	default:
+		return nil, errors.New("forbidden")
+	}
+
+	rows, err := s.kolektibilitasRepo.GetAging(adminID)
+	if err != nil {
+		return nil, err
+	}
+	byBucket := make(map[int]repository.AgingBucket, len(rows))
+	for _, row := range rows {
+		byBucket[row.Kolektibilitas] = row
+	}
+
+	// Always list all five buckets, empty ones included
+	report := &AgingReport{}
+	for k := model.KolektibilitasLancar; k <= model.KolektibilitasMacet; k++ {
+		row := byBucket[k]
+		report.Buckets = append(report.Buckets, AgingBucket{
+			Kolektibilitas: k,
+			Nama:           model.NamaKolektibilitas[k],
+			JumlahPinjaman: row.JumlahPinjaman,
+			SisaPokok:      row.SisaPokok,
+		})
+		report.JumlahPinjaman += row.JumlahPinjaman
+		report.SisaPokok += row.SisaPokok
+	}
+	return report, nil
+}
+
+// GetRiwayatKolektibilitas returns the classification history of a loan with the same access rules as Get
+func (s *PinjamanService) GetRiwayatKolektibilitas(requestorID uint, requestorRole string, id uint) ([]model.RiwayatKolektibilitas, error) {
+	p, err := s.Get(requestorID, requestorRole, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.kolektibilitasRepo.GetRiwayatByPinjaman(p.ID)
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"koperasi-service/internal/model"
+	"koperasi-service/pkg/money"
+	"testing"
+	"time"
+)
+
+func TestKolektibilitasDari(t *testing.T) {
+	tests := []struct {
+		hari int
+		want int
+	}{
+		{-5, model.KolektibilitasLancar},
+		{0, model.KolektibilitasLancar},
+		{1, model.KolektibilitasDalamPerhatian},
+		{90, model.KolektibilitasDalamPerhatian},
+		{91, model.KolektibilitasKurangLancar},
+		{120, model.KolektibilitasKurangLancar},
+		{121, model.KolektibilitasDiragukan},
+		{180, model.KolektibilitasDiragukan},
+		{181, model.KolektibilitasMacet},
+		{720, model.KolektibilitasMacet},
+	}
+	for _, tt := range tests {
+		if got := kolektibilitasDari(tt.hari); got != tt.want {
+			t.Errorf("kolektibilitasDari(%d) = %d, want %d", tt.hari, got, tt.want)
+		}
+	}
+}
+
+func TestHariTunggakanJadwal(t *testing.T) {
+	rp := money.FromRupiah
+	row := func(bulan time.Month, pokokDibayar, bungaDibayar money.Money, denda money.Money) model.JadwalAngsuran {
+		return model.JadwalAngsuran{
+			TanggalJatuhTempo: time.Date(2024, bulan, 1, 0, 0, 0, 0, time.UTC),
+			Pokok:             rp(100),
+			Bunga:             rp(10),
+			PokokDibayar:      pokokDibayar,
+			BungaDibayar:      bungaDibayar,
+			Denda:             denda,
+		}
+	}
+	tanggal := time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		jadwal []model.JadwalAngsuran
+		want   int
+	}{
+		{"oldest unpaid row", []model.JadwalAngsuran{row(1, 0, 0, 0), row(2, 0, 0, 0)}, 91},
+		{"paid rows are skipped", []model.JadwalAngsuran{row(1, rp(100), rp(10), 0), row(2, 0, rp(10), 0)}, 60},
+		{"unpaid denda alone is no arrears", []model.JadwalAngsuran{row(1, rp(100), rp(10), rp(5)), row(5, 0, 0, 0)}, 0},
+		{"not yet due", []model.JadwalAngsuran{row(5, 0, 0, 0)}, 0},
+		{"fully paid", []model.JadwalAngsuran{row(1, rp(100), rp(10), 0)}, 0},
+	}
+	for _, tt := range tests {
+		if got := hariTunggakanJadwal(tt.jadwal, tanggal); got != tt.want {
+			t.Errorf("%s: hariTunggakanJadwal = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
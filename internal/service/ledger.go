@@ -0,0 +1,281 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+	"koperasi-service/pkg/money"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// walletMovement describes one money movement into or out of a simpanan wallet
+type walletMovement struct {
+	EntryType      string      // model.Ledger* constant
+	CounterAccount string      // model.Akun* constant on the other side of the wallet
+	Amount         money.Money // Positive moves money into the wallet, negative moves it out
+	ReferenceTable string
+	ReferenceID    uint
+	Description    string
+	PostedBy       *uint
+	PostedAt       time.Time // Defaults to now
+}
+
+// postWalletMovement posts a balanced journal for m and applies it to the
+// wallet's cached balance. The wallet must already be locked with
+// GetWalletByIDForUpdate inside the same unit of work.
+func postWalletMovement(repos *repository.Repositories, wallet *model.Simpanan, m walletMovement) (*model.LedgerJournal, error) {
+	if m.Amount == 0 {
+		return nil, errors.New("amount must not be zero")
+	}
+	// Money held for pending withdrawals cannot be spent
+	if m.Amount < 0 && wallet.SaldoTersedia()+m.Amount < 0 {
+		return nil, errors.New("insufficient balance")
+	}
+
+	journal := walletJournal(wallet.ID, m)
+	if err := repos.Ledger.PostJournal(journal); err != nil {
+		return nil, err
+	}
+
+	wallet.Balance += m.Amount
+	if err := repos.Simpanan.UpdateWallet(wallet); err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+// walletJournal builds the balanced journal for a movement on a wallet
+func walletJournal(walletID uint, m walletMovement) *model.LedgerJournal {
+	walletEntry := model.LedgerEntry{Account: model.AkunSimpananAnggota, SimpananID: &walletID}
+	counterEntry := model.LedgerEntry{Account: m.CounterAccount}
+	if m.Amount > 0 {
+		counterEntry.Debit = m.Amount
+		walletEntry.Credit = m.Amount
+	} else {
+		walletEntry.Debit = -m.Amount
+		counterEntry.Credit = -m.Amount
+	}
+
+	return &model.LedgerJournal{
+		EntryType:      m.EntryType,
+		ReferenceTable: m.ReferenceTable,
+		ReferenceID:    m.ReferenceID,
+		Description:    m.Description,
+		PostedAt:       m.PostedAt,
+		PostedBy:       m.PostedBy,
+		Entries:        []model.LedgerEntry{counterEntry, walletEntry},
+	}
+}
+
+// walletTransfer describes money moved from one wallet to another
+type walletTransfer struct {
+	EntryType      string      // model.Ledger* constant
+	Amount         money.Money // Must be positive
+	ReferenceTable string
+	ReferenceID    uint
+	Description    string
+	PostedBy       *uint
+	PostedAt       time.Time // Defaults to now
+}
+
+// postWalletTransfer posts a balanced journal that moves m.Amount from one
+// wallet to another and applies it to both cached balances. Both wallets must
+// already be locked inside the same unit of work.
+func postWalletTransfer(repos *repository.Repositories, from, to *model.Simpanan, m walletTransfer) (*model.LedgerJournal, error) {
+	if m.Amount <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+	if from.SaldoTersedia() < m.Amount {
+		return nil, errors.New("insufficient balance")
+	}
+
+	fromID, toID := from.ID, to.ID
+	journal := &model.LedgerJournal{
+		EntryType:      m.EntryType,
+		ReferenceTable: m.ReferenceTable,
+		ReferenceID:    m.ReferenceID,
+		Description:    m.Description,
+		PostedAt:       m.PostedAt,
+		PostedBy:       m.PostedBy,
+		Entries: []model.LedgerEntry{
+			{Account: model.AkunSimpananAnggota, SimpananID: &fromID, Debit: m.Amount},
+			{Account: model.AkunSimpananAnggota, SimpananID: &toID, Credit: m.Amount},
+		},
+	}
+	if err := repos.Ledger.PostJournal(journal); err != nil {
+		return nil, err
+	}
+
+	from.Balance -= m.Amount
+	if err := repos.Simpanan.UpdateWallet(from); err != nil {
+		return nil, err
+	}
+	to.Balance += m.Amount
+	if err := repos.Simpanan.UpdateWallet(to); err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+// walletLegs describes money moved from one wallet to another together with
+// the transaction it leaves in the history of each wallet
+type walletLegs struct {
+	Type              string      // SimpananTransaction type of both legs
+	EntryType         string      // model.Ledger* constant
+	Amount            money.Money // Must be positive
+	DescriptionKeluar string
+	DescriptionMasuk  string
+	PostedBy          *uint
+	PostedAt          time.Time
+}
+
+// postWalletLegs moves m.Amount between two wallets locked by the caller: a
+// verified transaction on each wallet, linked to each other, and one journal
+// that references the outgoing one. It returns the outgoing and incoming legs.
+func postWalletLegs(repos *repository.Repositories, from, to *model.Simpanan, m walletLegs) (*model.SimpananTransaction, *model.SimpananTransaction, error) {
+	verifiedAt := gorm.DeletedAt{Time: m.PostedAt, Valid: true}
+	keluar := &model.SimpananTransaction{
+		SimpananID:   from.ID,
+		Type:         m.Type,
+		Amount:       -m.Amount,
+		Description:  m.DescriptionKeluar,
+		Status:       "verified",
+		VerifiedByID: m.PostedBy,
+		VerifiedAt:   &verifiedAt,
+	}
+	if err := repos.Simpanan.CreateTransaction(keluar); err != nil {
+		return nil, nil, err
+	}
+	journal, err := postWalletTransfer(repos, from, to, walletTransfer{
+		EntryType:      m.EntryType,
+		Amount:         m.Amount,
+		ReferenceTable: "simpanan_transactions",
+		ReferenceID:    keluar.ID,
+		Description:    m.DescriptionKeluar,
+		PostedBy:       m.PostedBy,
+		PostedAt:       m.PostedAt,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := repos.Simpanan.SetTransactionJournal(keluar.ID, journal.ID); err != nil {
+		return nil, nil, err
+	}
+	keluar.LedgerJournalID = &journal.ID
+
+	masuk := &model.SimpananTransaction{
+		SimpananID:      to.ID,
+		Type:            m.Type,
+		Amount:          m.Amount,
+		Description:     m.DescriptionMasuk,
+		Status:          "verified",
+		VerifiedByID:    m.PostedBy,
+		VerifiedAt:      &verifiedAt,
+		LedgerJournalID: &journal.ID,
+		PasanganID:      &keluar.ID,
+	}
+	if err := repos.Simpanan.CreateTransaction(masuk); err != nil {
+		return nil, nil, err
+	}
+	if err := repos.Simpanan.SetTransactionPasangan(keluar.ID, masuk.ID); err != nil {
+		return nil, nil, err
+	}
+	keluar.PasanganID = &masuk.ID
+	return keluar, masuk, nil
+}
+
+// reverseJournal posts a journal that mirrors original with debit and credit
+// swapped, locking and updating every wallet it touches. It returns the
+// reversal journal and the net change per wallet.
+func reverseJournal(repos *repository.Repositories, original *model.LedgerJournal, postedBy *uint, description string) (*model.LedgerJournal, map[uint]money.Money, error) {
+	// Only money an admin posted directly can be taken back here; the journals
+	// of other flows have records that would be left out of step
+	switch original.EntryType {
+	case model.LedgerTopup, model.LedgerAdjustment:
+	case model.LedgerReversal:
+		return nil, nil, errors.New("a reversal cannot be reversed")
+	case model.LedgerAutoDebet:
+		// The debit paid a verified installment; reversing it alone would refund the wallet
+		return nil, nil, errors.New("an auto debet journal cannot be reversed")
+	case model.LedgerWithdrawal:
+		// The penarikan, or the refund on resignation, stays paid; money the bank
+		// sends back is a new top-up
+		return nil, nil, errors.New("a paid withdrawal cannot be reversed")
+	case model.LedgerSHUCredit:
+		// The SHU Anggota record stays credited
+		return nil, nil, errors.New("an SHU credit cannot be reversed")
+	case model.LedgerJasaSimpanan:
+		// The month stays recorded as credited, and the tax withheld is owed
+		return nil, nil, errors.New("jasa simpanan cannot be reversed")
+	case model.LedgerBerjangka, model.LedgerBungaBerjangka:
+		// The deposit's status and nominal follow these; break it early instead
+		return nil, nil, errors.New("a simpanan berjangka movement cannot be reversed")
+	case model.LedgerTransfer:
+		// The transfer stays berhasil and counts toward the daily limit; the
+		// recipient sends the money back with a transfer of their own
+		return nil, nil, errors.New("a sukarela transfer cannot be reversed")
+	default:
+		return nil, nil, fmt.Errorf("a %s journal cannot be reversed", original.EntryType)
+	}
+	reversed, err := repos.Ledger.IsReversed(original.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if reversed {
+		return nil, nil, errors.New("journal already reversed")
+	}
+
+	walletChanges := make(map[uint]money.Money)
+	entries := make([]model.LedgerEntry, 0, len(original.Entries))
+	for _, e := range original.Entries {
+		entries = append(entries, model.LedgerEntry{
+			Account:    e.Account,
+			SimpananID: e.SimpananID,
+			Debit:      e.Credit,
+			Credit:     e.Debit,
+		})
+		if e.Account == model.AkunSimpananAnggota && e.SimpananID != nil {
+			walletChanges[*e.SimpananID] += e.Debit - e.Credit
+		}
+	}
+
+	// Lock wallets in id order so concurrent reversals cannot deadlock
+	walletIDs := make([]uint, 0, len(walletChanges))
+	for walletID := range walletChanges {
+		walletIDs = append(walletIDs, walletID)
+	}
+	sort.Slice(walletIDs, func(i, j int) bool { return walletIDs[i] < walletIDs[j] })
+
+	for _, walletID := range walletIDs {
+		change := walletChanges[walletID]
+		wallet, err := repos.Simpanan.GetWalletByIDForUpdate(walletID)
+		if err != nil {
+			return nil, nil, err
+		}
+		wallet.Balance += change
+		if wallet.Balance < wallet.SaldoDitahan {
+			return nil, nil, errors.New("insufficient balance")
+		}
+		if err := repos.Simpanan.UpdateWallet(wallet); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	journal := &model.LedgerJournal{
+		EntryType:      model.LedgerReversal,
+		ReferenceTable: original.ReferenceTable,
+		ReferenceID:    original.ReferenceID,
+		ReversalOfID:   &original.ID,
+		Description:    description,
+		PostedBy:       postedBy,
+		Entries:        entries,
+	}
+	if err := repos.Ledger.PostJournal(journal); err != nil {
+		return nil, nil, err
+	}
+	return journal, walletChanges, nil
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+	"time"
+)
+
+// NotifikasiService gives members access to their own notifications
+type NotifikasiService struct {
+	repo *repository.NotifikasiRepository
+}
+
+// NewNotifikasiService creates a new service instance
+func NewNotifikasiService(repo *repository.NotifikasiRepository) *NotifikasiService {
+	return &NotifikasiService{repo: repo}
+}
+
+// List returns the requestor's notifications, newest first
+func (s *NotifikasiService) List(requestorID uint, unreadOnly bool) ([]model.Notifikasi, error) {
+	return s.repo.GetByUser(requestorID, unreadOnly)
+}
+
+// MarkRead marks one of the requestor's notifications as read
+func (s *NotifikasiService) MarkRead(requestorID uint, id uint) error {
+	return s.repo.MarkRead(id, requestorID, time.Now())
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"errors"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+	"koperasi-service/pkg/money"
+	"time"
+)
+
+// ErrPelunasanBerubah is returned when the amount confirmed by the admin no
+// longer matches the payoff amount, e.g. because a payment was verified since
+// the quote was made
+var ErrPelunasanBerubah = errors.New("pelunasan amount has changed, request a new quote")
+
+// PelunasanRincian is what an early settlement pays on one schedule row
+type PelunasanRincian struct {
+	JadwalAngsuranID  uint        `json:"jadwal_angsuran_id"`
+	AngsuranKe        int         `json:"angsuran_ke"`
+	TanggalJatuhTempo time.Time   `json:"tanggal_jatuh_tempo"`
+	Denda             money.Money `json:"denda"`
+	Bunga             money.Money `json:"bunga"`
+	Pokok             money.Money `json:"pokok"`
+	BungaDihapus      money.Money `json:"bunga_dihapus"` // Scheduled interest waived by the settlement
+}
+
+// PelunasanQuote is the amount that pays a loan off on a date
+type PelunasanQuote struct {
+	PinjamanID      uint                   `json:"pinjaman_id"`
+	Tanggal         time.Time              `json:"tanggal"`
+	Aturan          *model.AturanPelunasan `json:"aturan"`
+	KebijakanBunga  string                 `json:"kebijakan_bunga"`
+	SisaPokok       money.Money            `json:"sisa_pokok"`
+	BungaTertunggak money.Money            `json:"bunga_tertunggak"` // Unpaid interest of installments already due
+	BungaBerjalan   money.Money            `json:"bunga_berjalan"`   // Interest of the current period charged under the policy
+	BungaSisa       money.Money            `json:"bunga_sisa"`       // Interest after the current period charged under the policy
+	BungaDihapus    money.Money            `json:"bunga_dihapus"`    // Scheduled interest waived
+	Denda           money.Money            `json:"denda"`
+	Biaya           money.Money            `json:"biaya"` // Early-settlement fee
+	Total           money.Money            `json:"total"`
+	Rincian         []PelunasanRincian     `json:"rincian"`
+}
+
+// PelunasanInput is what the admin confirms when executing a settlement
+type PelunasanInput struct {
+	Tanggal            time.Time
+	Total              *money.Money // Quoted total; the settlement is refused if it no longer matches
+	NoRekening         string
+	BankName           string
+	ImageBuktiTransfer string
+	BuktiTransferID    *uint // Uploaded receipt; replaces ImageBuktiTransfer
+}
+
+// hitungPelunasan computes the payoff of a loan on tanggal. The denda due on
+// tanggal is charged on jadwal in place. Installments already due pay all their
+// bunga; the first installment not yet due pays bunga per the rule's policy;
+// later installments pay none, or under penuh their bunga less the rebate.
+func hitungPelunasan(jadwal []model.JadwalAngsuran, aturanDenda *model.AturanDenda, aturan *model.AturanPelunasan, tanggal time.Time) *PelunasanQuote {
+	terapkanDenda(jadwal, aturanDenda, tanggal)
+
+	kebijakan := model.BungaPelunasanAkrual
+	if aturan != nil {
+		kebijakan = aturan.KebijakanBunga
+	}
+	q := &PelunasanQuote{Tanggal: tanggal, Aturan: aturan, KebijakanBunga: kebijakan, Rincian: []PelunasanRincian{}}
+
+	berjalan := false
+	for i := range jadwal {
+		row := &jadwal[i]
+		if row.SisaTagihan() <= 0 {
+			continue
+		}
+
+		sisaBunga := row.SisaBunga()
+		var bunga money.Money
+		switch {
+		case !row.TanggalJatuhTempo.After(tanggal):
+			bunga = sisaBunga
+			q.BungaTertunggak += bunga
+		case !berjalan:
+			berjalan = true
+			bunga = sisaBunga
+			if kebijakan == model.BungaPelunasanAkrual {
+				mulai := addMonths(row.TanggalJatuhTempo, -1)
+				if i > 0 {
+					mulai = jadwal[i-1].TanggalJatuhTempo
+				}
+				akrual := row.Bunga.MulRatio(int64(hariTerlambat(mulai, tanggal)), int64(hariTerlambat(mulai, row.TanggalJatuhTempo)))
+				bunga = money.Min(money.Max(akrual-row.BungaDibayar, 0), sisaBunga)
+			}
+			q.BungaBerjalan += bunga
+		default:
+			if kebijakan == model.BungaPelunasanPenuh {
+				bunga = sisaBunga - sisaBunga.MulPercent(aturan.PersenRebate)
+			}
+			q.BungaSisa += bunga
+		}
+
+		r := PelunasanRincian{
+			JadwalAngsuranID:  row.ID,
+			AngsuranKe:        row.AngsuranKe,
+			TanggalJatuhTempo: row.TanggalJatuhTempo,
+			Denda:             row.SisaDenda(),
+			Bunga:             bunga,
+			Pokok:             row.SisaPokokAngsuran(),
+			BungaDihapus:      sisaBunga - bunga,
+		}
+		q.SisaPokok += r.Pokok
+		q.Denda += r.Denda
+		q.BungaDihapus += r.BungaDihapus
+		q.Rincian = append(q.Rincian, r)
+	}
+
+	if aturan != nil {
+		q.Biaya = q.SisaPokok.MulPercent(aturan.BiayaPersen) + aturan.BiayaTetap
+	}
+	q.Total = q.SisaPokok + q.BungaTertunggak + q.BungaBerjalan + q.BungaSisa + q.Denda + q.Biaya
+	return q
+}
+
+// checkPelunasan verifies that a loan can be settled on tanggal
+func checkPelunasan(p *model.Pinjaman, tanggal time.Time) error {
+	if p.Status != model.StatusPinjamanDicairkan && p.Status != model.StatusPinjamanMacet {
+		return errors.New("only dicairkan or macet pinjaman can be settled")
+	}
+	if p.TanggalPencairan != nil {
+		cair := p.TanggalPencairan.In(tanggal.Location())
+		if tanggal.Before(time.Date(cair.Year(), cair.Month(), cair.Day(), 0, 0, 0, 0, tanggal.Location())) {
+			return errors.New("tanggal pelunasan is before the disbursement")
+		}
+	}
+	return nil
+}
+
+// QuotePelunasan returns the payoff amount of a loan on tanggal without
+// charging anything. Access rules are the same as Get.
+func (s *PinjamanService) QuotePelunasan(requestorID uint, requestorRole string, id uint, tanggal time.Time) (*PelunasanQuote, error) {
+	p, err := s.Get(requestorID, requestorRole, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPelunasan(p, tanggal); err != nil {
+		return nil, err
+	}
+
+	aturanDenda, err := s.aturanDendaRepo.GetActive()
+	if err != nil {
+		return nil, err
+	}
+	aturan, err := s.aturanPelunasanRepo.GetActive()
+	if err != nil {
+		return nil, err
+	}
+	jadwal, err := s.jadwalRepo.GetByPinjaman(p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	q := hitungPelunasan(jadwal, aturanDenda, aturan, tanggal)
+	q.PinjamanID = p.ID
+	return q, nil
+}
+
+// Lunasi settles a loan early (admin only, same access as Approve). It records
+// one verified pelunasan Angsuran for the quoted total, closes every open
+// schedule row with the waived interest taken off it, and marks the loan lunas.
+func (s *PinjamanService) Lunasi(requestorID uint, requestorRole string, id uint, input PelunasanInput) (*model.Angsuran, error) {
+	if input.Tanggal.After(time.Now()) {
+		return nil, errors.New("tanggal pelunasan must not be in the future")
+	}
+
+	aturanDenda, err := s.aturanDendaRepo.GetActive()
+	if err != nil {
+		return nil, err
+	}
+	aturan, err := s.aturanPelunasanRepo.GetActive()
+	if err != nil {
+		return nil, err
+	}
+
+	var result *model.Angsuran
+	err = s.uow.Do(func(repos *repository.Repositories) error {
+		p, err := repos.Pinjaman.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if err := s.checkDecisionAccess(requestorID, requestorRole, p); err != nil {
+			return err
+		}
+		result, err = lunasiPinjaman(repos, requestorID, p, input, aturanDenda, aturan)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// lunasiPinjaman executes the settlement of a loan locked by the caller, whose
+// access has already been checked
+func lunasiPinjaman(repos *repository.Repositories, requestorID uint, p *model.Pinjaman, input PelunasanInput, aturanDenda *model.AturanDenda, aturan *model.AturanPelunasan) (*model.Angsuran, error) {
+	if err := checkPelunasan(p, input.Tanggal); err != nil {
+		return nil, err
+	}
+	// A payment still waiting for verification is not in the quote and could
+	// not be verified once the loan is lunas
+	pending, err := repos.Angsuran.HasPendingByPinjaman(p.ID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, errors.New("pinjaman has a pending angsuran, verify or delete it first")
+	}
+
+	jadwal, err := ensureJadwal(repos, p)
+	if err != nil {
+		return nil, err
+	}
+	q := hitungPelunasan(jadwal, aturanDenda, aturan, input.Tanggal)
+	if input.Total != nil && *input.Total != q.Total {
+		return nil, ErrPelunasanBerubah
+	}
+
+	now := time.Now()
+	angsuranKe, err := repos.Angsuran.GetNextAngsuranKe(p.ID)
+	if err != nil {
+		return nil, err
+	}
+	a := &model.Angsuran{
+		PinjamanID:         p.ID,
+		AngsuranKe:         angsuranKe,
+		TanggalBayar:       input.Tanggal,
+		Pokok:              q.SisaPokok,
+		Bunga:              q.BungaTertunggak + q.BungaBerjalan + q.BungaSisa,
+		Denda:              q.Denda,
+		Biaya:              q.Biaya,
+		TotalBayar:         q.Total,
+		UserID:             p.UserID,
+		Status:             "verified",
+		Jenis:              model.JenisAngsuranPelunasan,
+		ImageBuktiTransfer: input.ImageBuktiTransfer,
+		BuktiTransferID:    input.BuktiTransferID,
+		NoRekening:         input.NoRekening,
+		BankName:           input.BankName,
+		DiverifikasiOleh:   &requestorID,
+		TanggalVerifikasi:  &now,
+	}
+	if a.BuktiTransferID != nil {
+		a.ImageBuktiTransfer = model.BerkasURL(*a.BuktiTransferID)
+	}
+	if err := repos.Angsuran.Create(a); err != nil {
+		return nil, err
+	}
+	if a.BuktiTransferID != nil {
+		if err := lampirkanBukti(repos, *a.BuktiTransferID, requestorID, model.BerkasAngsuran, a.ID, p.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Close the open rows; waived interest is taken off the schedule
+	byID := make(map[uint]PelunasanRincian, len(q.Rincian))
+	for _, r := range q.Rincian {
+		byID[r.JadwalAngsuranID] = r
+	}
+	var alokasi []model.AlokasiAngsuran
+	for i := range jadwal {
+		row := &jadwal[i]
+		r, ok := byID[row.ID]
+		if !ok {
+			continue
+		}
+		row.DendaDibayar += r.Denda
+		row.BungaDibayar += r.Bunga
+		row.PokokDibayar += r.Pokok
+		row.Bunga -= r.BungaDihapus
+		row.TotalAngsuran = row.Pokok + row.Bunga
+		row.Status = model.JadwalLunas
+		row.TanggalLunas = &now
+		if err := repos.Jadwal.Update(row); err != nil {
+			return nil, err
+		}
+		if r.Denda+r.Bunga+r.Pokok > 0 {
+			alokasi = append(alokasi, model.AlokasiAngsuran{
+				AngsuranID:       a.ID,
+				JadwalAngsuranID: row.ID,
+				AngsuranKe:       row.AngsuranKe,
+				Denda:            r.Denda,
+				Bunga:            r.Bunga,
+				Pokok:            r.Pokok,
+			})
+		}
+	}
+	if err := repos.Angsuran.CreateAlokasi(alokasi); err != nil {
+		return nil, err
+	}
+
+	p.SisaAngsuran = 0
+	if err := markLunas(p, input.Tanggal); err != nil {
+		return nil, err
+	}
+	if err := repos.Pinjaman.Update(p); err != nil {
+		return nil, err
+	}
+	if err := repos.Agunan.KembalikanByPinjaman(p.ID, &requestorID, now); err != nil {
+		return nil, err
+	}
+
+	a.Alokasi = alokasi
+	return a, nil
+}
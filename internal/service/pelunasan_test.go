@@ -0,0 +1,119 @@
+package service
+
+import (
+	"koperasi-service/internal/model"
+	"koperasi-service/pkg/money"
+	"testing"
+	"time"
+)
+
+// jadwalPelunasan is four installments of 100 pokok and 30 bunga due on the
+// first of January to April 2024, the first one paid.
+func jadwalPelunasan() []model.JadwalAngsuran {
+	rp := money.FromRupiah
+	jadwal := make([]model.JadwalAngsuran, 4)
+	for i := range jadwal {
+		jadwal[i].ID = uint(i + 1)
+		jadwal[i].AngsuranKe = i + 1
+		jadwal[i].TanggalJatuhTempo = time.Date(2024, time.Month(i+1), 1, 0, 0, 0, 0, time.UTC)
+		jadwal[i].Pokok = rp(100)
+		jadwal[i].Bunga = rp(30)
+		jadwal[i].Status = model.JadwalBelumBayar
+	}
+	jadwal[0].PokokDibayar = rp(100)
+	jadwal[0].BungaDibayar = rp(30)
+	jadwal[0].Status = model.JadwalLunas
+	return jadwal
+}
+
+func TestHitungPelunasan(t *testing.T) {
+	rp := money.FromRupiah
+	// February 11: the second installment is overdue and the third, due on
+	// March 1, is the current period (10 of its 29 days have passed)
+	tanggal := time.Date(2024, time.February, 11, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		aturan        *model.AturanPelunasan
+		aturanDenda   *model.AturanDenda
+		bungaDibayar  money.Money // Already paid on the current installment
+		wantBerjalan  money.Money
+		wantSisa      money.Money
+		wantDihapus   money.Money
+		wantDenda     money.Money
+		wantBiaya     money.Money
+		wantTotal     money.Money
+		wantKebijakan string
+	}{
+		{
+			name:         "akrual without a rule",
+			wantBerjalan: rp(10), wantDihapus: rp(50),
+			wantTotal:     rp(340),
+			wantKebijakan: model.BungaPelunasanAkrual,
+		},
+		{
+			name:         "akrual less interest already paid",
+			bungaDibayar: rp(4),
+			wantBerjalan: rp(6), wantDihapus: rp(50),
+			wantTotal:     rp(336),
+			wantKebijakan: model.BungaPelunasanAkrual,
+		},
+		{
+			name:         "akrual with fee",
+			aturan:       &model.AturanPelunasan{KebijakanBunga: model.BungaPelunasanAkrual, BiayaPersen: 1, BiayaTetap: rp(5)},
+			wantBerjalan: rp(10), wantDihapus: rp(50), wantBiaya: rp(8),
+			wantTotal:     rp(348),
+			wantKebijakan: model.BungaPelunasanAkrual,
+		},
+		{
+			name:         "akrual with denda on the overdue installment",
+			aturanDenda:  &model.AturanDenda{PersenPerHari: 1},
+			wantBerjalan: rp(10), wantDihapus: rp(50), wantDenda: rp(13),
+			wantTotal:     rp(353),
+			wantKebijakan: model.BungaPelunasanAkrual,
+		},
+		{
+			name:         "bulan_berjalan",
+			aturan:       &model.AturanPelunasan{KebijakanBunga: model.BungaPelunasanBulanBerjalan},
+			wantBerjalan: rp(30), wantDihapus: rp(30),
+			wantTotal:     rp(360),
+			wantKebijakan: model.BungaPelunasanBulanBerjalan,
+		},
+		{
+			name:         "penuh with rebate",
+			aturan:       &model.AturanPelunasan{KebijakanBunga: model.BungaPelunasanPenuh, PersenRebate: 50},
+			wantBerjalan: rp(30), wantSisa: rp(15), wantDihapus: rp(15),
+			wantTotal:     rp(375),
+			wantKebijakan: model.BungaPelunasanPenuh,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jadwal := jadwalPelunasan()
+			jadwal[2].BungaDibayar = tt.bungaDibayar
+			q := hitungPelunasan(jadwal, tt.aturanDenda, tt.aturan, tanggal)
+
+			if q.KebijakanBunga != tt.wantKebijakan {
+				t.Errorf("kebijakan %s, want %s", q.KebijakanBunga, tt.wantKebijakan)
+			}
+			if q.SisaPokok != rp(300) || q.BungaTertunggak != rp(30) {
+				t.Errorf("sisa pokok %s bunga tertunggak %s, want 300 and 30", q.SisaPokok, q.BungaTertunggak)
+			}
+			if q.BungaBerjalan != tt.wantBerjalan || q.BungaSisa != tt.wantSisa || q.BungaDihapus != tt.wantDihapus {
+				t.Errorf("bunga berjalan %s sisa %s dihapus %s, want %s %s %s", q.BungaBerjalan, q.BungaSisa, q.BungaDihapus, tt.wantBerjalan, tt.wantSisa, tt.wantDihapus)
+			}
+			if q.Denda != tt.wantDenda || q.Biaya != tt.wantBiaya || q.Total != tt.wantTotal {
+				t.Errorf("denda %s biaya %s total %s, want %s %s %s", q.Denda, q.Biaya, q.Total, tt.wantDenda, tt.wantBiaya, tt.wantTotal)
+			}
+			if len(q.Rincian) != 3 || q.Rincian[0].AngsuranKe != 2 {
+				t.Fatalf("rincian %+v, want installments 2 to 4", q.Rincian)
+			}
+			var total money.Money
+			for _, r := range q.Rincian {
+				total += r.Denda + r.Bunga + r.Pokok
+			}
+			if total+q.Biaya != q.Total {
+				t.Errorf("rincian add up to %s, total %s", total+q.Biaya, q.Total)
+			}
+		})
+	}
+}
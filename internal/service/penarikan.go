@@ -0,0 +1,249 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+	"koperasi-service/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PenarikanInput is a member's withdrawal request
+type PenarikanInput struct {
+	WalletType string // Defaults to sukarela; only withdrawable types can be withdrawn
+	Jumlah     money.Money
+	NoRekening string
+	BankName   string
+	AtasNama   string
+	Keterangan string
+}
+
+// PembayaranPenarikanInput is the transfer the admin made to pay a withdrawal
+type PembayaranPenarikanInput struct {
+	ReferensiTransfer  string
+	ImageBuktiTransfer string
+}
+
+// AjukanPenarikan requests a withdrawal from one of the member's withdrawable
+// wallets, sukarela by default. The amount is held on the wallet until the
+// request is rejected or paid; the minimum balance of the type must remain.
+func (s *SimpananService) AjukanPenarikan(userID uint, input PenarikanInput) (*model.PenarikanSimpanan, error) {
+	if input.WalletType == "" {
+		input.WalletType = "sukarela"
+	}
+	jenis, err := s.jenisSimpananRepo.GetByKode(input.WalletType)
+	if err != nil {
+		return nil, errors.New("invalid wallet type")
+	}
+	// Pokok and wajib only leave on resignation, whatever the catalog says
+	if !jenis.BisaDitarik || jenis.HanyaSaatKeluar() {
+		return nil, fmt.Errorf("simpanan %s cannot be withdrawn", jenis.Kode)
+	}
+	if input.Jumlah <= 0 {
+		return nil, errors.New("jumlah must be positive")
+	}
+	if input.NoRekening == "" {
+		return nil, errors.New("no rekening is required")
+	}
+	if input.BankName == "" {
+		return nil, errors.New("bank name is required")
+	}
+
+	wallet, err := s.repo.GetWalletByUserAndType(userID, input.WalletType)
+	if err != nil {
+		return nil, errors.New("wallet not found")
+	}
+
+	var result *model.PenarikanSimpanan
+	err = s.uow.Do(func(repos *repository.Repositories) error {
+		wallet, err := repos.Simpanan.GetWalletByIDForUpdate(wallet.ID)
+		if err != nil {
+			return err
+		}
+		if wallet.SaldoTersedia()-jenis.SaldoMinimal < input.Jumlah {
+			return errors.New("insufficient balance")
+		}
+
+		wallet.SaldoDitahan += input.Jumlah
+		if err := repos.Simpanan.UpdateWallet(wallet); err != nil {
+			return err
+		}
+
+		p := &model.PenarikanSimpanan{
+			SimpananID: wallet.ID,
+			UserID:     userID,
+			Jumlah:     input.Jumlah,
+			NoRekening: input.NoRekening,
+			BankName:   input.BankName,
+			AtasNama:   input.AtasNama,
+			Keterangan: input.Keterangan,
+			Status:     model.PenarikanPending,
+		}
+		if err := repos.Penarikan.Create(p); err != nil {
+			return err
+		}
+		result = p
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListPenarikan returns withdrawal requests. Admins see every request, members
+// only their own; status optionally filters by status.
+func (s *SimpananService) ListPenarikan(requestorID uint, requestorRole string, status string) ([]model.PenarikanSimpanan, error) {
+	if requestorRole == "super_admin" || requestorRole == "admin" {
+		return s.penarikanRepo.List(0, status)
+	}
+	return s.penarikanRepo.List(requestorID, status)
+}
+
+// GetPenarikan returns one withdrawal request. Members can only see their own.
+func (s *SimpananService) GetPenarikan(requestorID uint, requestorRole string, id uint) (*model.PenarikanSimpanan, error) {
+	p, err := s.penarikanRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if requestorRole != "super_admin" && requestorRole != "admin" && p.UserID != requestorID {
+		return nil, errors.New("forbidden")
+	}
+	return p, nil
+}
+
+// SetujuiPenarikan approves a pending withdrawal (admin only). The amount stays
+// held until the transfer is recorded with BayarPenarikan.
+func (s *SimpananService) SetujuiPenarikan(adminID uint, adminRole string, id uint) (*model.PenarikanSimpanan, error) {
+	return s.putuskanPenarikan(adminRole, id, func(repos *repository.Repositories, p *model.PenarikanSimpanan, now time.Time) error {
+		if p.Status != model.PenarikanPending {
+			return fmt.Errorf("penarikan can only be approved when pending, it is %s", p.Status)
+		}
+		p.Status = model.PenarikanApproved
+		p.DisetujuiOleh = &adminID
+		p.TanggalDisetujui = &now
+		return nil
+	})
+}
+
+// TolakPenarikan rejects a pending or approved withdrawal (admin only) and
+// releases the held amount
+func (s *SimpananService) TolakPenarikan(adminID uint, adminRole string, id uint, alasan string) (*model.PenarikanSimpanan, error) {
+	if alasan == "" {
+		return nil, errors.New("alasan penolakan is required")
+	}
+	return s.putuskanPenarikan(adminRole, id, func(repos *repository.Repositories, p *model.PenarikanSimpanan, now time.Time) error {
+		if p.Status != model.PenarikanPending && p.Status != model.PenarikanApproved {
+			return fmt.Errorf("penarikan can only be rejected when pending or approved, it is %s", p.Status)
+		}
+		if _, err := lepasSaldoDitahan(repos, p); err != nil {
+			return err
+		}
+		p.Status = model.PenarikanRejected
+		p.DitolakOleh = &adminID
+		p.TanggalDitolak = &now
+		p.AlasanPenolakan = alasan
+		return nil
+	})
+}
+
+// BayarPenarikan records the transfer of an approved withdrawal (admin only).
+// The hold is released and the amount is debited from the wallet through the
+// ledger, with a withdrawal transaction in the wallet history.
+func (s *SimpananService) BayarPenarikan(adminID uint, adminRole string, id uint, input PembayaranPenarikanInput) (*model.PenarikanSimpanan, error) {
+	if input.ImageBuktiTransfer == "" {
+		return nil, errors.New("image bukti transfer is required")
+	}
+	return s.putuskanPenarikan(adminRole, id, func(repos *repository.Repositories, p *model.PenarikanSimpanan, now time.Time) error {
+		if p.Status != model.PenarikanApproved {
+			return fmt.Errorf("penarikan can only be paid when approved, it is %s", p.Status)
+		}
+		wallet, err := lepasSaldoDitahan(repos, p)
+		if err != nil {
+			return err
+		}
+
+		description := fmt.Sprintf("Penarikan ke %s %s", p.BankName, p.NoRekening)
+		transaction := &model.SimpananTransaction{
+			SimpananID:   wallet.ID,
+			Type:         "withdrawal",
+			Amount:       -p.Jumlah,
+			Description:  description,
+			Status:       "verified",
+			VerifiedByID: &adminID,
+			VerifiedAt:   &gorm.DeletedAt{Time: now, Valid: true},
+		}
+		if err := repos.Simpanan.CreateTransaction(transaction); err != nil {
+			return err
+		}
+
+		journal, err := postWalletMovement(repos, wallet, walletMovement{
+			EntryType:      model.LedgerWithdrawal,
+			CounterAccount: model.AkunKas,
+			Amount:         -p.Jumlah,
+			ReferenceTable: "simpanan_transactions",
+			ReferenceID:    transaction.ID,
+			Description:    description,
+			PostedBy:       &adminID,
+			PostedAt:       now,
+		})
+		if err != nil {
+			return err
+		}
+		if err := repos.Simpanan.SetTransactionJournal(transaction.ID, journal.ID); err != nil {
+			return err
+		}
+
+		p.Status = model.PenarikanPaid
+		p.DibayarOleh = &adminID
+		p.TanggalDibayar = &now
+		p.ReferensiTransfer = input.ReferensiTransfer
+		p.ImageBuktiTransfer = input.ImageBuktiTransfer
+		p.SimpananTransactionID = &transaction.ID
+		return nil
+	})
+}
+
+// putuskanPenarikan locks a withdrawal request, applies an admin decision to it
+// and saves it in one unit of work
+func (s *SimpananService) putuskanPenarikan(adminRole string, id uint, apply func(repos *repository.Repositories, p *model.PenarikanSimpanan, now time.Time) error) (*model.PenarikanSimpanan, error) {
+	if adminRole != "super_admin" && adminRole != "admin" {
+		return nil, errors.New("forbidden")
+	}
+
+	var result *model.PenarikanSimpanan
+	err := s.uow.Do(func(repos *repository.Repositories) error {
+		p, err := repos.Penarikan.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if err := apply(repos, p, time.Now()); err != nil {
+			return err
+		}
+		if err := repos.Penarikan.Update(p); err != nil {
+			return err
+		}
+		result = p
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// lepasSaldoDitahan locks the wallet of a withdrawal and releases its hold
+func lepasSaldoDitahan(repos *repository.Repositories, p *model.PenarikanSimpanan) (*model.Simpanan, error) {
+	wallet, err := repos.Simpanan.GetWalletByIDForUpdate(p.SimpananID)
+	if err != nil {
+		return nil, err
+	}
+	wallet.SaldoDitahan = money.Max(wallet.SaldoDitahan-p.Jumlah, 0)
+	if err := repos.Simpanan.UpdateWallet(wallet); err != nil {
+		return nil, err
+	}
+	return wallet, nil
+}
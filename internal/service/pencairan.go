@@ -0,0 +1,182 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+	"koperasi-service/pkg/money"
+	"time"
+)
+
+// PencairanInput is what the admin records when disbursing a loan
+type PencairanInput struct {
+	ReferensiTransfer  string
+	NoRekening         string // Overrides the loan's disbursement account when set
+	BankName           string
+	ImageBuktiTransfer string
+	BuktiTransferID    *uint // Uploaded receipt; replaces ImageBuktiTransfer
+	PremiAsuransi      money.Money
+	LunasiPinjamanID   *uint // Previous loan of the borrower paid off from the disbursement
+}
+
+// Disburse moves an approved loan to dicairkan (same access as Approve). The
+// admin and provision fees fixed at application, the insurance premium and the
+// payoff of a previous loan are deducted from the loan amount; the rest is
+// transferred to the member. Installments fall due counting from the
+// disbursement date.
+func (s *PinjamanService) Disburse(requestorID uint, requestorRole string, id uint, input PencairanInput) (*model.Pinjaman, *model.Pencairan, error) {
+	if input.ReferensiTransfer == "" {
+		return nil, nil, errors.New("referensi pencairan is required")
+	}
+	if input.PremiAsuransi < 0 {
+		return nil, nil, errors.New("premi asuransi must not be negative")
+	}
+	if input.LunasiPinjamanID != nil && *input.LunasiPinjamanID == id {
+		return nil, nil, errors.New("the pelunasan pinjaman must be a previous loan")
+	}
+
+	var aturanDenda *model.AturanDenda
+	var aturanPelunasan *model.AturanPelunasan
+	if input.LunasiPinjamanID != nil {
+		var err error
+		if aturanDenda, err = s.aturanDendaRepo.GetActive(); err != nil {
+			return nil, nil, err
+		}
+		if aturanPelunasan, err = s.aturanPelunasanRepo.GetActive(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var resultP *model.Pinjaman
+	var resultPC *model.Pencairan
+	err := s.uow.Do(func(repos *repository.Repositories) error {
+		p, err := repos.Pinjaman.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if err := s.checkDecisionAccess(requestorID, requestorRole, p); err != nil {
+			return err
+		}
+		if err := checkTransition(p.Status, model.StatusPinjamanDicairkan); err != nil {
+			return err
+		}
+
+		if input.NoRekening != "" {
+			p.NoRekeningPencairan = input.NoRekening
+		}
+		if input.BankName != "" {
+			p.BankName = input.BankName
+		}
+		if p.NoRekeningPencairan == "" {
+			return errors.New("no rekening pencairan is required")
+		}
+
+		now := time.Now()
+		pc := &model.Pencairan{
+			PinjamanID:         p.ID,
+			JumlahBruto:        p.JumlahPinjaman,
+			BiayaAdmin:         p.BiayaAdmin,
+			BiayaProvisi:       p.BiayaProvisi,
+			PremiAsuransi:      input.PremiAsuransi,
+			ReferensiTransfer:  input.ReferensiTransfer,
+			NoRekening:         p.NoRekeningPencairan,
+			BankName:           p.BankName,
+			ImageBuktiTransfer: input.ImageBuktiTransfer,
+			BuktiTransferID:    input.BuktiTransferID,
+			DicairkanOleh:      requestorID,
+			TanggalPencairan:   now,
+		}
+
+		if input.LunasiPinjamanID != nil {
+			lama, err := repos.Pinjaman.GetByIDForUpdate(*input.LunasiPinjamanID)
+			if err != nil {
+				return err
+			}
+			if lama.UserID != p.UserID {
+				return errors.New("the pelunasan pinjaman must belong to the same member")
+			}
+			a, err := lunasiPinjaman(repos, requestorID, lama, PelunasanInput{
+				Tanggal:    now,
+				NoRekening: p.NoRekeningPencairan,
+				BankName:   p.BankName,
+			}, aturanDenda, aturanPelunasan)
+			if err != nil {
+				return err
+			}
+			pc.PelunasanPinjamanID = &lama.ID
+			pc.PelunasanAngsuranID = &a.ID
+			pc.JumlahPelunasan = a.TotalBayar
+		}
+
+		pc.JumlahBersih = pc.JumlahBruto - pc.BiayaAdmin - pc.BiayaProvisi - pc.PremiAsuransi - pc.JumlahPelunasan
+		if pc.JumlahBersih <= 0 {
+			return errors.New("jumlah bersih pencairan must be positive")
+		}
+
+		jadwal, err := s.rescheduleFrom(p, now)
+		if err != nil {
+			return err
+		}
+		p.Status = model.StatusPinjamanDicairkan
+		p.ReferensiPencairan = input.ReferensiTransfer
+		p.DicairkanOleh = &requestorID
+		p.TanggalPencairan = &now
+		if err := repos.Pinjaman.Update(p); err != nil {
+			return err
+		}
+		if err := saveJadwal(repos, p, jadwal); err != nil {
+			return err
+		}
+		if pc.BuktiTransferID != nil {
+			pc.ImageBuktiTransfer = model.BerkasURL(*pc.BuktiTransferID)
+		}
+		if err := repos.Pencairan.Create(pc); err != nil {
+			return err
+		}
+		if pc.BuktiTransferID != nil {
+			if err := lampirkanBukti(repos, *pc.BuktiTransferID, requestorID, model.BerkasPencairan, pc.ID, p.UserID); err != nil {
+				return err
+			}
+		}
+
+		metadata, err := json.Marshal(pc)
+		if err != nil {
+			return err
+		}
+		if err := repos.Transaksi.Create(&model.TransactionHistory{
+			UserID:          p.UserID,
+			TransactionType: "PINJAMAN",
+			ReferenceTable:  "pencairan",
+			ReferenceID:     pc.ID,
+			Amount:          pc.JumlahBersih,
+			Status:          "COMPLETED",
+			TransactionDate: now,
+			VerifiedBy:      requestorID,
+			VerifiedAt:      &now,
+			Description:     fmt.Sprintf("Pencairan pinjaman %s", p.KodePinjaman),
+			Metadata:        string(metadata),
+		}); err != nil {
+			return err
+		}
+
+		resultP = p
+		resultPC = pc
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return resultP, resultPC, nil
+}
+
+// GetPencairan returns the disbursement record of a loan with the same access
+// rules as Get
+func (s *PinjamanService) GetPencairan(requestorID uint, requestorRole string, id uint) (*model.Pencairan, error) {
+	p, err := s.Get(requestorID, requestorRole, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.pencairanRepo.GetByPinjaman(p.ID)
+}
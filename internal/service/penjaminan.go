@@ -0,0 +1,284 @@
+package service
+
+import (
+	"errors"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+	"koperasi-service/pkg/money"
+)
+
+// checkJaminanEditable verifies that the guarantors and collateral of a loan
+// may still be changed
+func checkJaminanEditable(p *model.Pinjaman) error {
+	if p.Status != model.StatusPinjamanProses && p.Status != model.StatusPinjamanDisetujui {
+		return errors.New("pinjaman can only be edited before disbursement")
+	}
+	return nil
+}
+
+// ListPenjamin returns the guarantors of a loan with the same access rules as Get
+func (s *PinjamanService) ListPenjamin(requestorID uint, requestorRole string, id uint) ([]model.Penjamin, error) {
+	p, err := s.Get(requestorID, requestorRole, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.penjaminRepo.GetByPinjaman(p.ID)
+}
+
+// TambahPenjamin adds a co-member as guarantor of a loan that has not been
+// disbursed. The guarantor must pass cekPenjamin.
+func (s *PinjamanService) TambahPenjamin(requestorID uint, requestorRole string, id uint, pj *model.Penjamin) (*model.Penjamin, error) {
+	p, err := s.Get(requestorID, requestorRole, id)
+	if err != nil {
+		return nil, err
+	}
+	aturan, err := s.aturanKelayakanUntuk(p)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.uow.Do(func(repos *repository.Repositories) error {
+		p, err = repos.Pinjaman.GetByIDForUpdate(p.ID)
+		if err != nil {
+			return err
+		}
+		if err := checkJaminanEditable(p); err != nil {
+			return err
+		}
+		existing, err := repos.Penjamin.GetByPinjaman(p.ID)
+		if err != nil {
+			return err
+		}
+		for _, other := range existing {
+			if other.UserID == pj.UserID {
+				return errors.New("penjamin already guarantees this pinjaman")
+			}
+		}
+		// The guarantor lock keeps two guarantees of the same member apart
+		if _, err := repos.Users.FindByIDForUpdate(pj.UserID); err != nil {
+			return err
+		}
+		if err := cekPenjamin(repos, aturan, p, pj.UserID); err != nil {
+			return err
+		}
+
+		pj.PinjamanID = p.ID
+		pj.CreatedBy = requestorID
+		return repos.Penjamin.Create(pj)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pj, nil
+}
+
+// HapusPenjamin removes a guarantor from a loan that has not been disbursed
+func (s *PinjamanService) HapusPenjamin(requestorID uint, requestorRole string, id, penjaminID uint) error {
+	p, err := s.Get(requestorID, requestorRole, id)
+	if err != nil {
+		return err
+	}
+	return s.uow.Do(func(repos *repository.Repositories) error {
+		p, err = repos.Pinjaman.GetByIDForUpdate(p.ID)
+		if err != nil {
+			return err
+		}
+		if err := checkJaminanEditable(p); err != nil {
+			return err
+		}
+		pj, err := repos.Penjamin.GetByID(penjaminID)
+		if err != nil {
+			return err
+		}
+		if pj.PinjamanID != p.ID {
+			return errors.New("penjamin does not belong to this pinjaman")
+		}
+		return repos.Penjamin.Delete(pj.ID)
+	})
+}
+
+// ListAgunan returns the collateral of a loan with the same access rules as Get
+func (s *PinjamanService) ListAgunan(requestorID uint, requestorRole string, id uint) ([]model.Agunan, error) {
+	p, err := s.Get(requestorID, requestorRole, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.agunanRepo.GetByPinjaman(p.ID)
+}
+
+// TambahAgunan records a collateral item, held by the koperasi, for a loan
+// that has not been disbursed
+func (s *PinjamanService) TambahAgunan(requestorID uint, requestorRole string, id uint, a *model.Agunan) (*model.Agunan, error) {
+	if !model.ValidJenisAgunan[a.Jenis] {
+		return nil, errors.New("invalid jenis agunan")
+	}
+	if a.NilaiTaksiran < 0 {
+		return nil, errors.New("nilai taksiran must not be negative")
+	}
+	p, err := s.Get(requestorID, requestorRole, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.uow.Do(func(repos *repository.Repositories) error {
+		p, err = repos.Pinjaman.GetByIDForUpdate(p.ID)
+		if err != nil {
+			return err
+		}
+		if err := checkJaminanEditable(p); err != nil {
+			return err
+		}
+		a.PinjamanID = p.ID
+		a.Status = model.StatusAgunanDitahan
+		a.CreatedBy = requestorID
+		return repos.Agunan.Create(a)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// HapusAgunan removes a collateral item from a loan that has not been disbursed
+func (s *PinjamanService) HapusAgunan(requestorID uint, requestorRole string, id, agunanID uint) error {
+	p, err := s.Get(requestorID, requestorRole, id)
+	if err != nil {
+		return err
+	}
+	return s.uow.Do(func(repos *repository.Repositories) error {
+		p, err = repos.Pinjaman.GetByIDForUpdate(p.ID)
+		if err != nil {
+			return err
+		}
+		if err := checkJaminanEditable(p); err != nil {
+			return err
+		}
+		a, err := repos.Agunan.GetByID(agunanID)
+		if err != nil {
+			return err
+		}
+		if a.PinjamanID != p.ID {
+			return errors.New("agunan does not belong to this pinjaman")
+		}
+		return repos.Agunan.Delete(a.ID)
+	})
+}
+
+// jaminanTerpenuhi checks the guarantors and collateral of a loan against its
+// product before approval, and checks every guarantor again since their loans
+// may have changed since they were added
+func (s *PinjamanService) jaminanTerpenuhi(repos *repository.Repositories, p *model.Pinjaman) error {
+	penjamin, err := repos.Penjamin.GetByPinjaman(p.ID)
+	if err != nil {
+		return err
+	}
+	agunan, err := repos.Agunan.GetByPinjaman(p.ID)
+	if err != nil {
+		return err
+	}
+
+	if p.JenisPinjamanID != nil {
+		jenis, err := s.jenisPinjamanRepo.GetByID(*p.JenisPinjamanID)
+		if err != nil {
+			return err
+		}
+		if jenis.WajibPenjamin && len(penjamin) == 0 {
+			return errors.New("pinjaman requires a penjamin")
+		}
+		var nilai money.Money
+		for _, a := range agunan {
+			if a.Status == model.StatusAgunanDitahan {
+				nilai += a.NilaiTaksiran
+			}
+		}
+		if jenis.WajibAgunan && len(agunan) == 0 {
+			return errors.New("pinjaman requires agunan")
+		}
+		if jenis.MinimalNilaiAgunan > 0 && nilai < p.JumlahPinjaman.MulPercent(jenis.MinimalNilaiAgunan) {
+			return errors.New("pinjaman requires agunan worth the minimum share of jumlah pinjaman")
+		}
+	}
+
+	if len(penjamin) == 0 {
+		return nil
+	}
+	aturan, err := s.aturanKelayakanUntuk(p)
+	if err != nil {
+		return err
+	}
+	for _, pj := range penjamin {
+		if _, err := repos.Users.FindByIDForUpdate(pj.UserID); err != nil {
+			return err
+		}
+		if err := cekPenjamin(repos, aturan, p, pj.UserID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cekPenjamin checks that a member may guarantee p: they are not the borrower,
+// are an active anggota and have no macet loan. Under the rule's
+// KelipatanPenjaminan their own running principal plus the principal of the running loans they guarantee,
+// p included, must fit their simpanan balances × that multiple.
+func cekPenjamin(repos *repository.Repositories, aturan *model.AturanKelayakan, p *model.Pinjaman, userID uint) error {
+	if userID == p.UserID {
+		return errors.New("penjamin must not be the borrower")
+	}
+	user, err := repos.Users.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.StatusKeanggotaan != model.StatusAnggotaAktif {
+		return errors.New("penjamin is not an active anggota")
+	}
+	macet, err := repos.Pinjaman.GetByUserAndStatus(userID, model.StatusPinjamanMacet)
+	if err != nil {
+		return err
+	}
+	if len(macet) > 0 {
+		return errors.New("penjamin has a pinjaman in macet")
+	}
+	if aturan == nil || aturan.KelipatanPenjaminan <= 0 {
+		return nil
+	}
+
+	wallets, err := repos.Simpanan.GetUserWallets(userID)
+	if err != nil {
+		return err
+	}
+	// Amounts held for pending withdrawals do not back a guarantee
+	var totalSimpanan money.Money
+	for i := range wallets {
+		totalSimpanan += wallets[i].SaldoTersedia()
+	}
+
+	eksposur, err := sisaPokokBerjalan(repos, p)
+	if err != nil {
+		return err
+	}
+	sendiri, err := repos.Pinjaman.GetByUserAndStatus(userID, pinjamanBerjalan...)
+	if err != nil {
+		return err
+	}
+	dijamin, err := repos.Penjamin.GetPinjamanDijamin(userID, pinjamanBerjalan...)
+	if err != nil {
+		return err
+	}
+	for _, list := range [][]model.Pinjaman{sendiri, dijamin} {
+		for i := range list {
+			if list[i].ID == p.ID {
+				continue
+			}
+			sisa, err := sisaPokokBerjalan(repos, &list[i])
+			if err != nil {
+				return err
+			}
+			eksposur += sisa
+		}
+	}
+	if eksposur > totalSimpanan.MulFactor(aturan.KelipatanPenjaminan) {
+		return errors.New("penjamin would exceed the maximum guarantee exposure")
+	}
+	return nil
+}
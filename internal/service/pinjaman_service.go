@@ -5,18 +5,69 @@ import (
 	"fmt"
 	"koperasi-service/internal/model"
 	"koperasi-service/internal/repository"
+	"koperasi-service/pkg/money"
 	"time"
 )
 
 // PinjamanService handles business logic for Pinjaman with role constraints
 type PinjamanService struct {
-	repo     *repository.PinjamanRepository
-	userRepo *repository.UserRepository
+	repo                *repository.PinjamanRepository
+	userRepo            *repository.UserRepository
+	bungaOptionRepo     repository.BungaOptionRepository
+	jadwalRepo          *repository.JadwalAngsuranRepository
+	aturanDendaRepo     repository.AturanRepository[model.AturanDenda]
+	kolektibilitasRepo  *repository.KolektibilitasRepository
+	aturanKelayakanRepo repository.AturanRepository[model.AturanKelayakan]
+	aturanPelunasanRepo repository.AturanRepository[model.AturanPelunasan]
+	restrukturisasiRepo *repository.RestrukturisasiRepository
+	jenisPinjamanRepo   repository.JenisPinjamanRepository
+	pencairanRepo       *repository.PencairanRepository
+	penjaminRepo        *repository.PenjaminRepository
+	agunanRepo          *repository.AgunanRepository
+	autoDebetRepo       *repository.AutoDebetRepository
+	uow                 *repository.UnitOfWork
 }
 
 // NewPinjamanService creates a new service instance
-func NewPinjamanService(repo *repository.PinjamanRepository, userRepo *repository.UserRepository) *PinjamanService {
-	return &PinjamanService{repo: repo, userRepo: userRepo}
+func NewPinjamanService(repo *repository.PinjamanRepository, userRepo *repository.UserRepository, bungaOptionRepo repository.BungaOptionRepository, jadwalRepo *repository.JadwalAngsuranRepository, aturanDendaRepo repository.AturanRepository[model.AturanDenda], kolektibilitasRepo *repository.KolektibilitasRepository, aturanKelayakanRepo repository.AturanRepository[model.AturanKelayakan], aturanPelunasanRepo repository.AturanRepository[model.AturanPelunasan], restrukturisasiRepo *repository.RestrukturisasiRepository, jenisPinjamanRepo repository.JenisPinjamanRepository, pencairanRepo *repository.PencairanRepository, penjaminRepo *repository.PenjaminRepository, agunanRepo *repository.AgunanRepository, autoDebetRepo *repository.AutoDebetRepository, uow *repository.UnitOfWork) *PinjamanService {
+	return &PinjamanService{
+		repo:                repo,
+		userRepo:            userRepo,
+		bungaOptionRepo:     bungaOptionRepo,
+		jadwalRepo:          jadwalRepo,
+		aturanDendaRepo:     aturanDendaRepo,
+		kolektibilitasRepo:  kolektibilitasRepo,
+		aturanKelayakanRepo: aturanKelayakanRepo,
+		aturanPelunasanRepo: aturanPelunasanRepo,
+		restrukturisasiRepo: restrukturisasiRepo,
+		jenisPinjamanRepo:   jenisPinjamanRepo,
+		pencairanRepo:       pencairanRepo,
+		penjaminRepo:        penjaminRepo,
+		agunanRepo:          agunanRepo,
+		autoDebetRepo:       autoDebetRepo,
+		uow:                 uow,
+	}
+}
+
+// DendaRincian is the penalty of one overdue schedule row
+type DendaRincian struct {
+	JadwalAngsuranID  uint        `json:"jadwal_angsuran_id"`
+	AngsuranKe        int         `json:"angsuran_ke"`
+	TanggalJatuhTempo time.Time   `json:"tanggal_jatuh_tempo"`
+	HariTerlambat     int         `json:"hari_terlambat"`
+	Tunggakan         money.Money `json:"tunggakan"` // Unpaid bunga + pokok of the row
+	Denda             money.Money `json:"denda"`
+	DendaDibayar      money.Money `json:"denda_dibayar"`
+	SisaDenda         money.Money `json:"sisa_denda"`
+}
+
+// DendaPreview is the penalty of a loan as of a date
+type DendaPreview struct {
+	PinjamanID uint               `json:"pinjaman_id"`
+	Tanggal    time.Time          `json:"tanggal"`
+	Aturan     *model.AturanDenda `json:"aturan"`
+	Rincian    []DendaRincian     `json:"rincian"`
+	TotalDenda money.Money        `json:"total_denda"` // Unpaid denda over all rows
 }
 
 // Create adds a new Pinjaman (members can create for themselves, admins can create for any user)
@@ -35,14 +86,185 @@ func (s *PinjamanService) Create(requestorID uint, requestorRole string, p *mode
 	if p.TanggalPinjam.IsZero() {
 		p.TanggalPinjam = time.Now()
 	}
-	if p.Status == "" {
-		p.Status = "proses"
+	// New loans always start in proses; later statuses go through the transition endpoints
+	p.Status = model.StatusPinjamanProses
+	p.SisaAngsuran = p.LamaBulan
+	p.VersiJadwal = 1
+
+	jadwal, err := s.hitungJadwalBaru(p)
+	if err != nil {
+		return err
 	}
-	if p.SisaAngsuran == 0 {
-		p.SisaAngsuran = p.LamaBulan
+
+	return s.uow.Do(func(repos *repository.Repositories) error {
+		// Applications that fail the eligibility check are not saved
+		if err := s.kelayakanTerpenuhi(repos, p); err != nil {
+			return err
+		}
+		if err := repos.Pinjaman.Create(p); err != nil {
+			return err
+		}
+		return saveJadwal(repos, p, jadwal)
+	})
+}
+
+// hitungJadwalBaru takes the terms of a new application from its product and
+// interest option and generates its schedule
+func (s *PinjamanService) hitungJadwalBaru(p *model.Pinjaman) ([]model.JadwalAngsuran, error) {
+	if err := s.terapkanJenisPinjaman(p); err != nil {
+		return nil, err
+	}
+	// Rate and default method come from the selected interest option
+	if p.BungaOptionID != nil {
+		option, err := s.bungaOptionRepo.GetByID(*p.BungaOptionID)
+		if err != nil {
+			return nil, errors.New("bunga option not found")
+		}
+		if !option.IsActive {
+			return nil, errors.New("bunga option is not active")
+		}
+		if err := s.terapkanBungaVersi(p); err != nil {
+			return nil, err
+		}
+	}
+	if p.MetodeBunga == "" {
+		p.MetodeBunga = model.MetodeBungaFlat
 	}
 
-	return s.repo.Create(p)
+	jadwal, err := generateJadwal(p.JumlahPinjaman, p.BungaPersen, p.LamaBulan, p.MetodeBunga, p.TanggalPinjam)
+	if err != nil {
+		return nil, err
+	}
+	// The installment amount is derived from the schedule, never taken from the client
+	p.JumlahAngsuran = jadwal[0].TotalAngsuran
+	return jadwal, nil
+}
+
+// terapkanJenisPinjaman checks an application against its product and takes
+// the product's rate, interest method and fees. Loans without a product are
+// left as they are; only new applications need an active product. A saved
+// loan is only checked against the product's limits and keeps its own terms,
+// so later product changes do not reach it.
+func (s *PinjamanService) terapkanJenisPinjaman(p *model.Pinjaman) error {
+	if p.JenisPinjamanID == nil {
+		return nil
+	}
+	jenis, err := s.jenisPinjamanRepo.GetByID(*p.JenisPinjamanID)
+	if err != nil {
+		return errors.New("jenis pinjaman not found")
+	}
+	if p.ID == 0 && !jenis.IsActive {
+		return errors.New("jenis pinjaman is not active")
+	}
+
+	if p.JumlahPinjaman < jenis.MinimalJumlah {
+		return errors.New("jumlah pinjaman is below the jenis pinjaman minimum")
+	}
+	if jenis.MaksimalJumlah > 0 && p.JumlahPinjaman > jenis.MaksimalJumlah {
+		return errors.New("jumlah pinjaman exceeds the jenis pinjaman maximum")
+	}
+	if p.LamaBulan < jenis.MinimalBulan || (jenis.MaksimalBulan > 0 && p.LamaBulan > jenis.MaksimalBulan) {
+		return errors.New("lama bulan is outside the jenis pinjaman tenor range")
+	}
+	if p.ID != 0 {
+		return nil
+	}
+
+	// The product decides the rate and method; an option linked to it gives the rate
+	p.BungaOptionID = jenis.BungaOptionID
+	p.BungaOptionVersiID = nil
+	p.BungaPersen = jenis.BungaPersen
+	p.MetodeBunga = jenis.MetodeBunga
+	if p.BungaOptionID != nil {
+		if err := s.terapkanBungaVersi(p); err != nil {
+			return err
+		}
+	}
+	p.BiayaAdmin = jenis.BiayaAdmin
+	p.BiayaProvisi = p.JumlahPinjaman.MulPercent(jenis.BiayaProvisiPersen)
+	return nil
+}
+
+// terapkanBungaVersi takes the rate of the loan's interest option version in
+// effect on tanggal pinjam; an empty method defaults to that version's method
+func (s *PinjamanService) terapkanBungaVersi(p *model.Pinjaman) error {
+	versi, err := s.bungaOptionRepo.GetVersiAt(*p.BungaOptionID, p.TanggalPinjam)
+	if err != nil {
+		return errors.New("bunga option has no rate in effect on tanggal pinjam")
+	}
+	p.BungaOptionVersiID = &versi.ID
+	p.BungaPersen = versi.Persen
+	if p.MetodeBunga == "" {
+		p.MetodeBunga = versi.MetodeBunga
+	}
+	return nil
+}
+
+// GetJadwal returns the amortization schedule of a loan with the same access rules as Get.
+// versi selects a schedule version replaced by a restructuring; 0 is the current one.
+func (s *PinjamanService) GetJadwal(requestorID uint, requestorRole string, id uint, versi int) ([]model.JadwalAngsuran, error) {
+	p, err := s.Get(requestorID, requestorRole, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if versi > 0 {
+		return s.jadwalRepo.GetByPinjamanVersi(p.ID, versi)
+	}
+	return s.jadwalRepo.GetByPinjaman(p.ID)
+}
+
+// PreviewDenda computes the denda of a loan as of tanggal with the active rule
+// without charging it. Access rules are the same as Get.
+func (s *PinjamanService) PreviewDenda(requestorID uint, requestorRole string, id uint, tanggal time.Time) (*DendaPreview, error) {
+	p, err := s.Get(requestorID, requestorRole, id)
+	if err != nil {
+		return nil, err
+	}
+
+	aturan, err := s.aturanDendaRepo.GetActive()
+	if err != nil {
+		return nil, err
+	}
+	jadwal, err := s.jadwalRepo.GetByPinjaman(p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &DendaPreview{PinjamanID: p.ID, Tanggal: tanggal, Aturan: aturan, Rincian: []DendaRincian{}}
+	for i := range jadwal {
+		row := &jadwal[i]
+		hari, denda, _ := hitungDenda(row, aturan, tanggal)
+		row.Denda = denda
+		if row.SisaDenda() <= 0 && (hari == 0 || row.Status == model.JadwalLunas) {
+			continue
+		}
+		preview.Rincian = append(preview.Rincian, DendaRincian{
+			JadwalAngsuranID:  row.ID,
+			AngsuranKe:        row.AngsuranKe,
+			TanggalJatuhTempo: row.TanggalJatuhTempo,
+			HariTerlambat:     hari,
+			Tunggakan:         row.SisaBunga() + row.SisaPokokAngsuran(),
+			Denda:             row.Denda,
+			DendaDibayar:      row.DendaDibayar,
+			SisaDenda:         row.SisaDenda(),
+		})
+	}
+	preview.TotalDenda = sisaDendaPinjaman(jadwal)
+
+	return preview, nil
+}
+
+// saveJadwal replaces the stored current schedule version of a loan
+func saveJadwal(repos *repository.Repositories, p *model.Pinjaman, jadwal []model.JadwalAngsuran) error {
+	if err := repos.Jadwal.DeleteByPinjaman(p.ID); err != nil {
+		return err
+	}
+	for i := range jadwal {
+		jadwal[i].PinjamanID = p.ID
+		jadwal[i].Versi = p.VersiJadwal
+	}
+	return repos.Jadwal.CreateBatch(jadwal)
 }
 
 // List returns pinjaman list filtered by user unless role allows viewing all
@@ -122,33 +344,69 @@ func (s *PinjamanService) Update(requestorID uint, requestorRole string, id uint
 		}
 	}
 
+	// Terms are fixed once the loan has been decided
+	if existing.Status != model.StatusPinjamanProses {
+		return nil, errors.New("pinjaman can only be edited while in proses")
+	}
+
 	// Update allowed fields
 	if payload.JumlahPinjaman > 0 {
+		// The provisi is a share of the amount at the rate the loan was taken with
+		existing.BiayaProvisi = existing.BiayaProvisi.MulRatio(int64(payload.JumlahPinjaman), int64(existing.JumlahPinjaman))
 		existing.JumlahPinjaman = payload.JumlahPinjaman
 	}
 	// Only update BungaPersen if explicitly provided (> 0)
-	// This prevents overwriting existing interest rate with default 0 value
-	if payload.BungaPersen > 0 {
+	// This prevents overwriting existing interest rate with default 0 value.
+	// A product or interest option keeps the rate and method the loan was taken with.
+	tetap := existing.JenisPinjamanID != nil || existing.BungaOptionID != nil
+	if payload.BungaPersen > 0 && !tetap {
 		existing.BungaPersen = payload.BungaPersen
 	}
 	if payload.LamaBulan > 0 {
 		existing.LamaBulan = payload.LamaBulan
-		// If loan duration changes, update remaining installments accordingly
-		// Only if loan is still in "proses" status
-		if existing.Status == "proses" {
-			existing.SisaAngsuran = payload.LamaBulan
-		}
+		existing.SisaAngsuran = payload.LamaBulan
 	}
-	if payload.JumlahAngsuran > 0 {
-		existing.JumlahAngsuran = payload.JumlahAngsuran
+	if payload.MetodeBunga != "" && !tetap {
+		existing.MetodeBunga = payload.MetodeBunga
 	}
-	// Note: SisaAngsuran cannot be directly updated via API
-	// It's only decremented by the system when payments are verified
-	if payload.Status != "" {
-		existing.Status = payload.Status
+	if payload.PenghasilanBulanan > 0 {
+		existing.PenghasilanBulanan = payload.PenghasilanBulanan
 	}
+	if existing.MetodeBunga == "" {
+		existing.MetodeBunga = model.MetodeBungaFlat
+	}
+	// Changed amount and tenor must still fit the product
+	if err := s.terapkanJenisPinjaman(existing); err != nil {
+		return nil, err
+	}
+	// The rate stays the option's rate in effect on tanggal pinjam
+	if existing.BungaOptionID != nil {
+		if err := s.terapkanBungaVersi(existing); err != nil {
+			return nil, err
+		}
+	}
+	// Note: Status changes go through Approve, Reject, Disburse and WriteOff.
+	// JumlahAngsuran is derived from the schedule and SisaAngsuran is only
+	// decremented by the system when payments are verified
 
-	if err := s.repo.Update(existing); err != nil {
+	// The schedule follows the terms while the loan is in process
+	jadwal, err := generateJadwal(existing.JumlahPinjaman, existing.BungaPersen, existing.LamaBulan, existing.MetodeBunga, existing.TanggalPinjam)
+	if err != nil {
+		return nil, err
+	}
+	existing.JumlahAngsuran = jadwal[0].TotalAngsuran
+
+	err = s.uow.Do(func(repos *repository.Repositories) error {
+		// Changed terms are checked again, without counting the loan itself
+		if err := s.kelayakanTerpenuhi(repos, existing); err != nil {
+			return err
+		}
+		if err := repos.Pinjaman.Update(existing); err != nil {
+			return err
+		}
+		return saveJadwal(repos, existing, jadwal)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -181,9 +439,159 @@ func (s *PinjamanService) Delete(requestorID uint, requestorRole string, id uint
 		}
 	}
 
+	// Approved and disbursed loans are kept for the books
+	if existing.Status != model.StatusPinjamanProses && existing.Status != model.StatusPinjamanDitolak {
+		return errors.New("only pinjaman in proses or ditolak can be deleted")
+	}
+
 	return s.repo.Delete(id)
 }
 
+// pinjamanTransitions lists the statuses each loan status may move to
+var pinjamanTransitions = map[string][]string{
+	model.StatusPinjamanProses:    {model.StatusPinjamanDisetujui, model.StatusPinjamanDitolak},
+	model.StatusPinjamanDisetujui: {model.StatusPinjamanDicairkan},
+	model.StatusPinjamanDicairkan: {model.StatusPinjamanLunas, model.StatusPinjamanMacet},
+	model.StatusPinjamanMacet:     {model.StatusPinjamanLunas, model.StatusPinjamanMacet}, // Write-off of a loan the kolektibilitas job made macet
+}
+
+// ErrInvalidStatusTransition is returned when a loan cannot move to the requested status
+var ErrInvalidStatusTransition = errors.New("invalid status transition")
+
+// checkTransition verifies that a loan may move from one status to another
+func checkTransition(from, to string) error {
+	for _, next := range pinjamanTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, from, to)
+}
+
+// markLunas closes a fully repaid loan. The caller persists the loan.
+func markLunas(p *model.Pinjaman, at time.Time) error {
+	if err := checkTransition(p.Status, model.StatusPinjamanLunas); err != nil {
+		return err
+	}
+	p.Status = model.StatusPinjamanLunas
+	p.TanggalLunas = &at
+	return nil
+}
+
+// checkDecisionAccess allows super admins and the admin who registered the borrower
+// to decide on a loan. Members never decide, not even on their own loans.
+func (s *PinjamanService) checkDecisionAccess(requestorID uint, requestorRole string, p *model.Pinjaman) error {
+	if requestorRole == "super_admin" {
+		return nil
+	}
+	if requestorRole != "admin" {
+		return errors.New("forbidden")
+	}
+	user, err := s.userRepo.FindByID(p.UserID)
+	if err != nil {
+		return err
+	}
+	if user.AdminID == nil || *user.AdminID != requestorID {
+		return errors.New("forbidden")
+	}
+	return nil
+}
+
+// transition locks the loan, checks access and the allowed transition, applies
+// the change and saves it. apply may also return a new schedule to store.
+func (s *PinjamanService) transition(requestorID uint, requestorRole string, id uint, to string, apply func(repos *repository.Repositories, p *model.Pinjaman, now time.Time) ([]model.JadwalAngsuran, error)) (*model.Pinjaman, error) {
+	var result *model.Pinjaman
+	err := s.uow.Do(func(repos *repository.Repositories) error {
+		p, err := repos.Pinjaman.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if err := s.checkDecisionAccess(requestorID, requestorRole, p); err != nil {
+			return err
+		}
+		if err := checkTransition(p.Status, to); err != nil {
+			return err
+		}
+
+		jadwal, err := apply(repos, p, time.Now())
+		if err != nil {
+			return err
+		}
+		p.Status = to
+		if err := repos.Pinjaman.Update(p); err != nil {
+			return err
+		}
+		if jadwal != nil {
+			if err := saveJadwal(repos, p, jadwal); err != nil {
+				return err
+			}
+		}
+		result = p
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Approve moves a loan from proses to disetujui and regenerates its schedule
+// from the approval date. The guarantors and collateral must meet the product's
+// requirements and every guarantor is checked again.
+func (s *PinjamanService) Approve(requestorID uint, requestorRole string, id uint) (*model.Pinjaman, error) {
+	return s.transition(requestorID, requestorRole, id, model.StatusPinjamanDisetujui, func(repos *repository.Repositories, p *model.Pinjaman, now time.Time) ([]model.JadwalAngsuran, error) {
+		if err := s.jaminanTerpenuhi(repos, p); err != nil {
+			return nil, err
+		}
+		p.DiputuskanOleh = &requestorID
+		p.TanggalKeputusan = &now
+		return s.rescheduleFrom(p, now)
+	})
+}
+
+// Reject moves a loan from proses to ditolak with a reason; its collateral is returned
+func (s *PinjamanService) Reject(requestorID uint, requestorRole string, id uint, alasan string) (*model.Pinjaman, error) {
+	if alasan == "" {
+		return nil, errors.New("alasan is required")
+	}
+	return s.transition(requestorID, requestorRole, id, model.StatusPinjamanDitolak, func(repos *repository.Repositories, p *model.Pinjaman, now time.Time) ([]model.JadwalAngsuran, error) {
+		p.DiputuskanOleh = &requestorID
+		p.TanggalKeputusan = &now
+		p.AlasanPenolakan = alasan
+		return nil, repos.Agunan.KembalikanByPinjaman(p.ID, &requestorID, now)
+	})
+}
+
+// WriteOff moves a disbursed loan to macet, or writes off a loan that is
+// already macet by kolektibilitas. Only super admins may write loans off.
+func (s *PinjamanService) WriteOff(requestorID uint, requestorRole string, id uint, alasan string) (*model.Pinjaman, error) {
+	if requestorRole != "super_admin" {
+		return nil, errors.New("forbidden")
+	}
+	if alasan == "" {
+		return nil, errors.New("alasan is required")
+	}
+	return s.transition(requestorID, requestorRole, id, model.StatusPinjamanMacet, func(repos *repository.Repositories, p *model.Pinjaman, now time.Time) ([]model.JadwalAngsuran, error) {
+		if p.TanggalHapusBuku != nil {
+			return nil, fmt.Errorf("%w: pinjaman has already been written off", ErrInvalidStatusTransition)
+		}
+		p.HapusBukuOleh = &requestorID
+		p.TanggalHapusBuku = &now
+		p.AlasanHapusBuku = alasan
+		return nil, nil
+	})
+}
+
+// rescheduleFrom regenerates the schedule with due dates counted from start
+func (s *PinjamanService) rescheduleFrom(p *model.Pinjaman, start time.Time) ([]model.JadwalAngsuran, error) {
+	jadwal, err := generateJadwal(p.JumlahPinjaman, p.BungaPersen, p.LamaBulan, p.MetodeBunga, start)
+	if err != nil {
+		return nil, err
+	}
+	p.JumlahAngsuran = jadwal[0].TotalAngsuran
+	return jadwal, nil
+}
+
 // generateKodePinjaman creates a unique loan code
 func (s *PinjamanService) generateKodePinjaman() string {
 	return fmt.Sprintf("PJM%d", time.Now().Unix())
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"errors"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+	"time"
+)
+
+// RestrukturisasiInput is what a restructuring request asks for
+type RestrukturisasiInput struct {
+	LamaBulan             int
+	BungaPersen           float64
+	MetodeBunga           string
+	MasaTenggangBulan     int
+	KapitalisasiTunggakan bool
+	Alasan                string
+}
+
+// checkRestrukturisasi verifies that a loan can be restructured
+func checkRestrukturisasi(p *model.Pinjaman) error {
+	if p.Status != model.StatusPinjamanDicairkan && p.Status != model.StatusPinjamanMacet {
+		return errors.New("only dicairkan or macet pinjaman can be restructured")
+	}
+	return nil
+}
+
+// AjukanRestrukturisasi records a restructuring request for a loan. The
+// borrower or their admin may submit it; the new terms only take effect once
+// an admin approves it. A loan has at most one pending request.
+func (s *PinjamanService) AjukanRestrukturisasi(requestorID uint, requestorRole string, id uint, input RestrukturisasiInput) (*model.Restrukturisasi, error) {
+	if input.MetodeBunga == "" {
+		input.MetodeBunga = model.MetodeBungaFlat
+	}
+	if !model.ValidMetodeBunga[input.MetodeBunga] {
+		return nil, errors.New("invalid metode bunga")
+	}
+	if input.LamaBulan <= 0 {
+		return nil, errors.New("lama bulan must be positive")
+	}
+	if input.BungaPersen < 0 {
+		return nil, errors.New("bunga persen must not be negative")
+	}
+	if input.MasaTenggangBulan < 0 {
+		return nil, errors.New("masa tenggang bulan must not be negative")
+	}
+
+	p, err := s.Get(requestorID, requestorRole, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *model.Restrukturisasi
+	err = s.uow.Do(func(repos *repository.Repositories) error {
+		// The loan lock keeps two requests of the same loan apart
+		p, err = repos.Pinjaman.GetByIDForUpdate(p.ID)
+		if err != nil {
+			return err
+		}
+		if err := checkRestrukturisasi(p); err != nil {
+			return err
+		}
+		pending, err := repos.Restrukturisasi.CountByPinjamanAndStatus(p.ID, model.StatusRestrukturisasiDiajukan)
+		if err != nil {
+			return err
+		}
+		if pending > 0 {
+			return errors.New("pinjaman already has a pending restrukturisasi")
+		}
+
+		rs := &model.Restrukturisasi{
+			PinjamanID:            p.ID,
+			Status:                model.StatusRestrukturisasiDiajukan,
+			LamaBulan:             input.LamaBulan,
+			BungaPersen:           input.BungaPersen,
+			MetodeBunga:           input.MetodeBunga,
+			MasaTenggangBulan:     input.MasaTenggangBulan,
+			KapitalisasiTunggakan: input.KapitalisasiTunggakan,
+			Alasan:                input.Alasan,
+			DiajukanOleh:          requestorID,
+			VersiLama:             p.VersiJadwal,
+		}
+		if err := repos.Restrukturisasi.Create(rs); err != nil {
+			return err
+		}
+		result = rs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListRestrukturisasi returns the restructuring requests of a loan with the
+// same access rules as Get
+func (s *PinjamanService) ListRestrukturisasi(requestorID uint, requestorRole string, id uint) ([]model.Restrukturisasi, error) {
+	p, err := s.Get(requestorID, requestorRole, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.restrukturisasiRepo.GetByPinjaman(p.ID)
+}
+
+// GetPendingRestrukturisasi returns the requests waiting for a decision
+func (s *PinjamanService) GetPendingRestrukturisasi(requestorID uint, requestorRole string) ([]model.Restrukturisasi, error) {
+	if requestorRole == "super_admin" {
+		return s.restrukturisasiRepo.GetByStatus(model.StatusRestrukturisasiDiajukan, 0)
+	}
+	if requestorRole == "admin" {
+		return s.restrukturisasiRepo.GetByStatus(model.StatusRestrukturisasiDiajukan, requestorID)
+	}
+	return nil, errors.New("forbidden")
+}
+
+// SetujuiRestrukturisasi applies a pending request (same access as Approve).
+// The open rows of the current schedule are closed as direstrukturisasi and a
+// new schedule version is generated from the remaining principal. Arrears of
+// bunga and denda are either added to the new principal or carried onto the
+// first new installment. The loan's original terms are left unchanged.
+func (s *PinjamanService) SetujuiRestrukturisasi(requestorID uint, requestorRole string, restrukturisasiID uint) (*model.Restrukturisasi, error) {
+	aturanDenda, err := s.aturanDendaRepo.GetActive()
+	if err != nil {
+		return nil, err
+	}
+
+	var result *model.Restrukturisasi
+	err = s.uow.Do(func(repos *repository.Repositories) error {
+		rs, p, err := s.lockRestrukturisasi(repos, requestorID, requestorRole, restrukturisasiID)
+		if err != nil {
+			return err
+		}
+		if err := checkRestrukturisasi(p); err != nil {
+			return err
+		}
+
+		jadwal, err := ensureJadwal(repos, p)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		terapkanDenda(jadwal, aturanDenda, now)
+
+		rs.SisaPokokLama = sisaPokokPinjaman(jadwal)
+		rs.TunggakanDenda = sisaDendaPinjaman(jadwal)
+		rs.TunggakanBunga = 0
+		for i := range jadwal {
+			if !jadwal[i].TanggalJatuhTempo.After(now) {
+				rs.TunggakanBunga += jadwal[i].SisaBunga()
+			}
+		}
+		if rs.SisaPokokLama <= 0 {
+			return errors.New("pinjaman has no remaining pokok to restructure")
+		}
+
+		rs.PokokBaru = rs.SisaPokokLama
+		if rs.KapitalisasiTunggakan {
+			rs.PokokBaru += rs.TunggakanBunga + rs.TunggakanDenda
+		}
+		baru, err := jadwalRestrukturisasi(rs, now)
+		if err != nil {
+			return err
+		}
+		// The regular installment is the first one that repays principal
+		rs.JumlahAngsuran = baru[rs.MasaTenggangBulan].TotalAngsuran
+		if !rs.KapitalisasiTunggakan {
+			// Arrears that are not capitalized fall due with the first new installment
+			baru[0].Bunga += rs.TunggakanBunga
+			baru[0].TotalAngsuran += rs.TunggakanBunga
+			baru[0].Denda = rs.TunggakanDenda
+		}
+
+		// The replaced version stays as it was paid, its open rows are closed
+		for i := range jadwal {
+			row := &jadwal[i]
+			if row.Status == model.JadwalLunas {
+				continue
+			}
+			row.Status = model.JadwalDirestrukturisasi
+			if err := repos.Jadwal.Update(row); err != nil {
+				return err
+			}
+		}
+
+		// The loan moves to the new version before it is stored, so the old
+		// version is not replaced by saveJadwal
+		rs.VersiLama = p.VersiJadwal
+		rs.VersiBaru = p.VersiJadwal + 1
+		p.VersiJadwal = rs.VersiBaru
+		p.JumlahAngsuran = rs.JumlahAngsuran
+		p.SisaAngsuran = len(baru)
+		if err := repos.Pinjaman.Update(p); err != nil {
+			return err
+		}
+		if err := saveJadwal(repos, p, baru); err != nil {
+			return err
+		}
+
+		rs.Status = model.StatusRestrukturisasiDisetujui
+		rs.DiputuskanOleh = &requestorID
+		rs.TanggalKeputusan = &now
+		if err := repos.Restrukturisasi.Update(rs); err != nil {
+			return err
+		}
+		result = rs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TolakRestrukturisasi rejects a pending request; the loan keeps its schedule
+func (s *PinjamanService) TolakRestrukturisasi(requestorID uint, requestorRole string, restrukturisasiID uint, alasan string) (*model.Restrukturisasi, error) {
+	if alasan == "" {
+		return nil, errors.New("alasan is required")
+	}
+
+	var result *model.Restrukturisasi
+	err := s.uow.Do(func(repos *repository.Repositories) error {
+		rs, _, err := s.lockRestrukturisasi(repos, requestorID, requestorRole, restrukturisasiID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		rs.Status = model.StatusRestrukturisasiDitolak
+		rs.AlasanPenolakan = alasan
+		rs.DiputuskanOleh = &requestorID
+		rs.TanggalKeputusan = &now
+		if err := repos.Restrukturisasi.Update(rs); err != nil {
+			return err
+		}
+		result = rs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// lockRestrukturisasi locks a pending request and its loan and checks that the
+// requestor may decide on the loan
+func (s *PinjamanService) lockRestrukturisasi(repos *repository.Repositories, requestorID uint, requestorRole string, id uint) (*model.Restrukturisasi, *model.Pinjaman, error) {
+	rs, err := repos.Restrukturisasi.GetByIDForUpdate(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	p, err := repos.Pinjaman.GetByIDForUpdate(rs.PinjamanID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.checkDecisionAccess(requestorID, requestorRole, p); err != nil {
+		return nil, nil, err
+	}
+	if rs.Status != model.StatusRestrukturisasiDiajukan {
+		return nil, nil, errors.New("restrukturisasi has already been decided")
+	}
+	return rs, p, nil
+}
+
+// jadwalRestrukturisasi generates the schedule of a restructured loan from
+// mulai: MasaTenggangBulan interest-only installments, then PokokBaru repaid
+// over LamaBulan with the new rate and method
+func jadwalRestrukturisasi(rs *model.Restrukturisasi, mulai time.Time) ([]model.JadwalAngsuran, error) {
+	cicilan, err := generateJadwal(rs.PokokBaru, rs.BungaPersen, rs.LamaBulan, rs.MetodeBunga, mulai)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]model.JadwalAngsuran, 0, rs.MasaTenggangBulan+len(cicilan))
+	bungaTenggang := rs.PokokBaru.MulPercent(rs.BungaPersen)
+	for ke := 1; ke <= rs.MasaTenggangBulan; ke++ {
+		rows = append(rows, model.JadwalAngsuran{
+			AngsuranKe:        ke,
+			TanggalJatuhTempo: addMonths(mulai, ke),
+			Pokok:             0,
+			Bunga:             bungaTenggang,
+			TotalAngsuran:     bungaTenggang,
+			SisaPokok:         rs.PokokBaru,
+			Status:            model.JadwalBelumBayar,
+		})
+	}
+	for _, row := range cicilan {
+		row.AngsuranKe += rs.MasaTenggangBulan
+		row.TanggalJatuhTempo = addMonths(mulai, row.AngsuranKe)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
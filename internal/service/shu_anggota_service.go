@@ -2,21 +2,27 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"koperasi-service/internal/model"
 	"koperasi-service/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 // SHUAnggotaService handles business logic for SHU Anggota operations
 type SHUAnggotaService struct {
 	repo    *repository.SHUAnggotaRepository
 	shuRepo *repository.SHUTahunanRepository
+	uow     *repository.UnitOfWork
 }
 
 // NewSHUAnggotaService creates a new service instance
-func NewSHUAnggotaService(repo *repository.SHUAnggotaRepository, shuRepo *repository.SHUTahunanRepository) *SHUAnggotaService {
+func NewSHUAnggotaService(repo *repository.SHUAnggotaRepository, shuRepo *repository.SHUTahunanRepository, uow *repository.UnitOfWork) *SHUAnggotaService {
 	return &SHUAnggotaService{
 		repo:    repo,
 		shuRepo: shuRepo,
+		uow:     uow,
 	}
 }
 
@@ -131,10 +137,92 @@ func (s *SHUAnggotaService) Delete(requestorRole string, id uint) error {
 	}
 
 	// Check if record exists
-	_, err := s.repo.GetByID(id)
+	existing, err := s.repo.GetByID(id)
 	if err != nil {
 		return errors.New("SHU Anggota record not found")
 	}
+	if existing.DikreditkanPada != nil {
+		return errors.New("SHU Anggota record already credited")
+	}
 
 	return s.repo.Delete(id)
 }
+
+// Kreditkan pays a saved SHU Anggota record into the member's sukarela wallet
+// (admin only): a verified shu transaction in the wallet history and an
+// shu_credit journal against shu_dibagikan. The SHU of the year must be final
+// and each record is credited once.
+func (s *SHUAnggotaService) Kreditkan(requestorRole string, requestorUserID uint, id uint) (*model.SHUAnggotaRecord, error) {
+	if requestorRole != "admin" && requestorRole != "super_admin" {
+		return nil, errors.New("forbidden")
+	}
+
+	var result *model.SHUAnggotaRecord
+	err := s.uow.Do(func(repos *repository.Repositories) error {
+		record, err := repos.SHUAnggota.GetByIDForUpdate(id)
+		if err != nil {
+			return errors.New("SHU Anggota record not found")
+		}
+		if record.DikreditkanPada != nil {
+			return errors.New("SHU Anggota record already credited")
+		}
+		if record.SHU.Status != "final" {
+			return errors.New("SHU is not final yet")
+		}
+		if record.SHUDiterima <= 0 {
+			return errors.New("shu diterima must be greater than 0")
+		}
+
+		found, err := repos.Simpanan.GetWalletByUserAndType(record.UserID, "sukarela")
+		if err != nil {
+			return errors.New("member has no sukarela wallet")
+		}
+		wallet, err := repos.Simpanan.GetWalletByIDForUpdate(found.ID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		description := fmt.Sprintf("SHU tahun %d", record.SHU.Tahun)
+		transaction := &model.SimpananTransaction{
+			SimpananID:   wallet.ID,
+			Type:         "shu",
+			Amount:       record.SHUDiterima,
+			Description:  description,
+			Status:       "verified",
+			VerifiedByID: &requestorUserID,
+			VerifiedAt:   &gorm.DeletedAt{Time: now, Valid: true},
+		}
+		if err := repos.Simpanan.CreateTransaction(transaction); err != nil {
+			return err
+		}
+		journal, err := postWalletMovement(repos, wallet, walletMovement{
+			EntryType:      model.LedgerSHUCredit,
+			CounterAccount: model.AkunSHU,
+			Amount:         record.SHUDiterima,
+			ReferenceTable: "simpanan_transactions",
+			ReferenceID:    transaction.ID,
+			Description:    description,
+			PostedBy:       &requestorUserID,
+			PostedAt:       now,
+		})
+		if err != nil {
+			return err
+		}
+		if err := repos.Simpanan.SetTransactionJournal(transaction.ID, journal.ID); err != nil {
+			return err
+		}
+		if err := repos.SHUAnggota.SetDikreditkan(record.ID, transaction.ID, now); err != nil {
+			return err
+		}
+
+		record.SimpananTransactionID = &transaction.ID
+		record.DikreditkanPada = &now
+		result = record
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
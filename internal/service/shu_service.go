@@ -4,6 +4,7 @@ import (
 	"errors"
 	"koperasi-service/internal/model"
 	"koperasi-service/internal/repository"
+	"koperasi-service/pkg/money"
 	"time"
 )
 
@@ -25,7 +26,7 @@ const (
 )
 
 // GenerateReport calculates and generates SHU report for a specific year
-func (s *SHUService) GenerateReport(requestorRole string, tahun int, totalSHUKoperasi float64) (*model.SHUReport, error) {
+func (s *SHUService) GenerateReport(requestorRole string, tahun int, totalSHUKoperasi money.Money) (*model.SHUReport, error) {
 	// Only admin and super_admin can generate SHU reports
 	if requestorRole != "admin" && requestorRole != "super_admin" {
 		return nil, errors.New("forbidden")
@@ -58,46 +59,9 @@ func (s *SHUService) GenerateReport(requestorRole string, tahun int, totalSHUKop
 	}
 
 	// Calculate SHU for each member
-	var detailAnggota []model.SHUAnggota
-
-	// Calculate SHU allocation for members (50% of total SHU)
-	shuUntukAnggota := totalSHUKoperasi * (DefaultPersenSHUAnggota / 100)
-	alokasiJasaModal := shuUntukAnggota * (DefaultPersenJasaModal / 100)
-	alokasiJasaUsaha := shuUntukAnggota * (DefaultPersenJasaUsaha / 100)
-
-	for _, user := range users {
-		simpananAnggota := userSimpanan[user.ID]
-		pinjamanAnggota := userPenjualan[user.ID] // This is actually loan data from Pinjaman table
-
-		// Calculate Jasa Modal Anggota (JMA)
-		// JMA = (Simpanan anggota / Total simpanan koperasi) × Alokasi Jasa Modal
-		var jasaModal float64
-		if totalSimpananAll > 0 {
-			jasaModal = (simpananAnggota / totalSimpananAll) * alokasiJasaModal
-		}
-
-		// Calculate Jasa Usaha Anggota (JUA)
-		// JUA = (Pinjaman anggota / Total pinjaman koperasi) × Alokasi Jasa Usaha
-		var jasaUsaha float64
-		if totalPenjualanAll > 0 {
-			jasaUsaha = (pinjamanAnggota / totalPenjualanAll) * alokasiJasaUsaha
-		}
-
-		// Total SHU Anggota = JMA + JUA
-		totalSHUAnggota := jasaModal + jasaUsaha
-
-		// Only include members who have some activity (simpanan or pinjaman)
-		if simpananAnggota > 0 || pinjamanAnggota > 0 {
-			detailAnggota = append(detailAnggota, model.SHUAnggota{
-				UserID:          user.ID,
-				Email:           user.Email,
-				TotalSimpanan:   simpananAnggota,
-				TotalPenjualan:  pinjamanAnggota, // Keep field name for compatibility but this is loan amount
-				JasaModal:       jasaModal,
-				JasaUsaha:       jasaUsaha,
-				TotalSHUAnggota: totalSHUAnggota,
-			})
-		}
+	detailAnggota := distributeSHU(totalSHUKoperasi, users, userSimpanan, userPenjualan, totalSimpananAll, totalPenjualanAll)
+	if err := s.tandaiTunggakanWajib(tahun, detailAnggota); err != nil {
+		return nil, err
 	}
 
 	report := &model.SHUReport{
@@ -115,7 +79,7 @@ func (s *SHUService) GenerateReport(requestorRole string, tahun int, totalSHUKop
 }
 
 // GenerateReportWithExpenses calculates SHU automatically based on income and expenses
-func (s *SHUService) GenerateReportWithExpenses(requestorRole string, tahun int, bebanOperasional, bebanNonOperasional, bebanPajak float64) (*model.SHUReport, error) {
+func (s *SHUService) GenerateReportWithExpenses(requestorRole string, tahun int, bebanOperasional, bebanNonOperasional, bebanPajak money.Money) (*model.SHUReport, error) {
 	// Only admin and super_admin can generate SHU reports
 	if requestorRole != "admin" && requestorRole != "super_admin" {
 		return nil, errors.New("forbidden")
@@ -170,46 +134,9 @@ func (s *SHUService) GenerateReportWithExpenses(requestorRole string, tahun int,
 	}
 
 	// Calculate SHU for each member
-	var detailAnggota []model.SHUAnggota
-
-	// Calculate SHU allocation for members (50% of total SHU)
-	shuUntukAnggota := totalSHUKoperasi * (DefaultPersenSHUAnggota / 100)
-	alokasiJasaModal := shuUntukAnggota * (DefaultPersenJasaModal / 100)
-	alokasiJasaUsaha := shuUntukAnggota * (DefaultPersenJasaUsaha / 100)
-
-	for _, user := range users {
-		simpananAnggota := userSimpanan[user.ID]
-		pinjamanAnggota := userPenjualan[user.ID] // This is actually loan data from Pinjaman table
-
-		// Calculate Jasa Modal Anggota (JMA)
-		// JMA = (Simpanan anggota / Total simpanan koperasi) × Alokasi Jasa Modal
-		var jasaModal float64
-		if totalSimpananAll > 0 {
-			jasaModal = (simpananAnggota / totalSimpananAll) * alokasiJasaModal
-		}
-
-		// Calculate Jasa Usaha Anggota (JUA)
-		// JUA = (Pinjaman anggota / Total pinjaman koperasi) × Alokasi Jasa Usaha
-		var jasaUsaha float64
-		if totalPenjualanAll > 0 {
-			jasaUsaha = (pinjamanAnggota / totalPenjualanAll) * alokasiJasaUsaha
-		}
-
-		// Total SHU Anggota = JMA + JUA
-		totalSHUAnggota := jasaModal + jasaUsaha
-
-		// Only include members who have some activity (simpanan or pinjaman)
-		if simpananAnggota > 0 || pinjamanAnggota > 0 {
-			detailAnggota = append(detailAnggota, model.SHUAnggota{
-				UserID:          user.ID,
-				Email:           user.Email,
-				TotalSimpanan:   simpananAnggota,
-				TotalPenjualan:  pinjamanAnggota, // Keep field name for compatibility but this is loan amount
-				JasaModal:       jasaModal,
-				JasaUsaha:       jasaUsaha,
-				TotalSHUAnggota: totalSHUAnggota,
-			})
-		}
+	detailAnggota := distributeSHU(totalSHUKoperasi, users, userSimpanan, userPenjualan, totalSimpananAll, totalPenjualanAll)
+	if err := s.tandaiTunggakanWajib(tahun, detailAnggota); err != nil {
+		return nil, err
 	}
 
 	report := &model.SHUReport{
@@ -232,7 +159,7 @@ func (s *SHUService) GenerateReportWithExpenses(requestorRole string, tahun int,
 }
 
 // SaveSHU saves the SHU calculation as a record
-func (s *SHUService) SaveSHU(requestorRole string, tahun int, totalSHU float64, status string) (*model.SHUTahunan, error) {
+func (s *SHUService) SaveSHU(requestorRole string, tahun int, totalSHU money.Money, status string) (*model.SHUTahunan, error) {
 	// Only admin and super_admin can save SHU
 	if requestorRole != "admin" && requestorRole != "super_admin" {
 		return nil, errors.New("forbidden")
@@ -264,7 +191,7 @@ func (s *SHUService) SaveSHU(requestorRole string, tahun int, totalSHU float64,
 }
 
 // SaveSHUWithExpenses saves the automated SHU calculation with detailed income and expense information
-func (s *SHUService) SaveSHUWithExpenses(requestorRole string, tahun int, pendapatanOperasional, pendapatanNonOperasional, bebanOperasional, bebanNonOperasional, bebanPajak, totalSHU float64, status string) (*model.SHUTahunan, error) {
+func (s *SHUService) SaveSHUWithExpenses(requestorRole string, tahun int, pendapatanOperasional, pendapatanNonOperasional, bebanOperasional, bebanNonOperasional, bebanPajak, totalSHU money.Money, status string) (*model.SHUTahunan, error) {
 	// Only admin and super_admin can save SHU
 	if requestorRole != "admin" && requestorRole != "super_admin" {
 		return nil, errors.New("forbidden")
@@ -436,37 +363,81 @@ func (s *SHUService) GenerateUserSHU(requestorRole string, requestorUserID uint,
 		return nil, errors.New("user not found")
 	}
 
-	// Get user's simpanan and pinjaman data
-	targetUserSimpanan := userSimpanan[targetUserID]  // Will be 0 if not found
-	targetUserPinjaman := userPenjualan[targetUserID] // Will be 0 if not found (this is actually loan data)
-
-	// Calculate SHU allocation for members (50% of total SHU)
-	shuUntukAnggota := totalSHUKoperasi * (DefaultPersenSHUAnggota / 100)
-	alokasiJasaModal := shuUntukAnggota * (DefaultPersenJasaModal / 100)
-	alokasiJasaUsaha := shuUntukAnggota * (DefaultPersenJasaUsaha / 100)
+	// Run the same distribution as the full report so the member's share,
+	// including any rounding remainder, matches it exactly
+	shuAnggota := &model.SHUAnggota{UserID: targetUserID, Email: userEmail}
+	for _, detail := range distributeSHU(totalSHUKoperasi, users, userSimpanan, userPenjualan, totalSimpananAll, totalPenjualanAll) {
+		if detail.UserID == targetUserID {
+			*shuAnggota = detail
+			break
+		}
+	}
+	detail := []model.SHUAnggota{*shuAnggota}
+	if err := s.tandaiTunggakanWajib(tahun, detail); err != nil {
+		return nil, err
+	}
+	*shuAnggota = detail[0]
 
-	// Calculate SHU for this specific user
-	var jasaModal, jasaUsaha float64
+	return shuAnggota, nil
+}
 
-	if totalSimpananAll > 0 {
-		jasaModal = (targetUserSimpanan / totalSimpananAll) * alokasiJasaModal
+// tandaiTunggakanWajib sets whether each member's simpanan wajib is up to date:
+// no month due before the end of tahun (or today, for the current year) is
+// still unpaid
+func (s *SHUService) tandaiTunggakanWajib(tahun int, detailAnggota []model.SHUAnggota) error {
+	tanggal := time.Date(tahun+1, 1, 1, 0, 0, 0, 0, time.Local)
+	if now := time.Now(); now.Before(tanggal) {
+		tanggal = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
 	}
-
-	if totalPenjualanAll > 0 {
-		jasaUsaha = (targetUserPinjaman / totalPenjualanAll) * alokasiJasaUsaha
+	tunggakan, err := s.repo.GetTunggakanWajibByUser(tanggal)
+	if err != nil {
+		return err
 	}
+	for i := range detailAnggota {
+		detailAnggota[i].TunggakanWajibBulan = tunggakan[detailAnggota[i].UserID]
+		detailAnggota[i].WajibLancar = detailAnggota[i].TunggakanWajibBulan == 0
+	}
+	return nil
+}
 
-	totalSHUAnggota := jasaModal + jasaUsaha
+// distributeSHU splits the member share of the koperasi SHU into jasa modal and
+// jasa usaha and allocates both pro-rata. Shares are whole rupiah; rounding
+// remainders are handed out by money.Allocate so member shares add up to the
+// allocations. Only members with simpanan or pinjaman activity are returned.
+func distributeSHU(totalSHUKoperasi money.Money, users []model.User, userSimpanan, userPenjualan map[uint]money.Money, totalSimpananAll, totalPenjualanAll money.Money) []model.SHUAnggota {
+	// Calculate SHU allocation for members (50% of total SHU)
+	shuUntukAnggota := totalSHUKoperasi.MulPercent(DefaultPersenSHUAnggota)
+	alokasiJasaModal := shuUntukAnggota.MulPercent(DefaultPersenJasaModal)
+	// Jasa usaha takes the rest so the two allocations add up to the member share
+	alokasiJasaUsaha := shuUntukAnggota - alokasiJasaModal
 
-	shuAnggota := &model.SHUAnggota{
-		UserID:          targetUserID,
-		Email:           userEmail,
-		TotalSimpanan:   targetUserSimpanan,
-		TotalPenjualan:  targetUserPinjaman, // Keep field name for compatibility but this is loan amount
-		JasaModal:       jasaModal,
-		JasaUsaha:       jasaUsaha,
-		TotalSHUAnggota: totalSHUAnggota,
+	simpananWeights := make([]money.Money, len(users))
+	pinjamanWeights := make([]money.Money, len(users))
+	for i, user := range users {
+		simpananWeights[i] = userSimpanan[user.ID]
+		pinjamanWeights[i] = userPenjualan[user.ID] // This is actually loan data from Pinjaman table
 	}
 
-	return shuAnggota, nil
+	// Jasa Modal Anggota (JMA) = (Simpanan anggota / Total simpanan koperasi) × Alokasi Jasa Modal
+	jasaModal := alokasiJasaModal.Allocate(simpananWeights, totalSimpananAll)
+	// Jasa Usaha Anggota (JUA) = (Pinjaman anggota / Total pinjaman koperasi) × Alokasi Jasa Usaha
+	jasaUsaha := alokasiJasaUsaha.Allocate(pinjamanWeights, totalPenjualanAll)
+
+	var detailAnggota []model.SHUAnggota
+	for i, user := range users {
+		// Only include members who have some activity (simpanan or pinjaman)
+		if simpananWeights[i] <= 0 && pinjamanWeights[i] <= 0 {
+			continue
+		}
+		detailAnggota = append(detailAnggota, model.SHUAnggota{
+			UserID:          user.ID,
+			Email:           user.Email,
+			TotalSimpanan:   simpananWeights[i],
+			TotalPenjualan:  pinjamanWeights[i], // Keep field name for compatibility but this is loan amount
+			JasaModal:       jasaModal[i],
+			JasaUsaha:       jasaUsaha[i],
+			TotalSHUAnggota: jasaModal[i] + jasaUsaha[i], // Total SHU Anggota = JMA + JUA
+		})
+	}
+	return detailAnggota
 }
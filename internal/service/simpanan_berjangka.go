@@ -0,0 +1,543 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+	"koperasi-service/pkg/money"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BukaBerjangkaInput is what a member fills in to open a simpanan berjangka
+type BukaBerjangkaInput struct {
+	JenisID             uint
+	Nominal             money.Money
+	SumberDana          string // model.SumberDana*, defaults to sukarela
+	InstruksiJatuhTempo string // model.JatuhTempo*, defaults to cair
+	Keterangan          string
+}
+
+var validInstruksiJatuhTempo = map[string]bool{
+	model.JatuhTempoCair:            true,
+	model.JatuhTempoPerpanjang:      true,
+	model.JatuhTempoPerpanjangPokok: true,
+}
+
+// lockWallets locks wallets in id order so concurrent operations on the same
+// wallets cannot deadlock
+func lockWallets(repos *repository.Repositories, ids ...uint) (map[uint]*model.Simpanan, error) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	wallets := make(map[uint]*model.Simpanan, len(ids))
+	for _, id := range ids {
+		wallet, err := repos.Simpanan.GetWalletByIDForUpdate(id)
+		if err != nil {
+			return nil, err
+		}
+		wallets[id] = wallet
+	}
+	return wallets, nil
+}
+
+// pindahDana moves amount between two wallets locked by the caller, with a
+// berjangka transaction in the history of both
+func pindahDana(repos *repository.Repositories, from, to *model.Simpanan, amount money.Money, description string, postedBy *uint, now time.Time) error {
+	_, _, err := postWalletLegs(repos, from, to, walletLegs{
+		Type:              "berjangka",
+		EntryType:         model.LedgerBerjangka,
+		Amount:            amount,
+		DescriptionKeluar: description,
+		DescriptionMasuk:  description,
+		PostedBy:          postedBy,
+		PostedAt:          now,
+	})
+	return err
+}
+
+// mulaiBerjangka starts a funded deposit on mulai and holds its principal on
+// its wallet until it ends
+func mulaiBerjangka(repos *repository.Repositories, b *model.SimpananBerjangka, wallet *model.Simpanan, mulai time.Time) error {
+	jatuhTempo := addMonths(mulai, b.TenorBulan)
+	b.Status = model.BerjangkaAktif
+	b.TanggalMulai = &mulai
+	b.TanggalJatuhTempo = &jatuhTempo
+	if err := repos.Berjangka.Update(b); err != nil {
+		return err
+	}
+	wallet.SaldoDitahan = b.Nominal
+	return repos.Simpanan.UpdateWallet(wallet)
+}
+
+// putuskanDanaBerjangka starts the deposit funded by a verified transfer, or
+// drops it when the transfer is rejected. Transactions of other wallets are
+// left alone; wallet is nil for a rejection.
+func putuskanDanaBerjangka(repos *repository.Repositories, transaction *model.SimpananTransaction, wallet *model.Simpanan, now time.Time) error {
+	if transaction.Type != "topup" {
+		return nil
+	}
+	b, err := repos.Berjangka.GetBySimpananIDForUpdate(transaction.SimpananID)
+	if err != nil || b == nil || b.Status != model.BerjangkaMenunggu {
+		return err
+	}
+	if wallet != nil {
+		return mulaiBerjangka(repos, b, wallet, now)
+	}
+	b.Status = model.BerjangkaDitolak
+	b.TanggalSelesai = &now
+	return repos.Berjangka.Update(b)
+}
+
+// BukaBerjangka opens a simpanan berjangka for an active anggota at the rate,
+// tenor and penalty of the chosen product. Funded from sukarela it starts at
+// once; funded by transfer it waits for the admin to verify the transfer, which
+// shows up as a pending top-up of the deposit's wallet.
+func (s *SimpananService) BukaBerjangka(userID uint, input BukaBerjangkaInput) (*model.SimpananBerjangka, error) {
+	if input.Nominal <= 0 {
+		return nil, errors.New("nominal must be positive")
+	}
+	if input.SumberDana == "" {
+		input.SumberDana = model.SumberDanaSukarela
+	}
+	if input.SumberDana != model.SumberDanaSukarela && input.SumberDana != model.SumberDanaTransfer {
+		return nil, errors.New("sumber dana must be sukarela or transfer")
+	}
+	if input.InstruksiJatuhTempo == "" {
+		input.InstruksiJatuhTempo = model.JatuhTempoCair
+	}
+	if !validInstruksiJatuhTempo[input.InstruksiJatuhTempo] {
+		return nil, errors.New("instruksi jatuh tempo must be cair, perpanjang or perpanjang_pokok")
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.StatusKeanggotaan != model.StatusAnggotaAktif {
+		return nil, errors.New("only an active anggota can open a simpanan berjangka")
+	}
+	jenis, err := s.jenisBerjangkaRepo.GetByID(input.JenisID)
+	if err != nil {
+		return nil, errors.New("jenis simpanan berjangka not found")
+	}
+	if !jenis.IsActive {
+		return nil, errors.New("jenis simpanan berjangka is not active")
+	}
+	if input.Nominal < jenis.MinimalNominal {
+		return nil, fmt.Errorf("nominal must be at least %s", jenis.MinimalNominal)
+	}
+
+	var result *model.SimpananBerjangka
+	err = s.uow.Do(func(repos *repository.Repositories) error {
+		var sukarela *model.Simpanan
+		if input.SumberDana == model.SumberDanaSukarela {
+			wallet, err := repos.Simpanan.GetWalletByUserAndType(userID, "sukarela")
+			if err != nil {
+				return errors.New("wallet not found")
+			}
+			if sukarela, err = repos.Simpanan.GetWalletByIDForUpdate(wallet.ID); err != nil {
+				return err
+			}
+			bebas, err := s.saldoBebas(sukarela)
+			if err != nil {
+				return err
+			}
+			if bebas < input.Nominal {
+				return errors.New("insufficient balance")
+			}
+		}
+
+		wallet := &model.Simpanan{
+			UserID:      userID,
+			Type:        "berjangka",
+			Description: "Simpanan berjangka " + jenis.Nama,
+		}
+		if err := repos.Simpanan.CreateWallet(wallet); err != nil {
+			return err
+		}
+		b := &model.SimpananBerjangka{
+			SimpananID:          wallet.ID,
+			UserID:              userID,
+			JenisID:             jenis.ID,
+			Nominal:             input.Nominal,
+			TenorBulan:          jenis.TenorBulan,
+			PersenTahunan:       jenis.PersenTahunan,
+			PersenPenalti:       jenis.PersenPenalti,
+			SumberDana:          input.SumberDana,
+			InstruksiJatuhTempo: input.InstruksiJatuhTempo,
+			Status:              model.BerjangkaMenunggu,
+			Keterangan:          input.Keterangan,
+		}
+		if err := repos.Berjangka.Create(b); err != nil {
+			return err
+		}
+		result = b
+
+		description := fmt.Sprintf("Penempatan simpanan berjangka %s", jenis.Nama)
+		if sukarela == nil {
+			return repos.Simpanan.CreateTransaction(&model.SimpananTransaction{
+				SimpananID:  wallet.ID,
+				Type:        "topup",
+				Amount:      input.Nominal,
+				Description: description,
+				Status:      "pending",
+			})
+		}
+		now := time.Now()
+		if err := pindahDana(repos, sukarela, wallet, input.Nominal, description, nil, now); err != nil {
+			return err
+		}
+		return mulaiBerjangka(repos, b, wallet, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListBerjangka returns simpanan berjangka, newest first. Members only see
+// their own; admins see everyone's unless userID is set. An empty status
+// matches every deposit.
+func (s *SimpananService) ListBerjangka(requestorID uint, requestorRole string, userID uint, status string) ([]model.SimpananBerjangka, error) {
+	if requestorRole != "super_admin" && requestorRole != "admin" {
+		userID = requestorID
+	}
+	if status == "" {
+		return s.berjangkaRepo.List(userID)
+	}
+	return s.berjangkaRepo.List(userID, status)
+}
+
+// GetBerjangka returns one simpanan berjangka; members can only see their own
+func (s *SimpananService) GetBerjangka(requestorID uint, requestorRole string, id uint) (*model.SimpananBerjangka, error) {
+	b, err := s.berjangkaRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if requestorRole != "super_admin" && requestorRole != "admin" && b.UserID != requestorID {
+		return nil, errors.New("forbidden")
+	}
+	return b, nil
+}
+
+// UbahInstruksiBerjangka changes what happens to a deposit at maturity. Access
+// rules are the same as GetBerjangka.
+func (s *SimpananService) UbahInstruksiBerjangka(requestorID uint, requestorRole string, id uint, instruksi string) (*model.SimpananBerjangka, error) {
+	if !validInstruksiJatuhTempo[instruksi] {
+		return nil, errors.New("instruksi jatuh tempo must be cair, perpanjang or perpanjang_pokok")
+	}
+	if _, err := s.GetBerjangka(requestorID, requestorRole, id); err != nil {
+		return nil, err
+	}
+
+	var result *model.SimpananBerjangka
+	err := s.uow.Do(func(repos *repository.Repositories) error {
+		b, err := repos.Berjangka.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if b.Status != model.BerjangkaMenunggu && b.Status != model.BerjangkaAktif {
+			return fmt.Errorf("simpanan berjangka can only be changed when menunggu or aktif, it is %s", b.Status)
+		}
+		b.InstruksiJatuhTempo = instruksi
+		result = b
+		return repos.Berjangka.Update(b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CairkanDiniBerjangka breaks an active deposit before its maturity date. The
+// principal less the early-break penalty locked when it started goes to
+// sukarela and no interest is paid. Access rules are the same as GetBerjangka.
+func (s *SimpananService) CairkanDiniBerjangka(requestorID uint, requestorRole string, id uint) (*model.SimpananBerjangka, error) {
+	b0, err := s.GetBerjangka(requestorID, requestorRole, id)
+	if err != nil {
+		return nil, err
+	}
+	var postedBy *uint
+	if requestorRole == "super_admin" || requestorRole == "admin" {
+		postedBy = &requestorID
+	}
+
+	var result *model.SimpananBerjangka
+	err = s.uow.Do(func(repos *repository.Repositories) error {
+		sukarela0, err := repos.Simpanan.GetWalletByUserAndType(b0.UserID, "sukarela")
+		if err != nil {
+			return errors.New("wallet not found")
+		}
+		wallets, err := lockWallets(repos, b0.SimpananID, sukarela0.ID)
+		if err != nil {
+			return err
+		}
+		wallet, sukarela := wallets[b0.SimpananID], wallets[sukarela0.ID]
+
+		b, err := repos.Berjangka.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if b.Status != model.BerjangkaAktif {
+			return fmt.Errorf("simpanan berjangka can only be broken when aktif, it is %s", b.Status)
+		}
+		now := time.Now()
+		if !awalHari(*b.TanggalJatuhTempo).After(awalHari(now)) {
+			return errors.New("simpanan berjangka has already matured")
+		}
+
+		wallet.SaldoDitahan = 0
+		b.Penalti = money.Min(b.Nominal.MulPercent(b.PersenPenalti), wallet.Balance)
+		if bersih := wallet.Balance - b.Penalti; bersih > 0 {
+			if err := pindahDana(repos, wallet, sukarela, bersih, "Pencairan dini simpanan berjangka", postedBy, now); err != nil {
+				return err
+			}
+		}
+		if b.Penalti > 0 {
+			if err := postPenaltiBerjangka(repos, wallet, b.Penalti, postedBy, now); err != nil {
+				return err
+			}
+		}
+		// Saves the released hold when nothing was moved
+		if err := repos.Simpanan.UpdateWallet(wallet); err != nil {
+			return err
+		}
+
+		b.Status = model.BerjangkaDicairkanDini
+		b.TanggalSelesai = &now
+		result = b
+		return repos.Berjangka.Update(b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// postPenaltiBerjangka debits the early-break penalty from a deposit's wallet
+// as other income of the koperasi
+func postPenaltiBerjangka(repos *repository.Repositories, wallet *model.Simpanan, penalti money.Money, postedBy *uint, now time.Time) error {
+	description := "Penalti pencairan dini simpanan berjangka"
+	transaction := &model.SimpananTransaction{
+		SimpananID:   wallet.ID,
+		Type:         "berjangka",
+		Amount:       -penalti,
+		Description:  description,
+		Status:       "verified",
+		VerifiedByID: postedBy,
+		VerifiedAt:   &gorm.DeletedAt{Time: now, Valid: true},
+	}
+	if err := repos.Simpanan.CreateTransaction(transaction); err != nil {
+		return err
+	}
+	journal, err := postWalletMovement(repos, wallet, walletMovement{
+		EntryType:      model.LedgerBerjangka,
+		CounterAccount: model.AkunPendapatanLain,
+		Amount:         -penalti,
+		ReferenceTable: "simpanan_transactions",
+		ReferenceID:    transaction.ID,
+		Description:    description,
+		PostedBy:       postedBy,
+		PostedAt:       now,
+	})
+	if err != nil {
+		return err
+	}
+	return repos.Simpanan.SetTransactionJournal(transaction.ID, journal.ID)
+}
+
+// JatuhTempoBerjangka settles every active deposit whose maturity date has
+// come: the interest is credited and the deposit is paid out to sukarela or
+// rolled over per its instruction. Each deposit is settled in its own unit of
+// work. Safe to run every day.
+func (s *SimpananService) JatuhTempoBerjangka(now time.Time) (int, error) {
+	list, err := s.berjangkaRepo.GetJatuhTempo(awalHari(now).AddDate(0, 0, 1))
+	if err != nil {
+		return 0, err
+	}
+
+	selesai := 0
+	var errs []error
+	for _, b := range list {
+		// Rollovers take the product's current rate; an inactive product pays out
+		var jenis *model.JenisSimpananBerjangka
+		if b.InstruksiJatuhTempo != model.JatuhTempoCair {
+			if j, err := s.jenisBerjangkaRepo.GetByID(b.JenisID); err == nil && j.IsActive {
+				jenis = j
+			}
+		}
+		settled := false
+		err := s.uow.Do(func(repos *repository.Repositories) error {
+			var err error
+			settled, err = selesaikanBerjangka(repos, b, jenis, now)
+			return err
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("simpanan berjangka %d: %w", b.ID, err))
+			continue
+		}
+		if settled {
+			selesai++
+		}
+	}
+	return selesai, errors.Join(errs...)
+}
+
+// RunJatuhTempoBerjangka settles matured deposits now (admin only)
+func (s *SimpananService) RunJatuhTempoBerjangka(requestorRole string) (int, error) {
+	if requestorRole != "super_admin" && requestorRole != "admin" {
+		return 0, errors.New("forbidden")
+	}
+	return s.JatuhTempoBerjangka(time.Now())
+}
+
+// selesaikanBerjangka settles one matured deposit. jenis is the product to
+// roll over into, nil to pay out. It reports false when the deposit was
+// already settled.
+func selesaikanBerjangka(repos *repository.Repositories, b0 model.SimpananBerjangka, jenis *model.JenisSimpananBerjangka, now time.Time) (bool, error) {
+	sukarela0, err := repos.Simpanan.GetWalletByUserAndType(b0.UserID, "sukarela")
+	if err != nil {
+		return false, errors.New("wallet not found")
+	}
+	wallets, err := lockWallets(repos, b0.SimpananID, sukarela0.ID)
+	if err != nil {
+		return false, err
+	}
+	wallet, sukarela := wallets[b0.SimpananID], wallets[sukarela0.ID]
+
+	b, err := repos.Berjangka.GetByIDForUpdate(b0.ID)
+	if err != nil {
+		return false, err
+	}
+	if b.Status != model.BerjangkaAktif {
+		return false, nil
+	}
+	jatuhTempo := *b.TanggalJatuhTempo
+
+	// The deposit ends here; its money is no longer held
+	wallet.SaldoDitahan = 0
+	b.Bunga = bungaHarian(b.Nominal, b.PersenTahunan, hariTerlambat(*b.TanggalMulai, jatuhTempo))
+	if b.Bunga > 0 {
+		if err := postBungaBerjangka(repos, wallet, b.Bunga, now); err != nil {
+			return false, err
+		}
+	}
+
+	instruksi := b.InstruksiJatuhTempo
+	if jenis == nil {
+		instruksi = model.JatuhTempoCair
+	}
+	pesan := "Dana dicairkan ke simpanan sukarela."
+	switch instruksi {
+	case model.JatuhTempoCair:
+		if wallet.Balance > 0 {
+			if err := pindahDana(repos, wallet, sukarela, wallet.Balance, "Pencairan simpanan berjangka jatuh tempo", nil, now); err != nil {
+				return false, err
+			}
+		}
+		b.Status = model.BerjangkaDicairkan
+	default:
+		pokok := wallet.Balance
+		if instruksi == model.JatuhTempoPerpanjangPokok {
+			pokok = money.Min(b.Nominal, wallet.Balance)
+			if bunga := wallet.Balance - pokok; bunga > 0 {
+				if err := pindahDana(repos, wallet, sukarela, bunga, "Bunga simpanan berjangka jatuh tempo", nil, now); err != nil {
+					return false, err
+				}
+			}
+		}
+		baru, err := perpanjangBerjangka(repos, b, wallet, jenis, pokok, jatuhTempo, now)
+		if err != nil {
+			return false, err
+		}
+		b.Status = model.BerjangkaDiperpanjang
+		b.DiperpanjangKeID = &baru.ID
+		pesan = fmt.Sprintf("Simpanan diperpanjang Rp %s sampai %s.", baru.Nominal, baru.TanggalJatuhTempo.Format("02-01-2006"))
+	}
+	if err := repos.Simpanan.UpdateWallet(wallet); err != nil {
+		return false, err
+	}
+
+	b.TanggalSelesai = &now
+	if err := repos.Berjangka.Update(b); err != nil {
+		return false, err
+	}
+	if err := repos.Notifikasi.Create(&model.Notifikasi{
+		UserID:         b.UserID,
+		Jenis:          model.NotifikasiBerjangkaJatuhTempo,
+		Judul:          "Simpanan berjangka jatuh tempo",
+		Pesan:          fmt.Sprintf("Simpanan berjangka Rp %s telah jatuh tempo dengan bunga Rp %s. %s", b.Nominal, b.Bunga, pesan),
+		ReferenceTable: "simpanan_berjangka",
+		ReferenceID:    b.ID,
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// postBungaBerjangka credits a matured deposit's interest to its wallet
+func postBungaBerjangka(repos *repository.Repositories, wallet *model.Simpanan, bunga money.Money, now time.Time) error {
+	description := "Bunga simpanan berjangka"
+	transaction := &model.SimpananTransaction{
+		SimpananID:  wallet.ID,
+		Type:        "bunga",
+		Amount:      bunga,
+		Description: description,
+		Status:      "verified",
+		VerifiedAt:  &gorm.DeletedAt{Time: now, Valid: true},
+	}
+	if err := repos.Simpanan.CreateTransaction(transaction); err != nil {
+		return err
+	}
+	journal, err := postWalletMovement(repos, wallet, walletMovement{
+		EntryType:      model.LedgerBungaBerjangka,
+		CounterAccount: model.AkunBebanJasa,
+		Amount:         bunga,
+		ReferenceTable: "simpanan_transactions",
+		ReferenceID:    transaction.ID,
+		Description:    description,
+		PostedAt:       now,
+	})
+	if err != nil {
+		return err
+	}
+	return repos.Simpanan.SetTransactionJournal(transaction.ID, journal.ID)
+}
+
+// perpanjangBerjangka rolls pokok of a matured deposit over into a new deposit
+// of the product, starting on the old maturity date
+func perpanjangBerjangka(repos *repository.Repositories, lama *model.SimpananBerjangka, wallet *model.Simpanan, jenis *model.JenisSimpananBerjangka, pokok money.Money, mulai, now time.Time) (*model.SimpananBerjangka, error) {
+	walletBaru := &model.Simpanan{
+		UserID:      lama.UserID,
+		Type:        "berjangka",
+		Description: "Simpanan berjangka " + jenis.Nama,
+	}
+	if err := repos.Simpanan.CreateWallet(walletBaru); err != nil {
+		return nil, err
+	}
+	baru := &model.SimpananBerjangka{
+		SimpananID:          walletBaru.ID,
+		UserID:              lama.UserID,
+		JenisID:             jenis.ID,
+		Nominal:             pokok,
+		TenorBulan:          jenis.TenorBulan,
+		PersenTahunan:       jenis.PersenTahunan,
+		PersenPenalti:       jenis.PersenPenalti,
+		SumberDana:          model.SumberDanaPerpanjangan,
+		InstruksiJatuhTempo: lama.InstruksiJatuhTempo,
+		Status:              model.BerjangkaMenunggu,
+		DiperpanjangDariID:  &lama.ID,
+	}
+	if err := repos.Berjangka.Create(baru); err != nil {
+		return nil, err
+	}
+	if err := pindahDana(repos, wallet, walletBaru, pokok, "Perpanjangan simpanan berjangka", nil, now); err != nil {
+		return nil, err
+	}
+	if err := mulaiBerjangka(repos, baru, walletBaru, mulai); err != nil {
+		return nil, err
+	}
+	return baru, nil
+}
@@ -2,8 +2,10 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"koperasi-service/internal/model"
 	"koperasi-service/internal/repository"
+	"koperasi-service/pkg/money"
 	"time"
 
 	"gorm.io/gorm"
@@ -11,15 +13,54 @@ import (
 
 // SimpananService contains business logic for Simpanan wallets.
 type SimpananService struct {
-	repo *repository.SimpananRepository
+	repo               *repository.SimpananRepository
+	ledgerRepo         *repository.LedgerRepository
+	penarikanRepo      *repository.PenarikanRepository
+	kewajibanWajibRepo *repository.KewajibanWajibRepository
+	jasaRepo           *repository.JasaSimpananRepository
+	berjangkaRepo      *repository.SimpananBerjangkaRepository
+	transferRepo       *repository.TransferSukarelaRepository
+	aturanWajibRepo    repository.AturanRepository[model.AturanSimpananWajib]
+	aturanPokokRepo    repository.AturanRepository[model.AturanSimpananPokok]
+	aturanJasaRepo     repository.AturanRepository[model.AturanJasaSimpanan]
+	jenisBerjangkaRepo repository.JenisSimpananBerjangkaRepository
+	aturanTransferRepo repository.AturanRepository[model.AturanTransfer]
+	jenisSimpananRepo  repository.JenisSimpananRepository
+	userRepo           *repository.UserRepository
+	uow                *repository.UnitOfWork
 }
 
 // NewSimpananService creates a new service instance.
-func NewSimpananService(repo *repository.SimpananRepository) *SimpananService {
-	return &SimpananService{repo: repo}
+func NewSimpananService(repo *repository.SimpananRepository, ledgerRepo *repository.LedgerRepository, penarikanRepo *repository.PenarikanRepository, kewajibanWajibRepo *repository.KewajibanWajibRepository, jasaRepo *repository.JasaSimpananRepository, berjangkaRepo *repository.SimpananBerjangkaRepository, transferRepo *repository.TransferSukarelaRepository, aturanWajibRepo repository.AturanRepository[model.AturanSimpananWajib], aturanPokokRepo repository.AturanRepository[model.AturanSimpananPokok], aturanJasaRepo repository.AturanRepository[model.AturanJasaSimpanan], jenisBerjangkaRepo repository.JenisSimpananBerjangkaRepository, aturanTransferRepo repository.AturanRepository[model.AturanTransfer], jenisSimpananRepo repository.JenisSimpananRepository, userRepo *repository.UserRepository, uow *repository.UnitOfWork) *SimpananService {
+	return &SimpananService{
+		repo:               repo,
+		ledgerRepo:         ledgerRepo,
+		penarikanRepo:      penarikanRepo,
+		kewajibanWajibRepo: kewajibanWajibRepo,
+		jasaRepo:           jasaRepo,
+		berjangkaRepo:      berjangkaRepo,
+		transferRepo:       transferRepo,
+		aturanWajibRepo:    aturanWajibRepo,
+		aturanPokokRepo:    aturanPokokRepo,
+		aturanJasaRepo:     aturanJasaRepo,
+		jenisBerjangkaRepo: jenisBerjangkaRepo,
+		aturanTransferRepo: aturanTransferRepo,
+		jenisSimpananRepo:  jenisSimpananRepo,
+		userRepo:           userRepo,
+		uow:                uow,
+	}
+}
+
+// WalletBalance is the ledger-derived balance of a wallet at a point in time
+type WalletBalance struct {
+	WalletID      uint         `json:"wallet_id"`
+	AsOf          time.Time    `json:"as_of"`
+	LedgerBalance money.Money  `json:"ledger_balance"`
+	WalletBalance *money.Money `json:"wallet_balance,omitempty"` // Cached Simpanan.Balance, only for the current balance
+	Consistent    *bool        `json:"consistent,omitempty"`     // Whether the cached balance matches the ledger
 }
 
-// InitializeUserWallets creates the three wallet types for a new user
+// InitializeUserWallets creates a wallet of every active wallet type for a new user
 func (s *SimpananService) InitializeUserWallets(userID uint) error {
 	return s.repo.InitializeUserWallets(userID)
 }
@@ -49,16 +90,31 @@ func (s *SimpananService) GetAllWallets(requestorRole string) ([]model.Simpanan,
 	return s.repo.GetAllWallets(0)
 }
 
-// TopupWallet creates a pending top-up transaction
-func (s *SimpananService) TopupWallet(userID uint, walletType string, amount float64, description string) error {
+// TopupWallet creates a pending top-up transaction. A zero amount takes the
+// default amount of the wallet type; an uploaded receipt given in
+// buktiTransferID is attached to it for the admin to check.
+func (s *SimpananService) TopupWallet(userID uint, walletType string, amount money.Money, description string, buktiTransferID *uint) error {
+	// Valid wallet types are the active types of the catalog
+	jenis, err := s.jenisSimpananRepo.GetByKode(walletType)
+	if err != nil || !jenis.IsActive {
+		return errors.New("invalid wallet type")
+	}
+	if amount == 0 {
+		amount = jenis.NominalDefault
+	}
 	if amount <= 0 {
 		return errors.New("amount must be positive")
 	}
+	if jenis.Wajib && amount < jenis.NominalDefault {
+		return fmt.Errorf("simpanan %s top-up must be at least %s", jenis.Kode, jenis.NominalDefault)
+	}
 
-	// Valid wallet types
-	validTypes := map[string]bool{"pokok": true, "wajib": true, "sukarela": true}
-	if !validTypes[walletType] {
-		return errors.New("invalid wallet type")
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.StatusKeanggotaan == model.StatusAnggotaKeluar {
+		return errors.New("membership has ended")
 	}
 
 	// Get or create wallet
@@ -67,92 +123,349 @@ func (s *SimpananService) TopupWallet(userID uint, walletType string, amount flo
 		return errors.New("wallet not found")
 	}
 
+	// Simpanan pokok is paid once, at the configured amount
+	if walletType == "pokok" {
+		if err := s.checkTopupPokok(wallet, amount); err != nil {
+			return err
+		}
+	}
+
 	// Create pending transaction
 	transaction := &model.SimpananTransaction{
-		SimpananID:  wallet.ID,
-		Type:        "topup",
-		Amount:      amount,
-		Description: description,
-		Status:      "pending",
+		SimpananID:      wallet.ID,
+		Type:            "topup",
+		Amount:          amount,
+		Description:     description,
+		Status:          "pending",
+		BuktiTransferID: buktiTransferID,
 	}
 
-	return s.repo.CreateTransaction(transaction)
+	if buktiTransferID == nil {
+		return s.repo.CreateTransaction(transaction)
+	}
+	return s.uow.Do(func(repos *repository.Repositories) error {
+		if err := repos.Simpanan.CreateTransaction(transaction); err != nil {
+			return err
+		}
+		return lampirkanBukti(repos, *buktiTransferID, userID, model.BerkasSimpananTransaction, transaction.ID, userID)
+	})
 }
 
-// VerifyTransaction verifies and processes a pending transaction (admin only)
-func (s *SimpananService) VerifyTransaction(transactionID uint, adminID uint, adminRole string, approve bool) error {
-	if adminRole != "super_admin" && adminRole != "admin" {
-		return errors.New("forbidden")
+// saldoBebas returns what a member can move out of a wallet: the available
+// balance above the minimum balance of its type
+func (s *SimpananService) saldoBebas(wallet *model.Simpanan) (money.Money, error) {
+	jenis, err := s.jenisSimpananRepo.GetByKode(wallet.Type)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return wallet.SaldoTersedia(), nil
 	}
+	if err != nil {
+		return 0, err
+	}
+	return wallet.SaldoTersedia() - jenis.SaldoMinimal, nil
+}
 
-	// Get transaction
-	transaction, err := s.repo.GetTransactionByID(transactionID)
+// checkTopupPokok refuses a simpanan pokok top-up once pokok has been paid or
+// while a pokok top-up is waiting for verification, and one that does not
+// match the active rule's amount
+func (s *SimpananService) checkTopupPokok(wallet *model.Simpanan, amount money.Money) error {
+	if wallet.Balance > 0 {
+		return errors.New("simpanan pokok has already been paid")
+	}
+	pending, err := s.repo.HasTransaction(wallet.ID, "topup", "pending")
+	if err != nil {
+		return err
+	}
+	if pending {
+		return errors.New("a simpanan pokok top-up is already pending")
+	}
+	aturan, err := s.aturanPokokRepo.GetActive()
 	if err != nil {
 		return err
 	}
+	if aturan != nil && amount != aturan.Nominal {
+		return fmt.Errorf("simpanan pokok must be exactly %s", aturan.Nominal)
+	}
+	return nil
+}
 
-	if transaction.Status != "pending" {
-		return errors.New("transaction already processed")
+// VerifyTransaction verifies and processes a pending transaction (admin only).
+// The transaction and wallet rows are locked so concurrent verifications
+// cannot lose an update; any failure rolls the whole operation back.
+func (s *SimpananService) VerifyTransaction(transactionID uint, adminID uint, adminRole string, approve bool) error {
+	if adminRole != "super_admin" && adminRole != "admin" {
+		return errors.New("forbidden")
 	}
 
-	// Update transaction status
-	if approve {
-		transaction.Status = "verified"
+	return s.uow.Do(func(repos *repository.Repositories) error {
+		repo := repos.Simpanan
 
-		// Update wallet balance
-		wallet, err := s.repo.GetWalletByID(transaction.SimpananID)
+		// Lock the transaction first so a second verifier waits here
+		transaction, err := repo.GetTransactionByIDForUpdate(transactionID)
 		if err != nil {
 			return err
 		}
 
-		wallet.Balance += transaction.Amount
-		if err := s.repo.UpdateWallet(wallet); err != nil {
+		if transaction.Status != "pending" {
+			return errors.New("transaction already processed")
+		}
+
+		// Update transaction status
+		var wallet *model.Simpanan
+		if approve {
+			transaction.Status = "verified"
+
+			// Post the top-up to the ledger and the wallet balance
+			wallet, err = repo.GetWalletByIDForUpdate(transaction.SimpananID)
+			if err != nil {
+				return err
+			}
+			if wallet.Type == "pokok" && wallet.Balance > 0 {
+				return errors.New("simpanan pokok has already been paid")
+			}
+
+			journal, err := postWalletMovement(repos, wallet, walletMovement{
+				EntryType:      model.LedgerTopup,
+				CounterAccount: model.AkunKas,
+				Amount:         transaction.Amount,
+				ReferenceTable: "simpanan_transactions",
+				ReferenceID:    transaction.ID,
+				Description:    transaction.Description,
+				PostedBy:       &adminID,
+			})
+			if err != nil {
+				return err
+			}
+			transaction.LedgerJournalID = &journal.ID
+		} else {
+			transaction.Status = "rejected"
+		}
+
+		transaction.VerifiedByID = &adminID
+		now := gorm.DeletedAt{Time: time.Now(), Valid: true}
+		transaction.VerifiedAt = &now
+
+		if err := repo.UpdateTransaction(transaction, "pending"); err != nil {
 			return err
 		}
-	} else {
-		transaction.Status = "rejected"
-	}
 
-	transaction.VerifiedByID = &adminID
-	now := gorm.DeletedAt{Time: time.Now(), Valid: true}
-	transaction.VerifiedAt = &now
+		// A simpanan berjangka funded by transfer starts, or is dropped, with its transfer
+		if err := putuskanDanaBerjangka(repos, transaction, wallet, now.Time); err != nil {
+			return err
+		}
 
-	return s.repo.UpdateTransaction(transaction)
+		// A verified wajib top-up pays the member's open months
+		if wallet != nil && wallet.Type == "wajib" {
+			return cocokkanWajib(repos, wallet.UserID, wallet.ID, now.Time)
+		}
+		// A verified pokok top-up makes a calon anggota an active anggota
+		if wallet != nil && wallet.Type == "pokok" {
+			return aktifkanAnggota(repos, wallet.UserID, now.Time)
+		}
+		return nil
+	})
 }
 
 // AdjustWalletBalance allows admin to directly adjust wallet balance
-func (s *SimpananService) AdjustWalletBalance(walletID uint, amount float64, description string, adminID uint, adminRole string) error {
+func (s *SimpananService) AdjustWalletBalance(walletID uint, amount money.Money, description string, adminID uint, adminRole string) error {
 	if adminRole != "super_admin" && adminRole != "admin" {
 		return errors.New("forbidden")
 	}
 
-	wallet, err := s.repo.GetWalletByID(walletID)
+	return s.uow.Do(func(repos *repository.Repositories) error {
+		repo := repos.Simpanan
+
+		wallet, err := repo.GetWalletByIDForUpdate(walletID)
+		if err != nil {
+			return err
+		}
+
+		if wallet.Type == "pokok" && amount < 0 {
+			return errors.New("simpanan pokok can only be refunded through resignation")
+		}
+
+		// Check the resulting balance before writing anything
+		if wallet.SaldoTersedia()+amount < 0 {
+			return errors.New("insufficient balance")
+		}
+
+		// Create adjustment transaction
+		transaction := &model.SimpananTransaction{
+			SimpananID:   wallet.ID,
+			Type:         "adjustment",
+			Amount:       amount,
+			Description:  description,
+			Status:       "verified",
+			VerifiedByID: &adminID,
+			VerifiedAt:   &gorm.DeletedAt{Time: time.Now(), Valid: true},
+		}
+
+		if err := repo.CreateTransaction(transaction); err != nil {
+			return err
+		}
+
+		// Post to the ledger and update the wallet balance immediately
+		journal, err := postWalletMovement(repos, wallet, walletMovement{
+			EntryType:      model.LedgerAdjustment,
+			CounterAccount: model.AkunPenyesuaian,
+			Amount:         amount,
+			ReferenceTable: "simpanan_transactions",
+			ReferenceID:    transaction.ID,
+			Description:    description,
+			PostedBy:       &adminID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return repo.SetTransactionJournal(transaction.ID, journal.ID)
+	})
+}
+
+// ReverseJournal corrects a posted top-up or adjustment journal by posting its
+// reversal (admin only).
+// A reversal transaction is added to the history of every wallet it touches.
+func (s *SimpananService) ReverseJournal(journalID uint, description string, adminID uint, adminRole string) (*model.LedgerJournal, error) {
+	if adminRole != "super_admin" && adminRole != "admin" {
+		return nil, errors.New("forbidden")
+	}
+
+	var reversal *model.LedgerJournal
+	err := s.uow.Do(func(repos *repository.Repositories) error {
+		original, err := repos.Ledger.GetJournalByID(journalID)
+		if err != nil {
+			return err
+		}
+
+		journal, walletChanges, err := reverseJournal(repos, original, &adminID, description)
+		if err != nil {
+			return err
+		}
+
+		for walletID, change := range walletChanges {
+			wallet, err := repos.Simpanan.GetWalletByID(walletID)
+			if err != nil {
+				return err
+			}
+			if err := cekPembalikan(repos, original, wallet, change); err != nil {
+				return err
+			}
+
+			transaction := &model.SimpananTransaction{
+				SimpananID:      walletID,
+				Type:            "reversal",
+				Amount:          change,
+				Description:     description,
+				Status:          "verified",
+				VerifiedByID:    &adminID,
+				VerifiedAt:      &gorm.DeletedAt{Time: time.Now(), Valid: true},
+				LedgerJournalID: &journal.ID,
+			}
+			if err := repos.Simpanan.CreateTransaction(transaction); err != nil {
+				return err
+			}
+		}
+
+		reversal = journal
+		return nil
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return reversal, nil
+}
 
-	// Create adjustment transaction
-	transaction := &model.SimpananTransaction{
-		SimpananID:   wallet.ID,
-		Type:         "adjustment",
-		Amount:       amount,
-		Description:  description,
-		Status:       "verified",
-		VerifiedByID: &adminID,
-		VerifiedAt:   &gorm.DeletedAt{Time: time.Now(), Valid: true},
+// cekPembalikan refuses a reversal that would leave the records the original
+// movement produced out of step. It runs after the reversal is posted.
+func cekPembalikan(repos *repository.Repositories, original *model.LedgerJournal, wallet *model.Simpanan, change money.Money) error {
+	switch {
+	case wallet.Type == "pokok" && change < 0:
+		// The payment made the member an anggota; only resignation pays it back
+		return errors.New("simpanan pokok can only be refunded through resignation")
+	case wallet.Type == "wajib" && original.EntryType == model.LedgerTopup:
+		return cekPembalikanWajib(repos, wallet)
+	case wallet.Type == "berjangka":
+		// A verified transfer started the deposit, which runs on its nominal
+		return errors.New("a simpanan berjangka movement cannot be reversed")
 	}
+	return nil
+}
 
-	if err := s.repo.CreateTransaction(transaction); err != nil {
-		return err
+// GetWalletBalance returns a wallet's balance derived from the ledger. With a
+// nil asOf it returns the current balance and checks it against the cached one.
+func (s *SimpananService) GetWalletBalance(walletID uint, asOf *time.Time, requestorID uint, requestorRole string) (*WalletBalance, error) {
+	wallet, err := s.GetWalletDetail(walletID, requestorID, requestorRole)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := s.ledgerRepo.GetWalletBalance(wallet.ID, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &WalletBalance{WalletID: wallet.ID, LedgerBalance: balance}
+	if asOf != nil {
+		result.AsOf = *asOf
+	} else {
+		result.AsOf = time.Now()
+		consistent := balance == wallet.Balance
+		result.WalletBalance = &wallet.Balance
+		result.Consistent = &consistent
 	}
+	return result, nil
+}
 
-	// Update wallet balance immediately
-	wallet.Balance += amount
-	if wallet.Balance < 0 {
-		return errors.New("insufficient balance")
+// GetWalletLedger returns the ledger entries of a wallet
+func (s *SimpananService) GetWalletLedger(walletID uint, requestorID uint, requestorRole string) ([]model.LedgerEntry, error) {
+	wallet, err := s.GetWalletDetail(walletID, requestorID, requestorRole)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.repo.UpdateWallet(wallet)
+	return s.ledgerRepo.GetEntriesByWallet(wallet.ID)
+}
+
+// PostOpeningBalances posts an opening-balance journal for every wallet whose
+// balance predates the ledger, so ledger and cached balances agree. Safe to run
+// on every start-up.
+func (s *SimpananService) PostOpeningBalances() error {
+	wallets, err := s.ledgerRepo.GetWalletsWithoutEntries()
+	if err != nil {
+		return err
+	}
+
+	for _, w := range wallets {
+		err := s.uow.Do(func(repos *repository.Repositories) error {
+			wallet, err := repos.Simpanan.GetWalletByIDForUpdate(w.ID)
+			if err != nil {
+				return err
+			}
+
+			// Another instance may have posted it, or a movement may have
+			// hit the wallet, since the list was read
+			hasEntries, err := repos.Ledger.HasWalletEntries(wallet.ID)
+			if err != nil {
+				return err
+			}
+			if hasEntries || wallet.Balance == 0 {
+				return nil
+			}
+
+			// The cached balance is already correct, only the journal is missing
+			return repos.Ledger.PostJournal(walletJournal(wallet.ID, walletMovement{
+				EntryType:      model.LedgerOpeningBalance,
+				CounterAccount: model.AkunSaldoAwal,
+				Amount:         wallet.Balance,
+				ReferenceTable: "simpanans",
+				ReferenceID:    wallet.ID,
+				Description:    "Saldo awal",
+			}))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // GetWalletTransactions returns transaction history for a wallet
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+	"koperasi-service/pkg/money"
+	"time"
+)
+
+// TunggakanWajib is a member's overdue simpanan wajib on a date
+type TunggakanWajib struct {
+	UserID         uint                   `json:"user_id"`
+	Tanggal        time.Time              `json:"tanggal"`
+	Lancar         bool                   `json:"lancar"` // No month is overdue
+	JumlahBulan    int                    `json:"jumlah_bulan"`
+	TotalTunggakan money.Money            `json:"total_tunggakan"`
+	Rincian        []model.KewajibanWajib `json:"rincian"`
+}
+
+// RekapTunggakanWajib is the overdue simpanan wajib of the whole koperasi
+type RekapTunggakanWajib struct {
+	Tanggal        time.Time                      `json:"tanggal"`
+	JumlahAnggota  int                            `json:"jumlah_anggota"` // Members with at least one overdue month
+	JumlahBulan    int                            `json:"jumlah_bulan"`
+	TotalTunggakan money.Money                    `json:"total_tunggakan"`
+	Anggota        []repository.TunggakanWajibRow `json:"anggota"`
+}
+
+// periodeBulan returns the first day of t's month
+func periodeBulan(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// awalHari returns the start of t's day; an obligation is overdue once its due
+// date is before it
+func awalHari(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// BuatKewajibanWajib creates the simpanan wajib obligation of now's month for
+// every active anggota who has none yet, at the amount of the active rule, and
+// pays it from top-ups not yet matched. Members activated after this month's
+// due date start next month. Without an active rule nothing is created. Safe to run
+// every day.
+func (s *SimpananService) BuatKewajibanWajib(now time.Time) (int, error) {
+	aturan, err := s.aturanWajibRepo.GetActive()
+	if err != nil {
+		return 0, err
+	}
+	if aturan == nil {
+		return 0, nil
+	}
+	members, err := s.userRepo.ListByRole("member")
+	if err != nil {
+		return 0, err
+	}
+
+	periode := periodeBulan(now)
+	jatuhTempo := time.Date(periode.Year(), periode.Month(), aturan.TanggalJatuhTempo, 0, 0, 0, 0, periode.Location())
+
+	dibuat := 0
+	var errs []error
+	for _, u := range members {
+		if u.StatusKeanggotaan != model.StatusAnggotaAktif {
+			continue
+		}
+		if u.TanggalAktif != nil && u.TanggalAktif.After(jatuhTempo) {
+			continue
+		}
+		created := false
+		err := s.uow.Do(func(repos *repository.Repositories) error {
+			wallet, err := repos.Simpanan.GetWalletByUserAndType(u.ID, "wajib")
+			if err != nil {
+				return err
+			}
+			// Serializes with top-up verification of the same wallet
+			if _, err := repos.Simpanan.GetWalletByIDForUpdate(wallet.ID); err != nil {
+				return err
+			}
+			exists, err := repos.KewajibanWajib.ExistsForPeriode(u.ID, periode)
+			if err != nil || exists {
+				return err
+			}
+			if err := repos.KewajibanWajib.Create(&model.KewajibanWajib{
+				UserID:            u.ID,
+				SimpananID:        wallet.ID,
+				Periode:           periode,
+				AturanID:          aturan.ID,
+				Nominal:           aturan.Nominal,
+				TanggalJatuhTempo: jatuhTempo,
+				Status:            model.KewajibanWajibBelumBayar,
+			}); err != nil {
+				return err
+			}
+			created = true
+			return cocokkanWajib(repos, u.ID, wallet.ID, now)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %d: %w", u.ID, err))
+			continue
+		}
+		if created {
+			dibuat++
+		}
+	}
+	return dibuat, errors.Join(errs...)
+}
+
+// RunKewajibanWajib creates this month's obligations on demand (admin only)
+func (s *SimpananService) RunKewajibanWajib(requestorRole string) (int, error) {
+	if requestorRole != "super_admin" && requestorRole != "admin" {
+		return 0, errors.New("forbidden")
+	}
+	return s.BuatKewajibanWajib(time.Now())
+}
+
+// cocokkanWajib pays the member's open obligations, oldest month first, from
+// the verified wajib top-ups not yet matched. Top-ups count from the month of
+// the member's first obligation. The wallet must be locked by the caller.
+func cocokkanWajib(repos *repository.Repositories, userID, simpananID uint, now time.Time) error {
+	semua, err := repos.KewajibanWajib.GetByUser(userID)
+	if err != nil || len(semua) == 0 {
+		return err
+	}
+	setoran, err := repos.KewajibanWajib.GetTotalSetoran(simpananID, semua[0].Periode)
+	if err != nil {
+		return err
+	}
+	terbayar, err := repos.KewajibanWajib.GetTotalTerbayar(userID)
+	if err != nil {
+		return err
+	}
+
+	kredit := setoran - terbayar
+	for i := range semua {
+		if kredit <= 0 {
+			break
+		}
+		k := &semua[i]
+		if k.Status == model.KewajibanWajibLunas {
+			continue
+		}
+		bayar := money.Min(kredit, k.Sisa())
+		k.Terbayar += bayar
+		kredit -= bayar
+		if k.Sisa() == 0 {
+			k.Status = model.KewajibanWajibLunas
+			k.TanggalLunas = &now
+		} else {
+			k.Status = model.KewajibanWajibSebagian
+		}
+		if err := repos.KewajibanWajib.Update(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cekPembalikanWajib refuses to reverse a wajib top-up whose money already
+// paid monthly obligations. The reversed top-up no longer counts as setoran.
+func cekPembalikanWajib(repos *repository.Repositories, wallet *model.Simpanan) error {
+	semua, err := repos.KewajibanWajib.GetByUser(wallet.UserID)
+	if err != nil || len(semua) == 0 {
+		return err
+	}
+	setoran, err := repos.KewajibanWajib.GetTotalSetoran(wallet.ID, semua[0].Periode)
+	if err != nil {
+		return err
+	}
+	terbayar, err := repos.KewajibanWajib.GetTotalTerbayar(wallet.UserID)
+	if err != nil {
+		return err
+	}
+	if setoran < terbayar {
+		return errors.New("simpanan wajib that paid kewajiban months cannot be reversed")
+	}
+	return nil
+}
+
+// hitungTunggakanWajib returns the member's obligations overdue on tanggal
+func hitungTunggakanWajib(repo *repository.KewajibanWajibRepository, userID uint, tanggal time.Time) (*TunggakanWajib, error) {
+	rincian, err := repo.GetTunggakanByUser(userID, awalHari(tanggal))
+	if err != nil {
+		return nil, err
+	}
+	t := &TunggakanWajib{UserID: userID, Tanggal: tanggal, JumlahBulan: len(rincian), Rincian: rincian}
+	for i := range rincian {
+		t.TotalTunggakan += rincian[i].Sisa()
+	}
+	t.Lancar = t.JumlahBulan == 0
+	return t, nil
+}
+
+// GetKewajibanWajib returns every monthly obligation of a member. Members can
+// only see their own.
+func (s *SimpananService) GetKewajibanWajib(userID uint, requestorID uint, requestorRole string) ([]model.KewajibanWajib, error) {
+	if requestorRole != "super_admin" && requestorRole != "admin" && requestorID != userID {
+		return nil, errors.New("forbidden")
+	}
+	return s.kewajibanWajibRepo.GetByUser(userID)
+}
+
+// GetTunggakanWajib returns a member's overdue simpanan wajib. Members can only
+// see their own.
+func (s *SimpananService) GetTunggakanWajib(userID uint, requestorID uint, requestorRole string) (*TunggakanWajib, error) {
+	if requestorRole != "super_admin" && requestorRole != "admin" && requestorID != userID {
+		return nil, errors.New("forbidden")
+	}
+	return hitungTunggakanWajib(s.kewajibanWajibRepo, userID, time.Now())
+}
+
+// GetRekapTunggakanWajib returns the overdue simpanan wajib of every member (admin only)
+func (s *SimpananService) GetRekapTunggakanWajib(requestorRole string) (*RekapTunggakanWajib, error) {
+	if requestorRole != "super_admin" && requestorRole != "admin" {
+		return nil, errors.New("forbidden")
+	}
+
+	now := time.Now()
+	rows, err := s.kewajibanWajibRepo.GetRekapTunggakan(awalHari(now))
+	if err != nil {
+		return nil, err
+	}
+	rekap := &RekapTunggakanWajib{Tanggal: now, JumlahAnggota: len(rows), Anggota: rows}
+	for _, r := range rows {
+		rekap.JumlahBulan += r.JumlahBulan
+		rekap.TotalTunggakan += r.TotalTunggakan
+	}
+	return rekap, nil
+}
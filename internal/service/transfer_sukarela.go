@@ -0,0 +1,409 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koperasi-service/internal/model"
+	"koperasi-service/internal/repository"
+	"koperasi-service/pkg/money"
+	"time"
+)
+
+// batasKonfirmasiTransfer is how long a created transfer can be confirmed
+const batasKonfirmasiTransfer = 15 * time.Minute
+
+// TransferInput is what a member fills in to send sukarela to another member
+type TransferInput struct {
+	EmailPenerima string
+	Jumlah        money.Money
+	Keterangan    string
+}
+
+// cekAturanTransfer checks jumlah against the per-transfer limits of the rule
+func cekAturanTransfer(aturan *model.AturanTransfer, jumlah money.Money) error {
+	if aturan == nil {
+		return nil
+	}
+	if jumlah < aturan.MinimalTransfer {
+		return fmt.Errorf("jumlah must be at least %s", aturan.MinimalTransfer)
+	}
+	if aturan.BatasPerTransaksi > 0 && jumlah > aturan.BatasPerTransaksi {
+		return fmt.Errorf("jumlah must not exceed %s per transfer", aturan.BatasPerTransaksi)
+	}
+	return nil
+}
+
+// cekBatasHarian checks that sending jumlah keeps the member within the
+// rule's daily limit
+func cekBatasHarian(repo *repository.TransferSukarelaRepository, aturan *model.AturanTransfer, pengirimID uint, jumlah money.Money, now time.Time) error {
+	if aturan == nil || aturan.BatasHarian <= 0 {
+		return nil
+	}
+	hari := awalHari(now)
+	terkirim, err := repo.SumTerkirim(pengirimID, hari, hari.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+	if terkirim+jumlah > aturan.BatasHarian {
+		return fmt.Errorf("daily transfer limit of %s exceeded, %s left today", aturan.BatasHarian, money.Max(aturan.BatasHarian-terkirim, 0))
+	}
+	return nil
+}
+
+// perluPersetujuan reports whether a transfer of jumlah needs an admin
+func perluPersetujuan(aturan *model.AturanTransfer, jumlah money.Money) bool {
+	return aturan != nil && aturan.BatasPersetujuan > 0 && jumlah >= aturan.BatasPersetujuan
+}
+
+// BuatTransfer creates a sukarela transfer to another active anggota. Nothing
+// moves until the sender confirms it with KonfirmasiTransfer; the returned
+// transfer shows the recipient's name and whether an admin must approve it.
+func (s *SimpananService) BuatTransfer(userID uint, input TransferInput) (*model.TransferSukarela, error) {
+	if input.Jumlah <= 0 {
+		return nil, errors.New("jumlah must be positive")
+	}
+	if input.EmailPenerima == "" {
+		return nil, errors.New("email penerima is required")
+	}
+
+	pengirim, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if pengirim.StatusKeanggotaan != model.StatusAnggotaAktif {
+		return nil, errors.New("only an active anggota can transfer")
+	}
+	penerima, err := s.userRepo.FindByEmail(input.EmailPenerima)
+	if err != nil {
+		return nil, errors.New("penerima not found")
+	}
+	if penerima.ID == userID {
+		return nil, errors.New("cannot transfer to yourself")
+	}
+	if penerima.StatusKeanggotaan != model.StatusAnggotaAktif {
+		return nil, errors.New("penerima is not an active anggota")
+	}
+
+	aturan, err := s.aturanTransferRepo.GetActive()
+	if err != nil {
+		return nil, err
+	}
+	if err := cekAturanTransfer(aturan, input.Jumlah); err != nil {
+		return nil, err
+	}
+	dari, err := s.repo.GetWalletByUserAndType(userID, "sukarela")
+	if err != nil {
+		return nil, errors.New("wallet not found")
+	}
+	ke, err := s.repo.GetWalletByUserAndType(penerima.ID, "sukarela")
+	if err != nil {
+		return nil, errors.New("wallet not found")
+	}
+	bebas, err := s.saldoBebas(dari)
+	if err != nil {
+		return nil, err
+	}
+	if bebas < input.Jumlah {
+		return nil, errors.New("insufficient balance")
+	}
+	now := time.Now()
+	if err := cekBatasHarian(s.transferRepo, aturan, userID, input.Jumlah, now); err != nil {
+		return nil, err
+	}
+
+	t := &model.TransferSukarela{
+		PengirimID:         userID,
+		PenerimaID:         penerima.ID,
+		SimpananPengirimID: dari.ID,
+		SimpananPenerimaID: ke.ID,
+		NamaPengirim:       pengirim.Name,
+		NamaPenerima:       penerima.Name,
+		Jumlah:             input.Jumlah,
+		Keterangan:         input.Keterangan,
+		Status:             model.TransferMenungguKonfirmasi,
+		PerluPersetujuan:   perluPersetujuan(aturan, input.Jumlah),
+		BatasKonfirmasi:    now.Add(batasKonfirmasiTransfer),
+	}
+	if err := s.transferRepo.Create(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// KonfirmasiTransfer confirms a transfer the member created. The limits and
+// the balance are checked again; below the approval threshold the money moves
+// at once, otherwise it is held on the sender's wallet until an admin decides.
+func (s *SimpananService) KonfirmasiTransfer(userID uint, id uint) (*model.TransferSukarela, error) {
+	t0, err := s.transferRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if t0.PengirimID != userID {
+		return nil, errors.New("forbidden")
+	}
+	aturan, err := s.aturanTransferRepo.GetActive()
+	if err != nil {
+		return nil, err
+	}
+
+	var result *model.TransferSukarela
+	err = s.uow.Do(func(repos *repository.Repositories) error {
+		wallets, err := lockWallets(repos, t0.SimpananPengirimID, t0.SimpananPenerimaID)
+		if err != nil {
+			return err
+		}
+		dari, ke := wallets[t0.SimpananPengirimID], wallets[t0.SimpananPenerimaID]
+
+		t, err := repos.Transfer.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if t.Status != model.TransferMenungguKonfirmasi {
+			return fmt.Errorf("transfer can only be confirmed when menunggu_konfirmasi, it is %s", t.Status)
+		}
+		now := time.Now()
+		if now.After(t.BatasKonfirmasi) {
+			return errors.New("transfer confirmation has expired")
+		}
+		if err := cekPenerimaAktif(repos, t); err != nil {
+			return err
+		}
+		if err := cekAturanTransfer(aturan, t.Jumlah); err != nil {
+			return err
+		}
+		if err := cekBatasHarian(repos.Transfer, aturan, t.PengirimID, t.Jumlah, now); err != nil {
+			return err
+		}
+		bebas, err := s.saldoBebas(dari)
+		if err != nil {
+			return err
+		}
+		if bebas < t.Jumlah {
+			return errors.New("insufficient balance")
+		}
+
+		t.DikonfirmasiPada = &now
+		t.PerluPersetujuan = perluPersetujuan(aturan, t.Jumlah)
+		result = t
+		if !t.PerluPersetujuan {
+			return kirimTransfer(repos, t, dari, ke, nil, now)
+		}
+		dari.SaldoDitahan += t.Jumlah
+		if err := repos.Simpanan.UpdateWallet(dari); err != nil {
+			return err
+		}
+		t.Status = model.TransferMenungguPersetujuan
+		return repos.Transfer.Update(t)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// cekPenerimaAktif refuses a transfer whose recipient has left the koperasi
+func cekPenerimaAktif(repos *repository.Repositories, t *model.TransferSukarela) error {
+	penerima, err := repos.Users.FindByID(t.PenerimaID)
+	if err != nil {
+		return err
+	}
+	if penerima.StatusKeanggotaan != model.StatusAnggotaAktif {
+		return errors.New("penerima is not an active anggota")
+	}
+	return nil
+}
+
+// kirimTransfer moves a transfer's money between the two sukarela wallets,
+// locked by the caller, and tells the recipient
+func kirimTransfer(repos *repository.Repositories, t *model.TransferSukarela, dari, ke *model.Simpanan, postedBy *uint, now time.Time) error {
+	keluar, masuk, err := postWalletLegs(repos, dari, ke, walletLegs{
+		Type:              "transfer",
+		EntryType:         model.LedgerTransfer,
+		Amount:            t.Jumlah,
+		DescriptionKeluar: "Transfer ke " + t.NamaPenerima,
+		DescriptionMasuk:  "Transfer dari " + t.NamaPengirim,
+		PostedBy:          postedBy,
+		PostedAt:          now,
+	})
+	if err != nil {
+		return err
+	}
+
+	t.Status = model.TransferBerhasil
+	t.TransaksiKeluarID = &keluar.ID
+	t.TransaksiMasukID = &masuk.ID
+	t.TanggalSelesai = &now
+	if err := repos.Transfer.Update(t); err != nil {
+		return err
+	}
+	return repos.Notifikasi.Create(&model.Notifikasi{
+		UserID:         t.PenerimaID,
+		Jenis:          model.NotifikasiTransferMasuk,
+		Judul:          "Transfer masuk",
+		Pesan:          fmt.Sprintf("Anda menerima transfer Rp %s dari %s ke simpanan sukarela.", t.Jumlah, t.NamaPengirim),
+		ReferenceTable: "transfer_sukarela",
+		ReferenceID:    t.ID,
+	})
+}
+
+// SetujuiTransfer approves a transfer waiting for an admin and moves the held
+// money to the recipient (admin only)
+func (s *SimpananService) SetujuiTransfer(adminID uint, adminRole string, id uint) (*model.TransferSukarela, error) {
+	if adminRole != "super_admin" && adminRole != "admin" {
+		return nil, errors.New("forbidden")
+	}
+	t0, err := s.transferRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *model.TransferSukarela
+	err = s.uow.Do(func(repos *repository.Repositories) error {
+		wallets, err := lockWallets(repos, t0.SimpananPengirimID, t0.SimpananPenerimaID)
+		if err != nil {
+			return err
+		}
+		dari, ke := wallets[t0.SimpananPengirimID], wallets[t0.SimpananPenerimaID]
+
+		t, err := repos.Transfer.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if t.Status != model.TransferMenungguPersetujuan {
+			return fmt.Errorf("transfer can only be approved when menunggu_persetujuan, it is %s", t.Status)
+		}
+		if err := cekPenerimaAktif(repos, t); err != nil {
+			return err
+		}
+
+		dari.SaldoDitahan -= t.Jumlah
+		t.DisetujuiOleh = &adminID
+		result = t
+		return kirimTransfer(repos, t, dari, ke, &adminID, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TolakTransfer rejects a transfer waiting for an admin and releases the held
+// amount (admin only)
+func (s *SimpananService) TolakTransfer(adminID uint, adminRole string, id uint, alasan string) (*model.TransferSukarela, error) {
+	if adminRole != "super_admin" && adminRole != "admin" {
+		return nil, errors.New("forbidden")
+	}
+	if alasan == "" {
+		return nil, errors.New("alasan penolakan is required")
+	}
+	t0, err := s.transferRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *model.TransferSukarela
+	err = s.uow.Do(func(repos *repository.Repositories) error {
+		dari, err := repos.Simpanan.GetWalletByIDForUpdate(t0.SimpananPengirimID)
+		if err != nil {
+			return err
+		}
+		t, err := repos.Transfer.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if t.Status != model.TransferMenungguPersetujuan {
+			return fmt.Errorf("transfer can only be rejected when menunggu_persetujuan, it is %s", t.Status)
+		}
+
+		dari.SaldoDitahan -= t.Jumlah
+		if err := repos.Simpanan.UpdateWallet(dari); err != nil {
+			return err
+		}
+		now := time.Now()
+		t.Status = model.TransferDitolak
+		t.DitolakOleh = &adminID
+		t.AlasanPenolakan = alasan
+		t.TanggalSelesai = &now
+		if err := repos.Transfer.Update(t); err != nil {
+			return err
+		}
+		result = t
+		return repos.Notifikasi.Create(&model.Notifikasi{
+			UserID:         t.PengirimID,
+			Jenis:          model.NotifikasiTransferDitolak,
+			Judul:          "Transfer ditolak",
+			Pesan:          fmt.Sprintf("Transfer Rp %s ke %s ditolak: %s", t.Jumlah, t.NamaPenerima, alasan),
+			ReferenceTable: "transfer_sukarela",
+			ReferenceID:    t.ID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// BatalkanTransfer lets the sender cancel a transfer that has not moved yet,
+// releasing the held amount of one waiting for an admin
+func (s *SimpananService) BatalkanTransfer(userID uint, id uint) (*model.TransferSukarela, error) {
+	t0, err := s.transferRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if t0.PengirimID != userID {
+		return nil, errors.New("forbidden")
+	}
+
+	var result *model.TransferSukarela
+	err = s.uow.Do(func(repos *repository.Repositories) error {
+		dari, err := repos.Simpanan.GetWalletByIDForUpdate(t0.SimpananPengirimID)
+		if err != nil {
+			return err
+		}
+		t, err := repos.Transfer.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		switch t.Status {
+		case model.TransferMenungguKonfirmasi:
+		case model.TransferMenungguPersetujuan:
+			dari.SaldoDitahan -= t.Jumlah
+			if err := repos.Simpanan.UpdateWallet(dari); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("transfer can only be cancelled before it is moved, it is %s", t.Status)
+		}
+
+		now := time.Now()
+		t.Status = model.TransferDibatalkan
+		t.TanggalSelesai = &now
+		result = t
+		return repos.Transfer.Update(t)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListTransfer returns sukarela transfers, newest first. Members only see the
+// ones they sent or received; admins see everyone's unless userID is set.
+func (s *SimpananService) ListTransfer(requestorID uint, requestorRole string, userID uint, status string) ([]model.TransferSukarela, error) {
+	if requestorRole != "super_admin" && requestorRole != "admin" {
+		userID = requestorID
+	}
+	return s.transferRepo.List(userID, status)
+}
+
+// GetTransfer returns one transfer; members can only see the ones they sent or received
+func (s *SimpananService) GetTransfer(requestorID uint, requestorRole string, id uint) (*model.TransferSukarela, error) {
+	t, err := s.transferRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if requestorRole != "super_admin" && requestorRole != "admin" && t.PengirimID != requestorID && t.PenerimaID != requestorID {
+		return nil, errors.New("forbidden")
+	}
+	return t, nil
+}
@@ -10,13 +10,13 @@ import (
 
 // UserService handles user CRUD with role constraints.
 type UserService struct {
-	repo         *repository.UserRepository
-	simpananRepo *repository.SimpananRepository
+	repo *repository.UserRepository
+	uow  *repository.UnitOfWork
 }
 
 // NewUserService constructs a new UserService.
-func NewUserService(repo *repository.UserRepository, simpananRepo *repository.SimpananRepository) *UserService {
-	return &UserService{repo: repo, simpananRepo: simpananRepo}
+func NewUserService(repo *repository.UserRepository, uow *repository.UnitOfWork) *UserService {
+	return &UserService{repo: repo, uow: uow}
 }
 
 // ListUsers returns all users; only super_admin can list all, admin can list their registered users.
@@ -78,18 +78,35 @@ func (s *UserService) CreateUser(requestorID uint, requestorRole string, u *mode
 		u.Password = string(h)
 	}
 
-	// Create user first
-	if err := s.repo.Create(u); err != nil {
-		return err
-	}
+	// Create the user and its wallets together; a wallet failure rolls back the user
+	return s.uow.Do(func(repos *repository.Repositories) error {
+		if err := setStatusKeanggotaanAwal(repos, u); err != nil {
+			return err
+		}
+		if err := repos.Users.Create(u); err != nil {
+			return err
+		}
 
-	// Initialize user wallets (3 types)
-	if err := s.simpananRepo.InitializeUserWallets(u.ID); err != nil {
-		// If wallet initialization fails, we should rollback user creation
-		// For now, we'll just return the error
-		return err
-	}
+		// Initialize user wallets (one per active jenis simpanan)
+		return repos.Simpanan.InitializeUserWallets(u.ID)
+	})
+}
 
+// setStatusKeanggotaanAwal sets the membership state of a new user. Members
+// start as calon anggota until their simpanan pokok is verified; other roles
+// are not members and start aktif.
+func setStatusKeanggotaanAwal(repos *repository.Repositories, u *model.User) error {
+	u.StatusKeanggotaan = model.StatusAnggotaAktif
+	if u.RoleID == 0 {
+		return nil
+	}
+	role, err := repos.Users.FindRoleByID(u.RoleID)
+	if err != nil {
+		return errors.New("role not found")
+	}
+	if role.Name == "member" {
+		u.StatusKeanggotaan = model.StatusCalonAnggota
+	}
 	return nil
 }
 
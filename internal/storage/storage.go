@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrNotFound is returned when an object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// Storage keeps uploaded files as objects addressed by key. Keys are
+// slash-separated paths such as "bukti/ab/ab12…cd.jpg".
+type Storage interface {
+	Put(key string, data []byte, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Exists(key string) (bool, error)
+	Delete(key string) error
+}
+
+// Config selects and configures a storage backend
+type Config struct {
+	Driver string // "local" (default) or "s3"
+
+	// Local filesystem
+	Dir string // Root directory, default "uploads"
+
+	// S3-compatible API (AWS S3, MinIO, ...)
+	Endpoint  string // e.g. http://localhost:9000
+	Region    string // Default us-east-1
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+// New returns the backend selected by cfg.Driver
+func New(cfg Config) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		dir := cfg.Dir
+		if dir == "" {
+			dir = "uploads"
+		}
+		return NewLocalStorage(dir)
+	case "s3":
+		return NewS3Storage(cfg.Endpoint, cfg.Region, cfg.Bucket, cfg.AccessKey, cfg.SecretKey)
+	}
+	return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+}
+
+// checkKey refuses keys that could escape the storage root
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid object key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid object key %q", key)
+		}
+	}
+	return nil
+}
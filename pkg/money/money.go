@@ -0,0 +1,263 @@
+// Package money provides an exact fixed-point type for rupiah amounts.
+//
+// Rounding rules:
+//   - Stored and parsed amounts keep two decimals (sen), matching the
+//     decimal(15,2) columns; extra decimals are rounded half-up.
+//   - Calculated amounts (percentages, ratios) are rounded half-up to the
+//     whole rupiah. Half-up rounds away from zero for negative amounts.
+//   - Pro-rata splits use Allocate, which hands out the rounding remainder so
+//     the parts always add up to the rounded total.
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in sen (1/100 rupiah).
+type Money int64
+
+// Rupiah is one rupiah.
+const Rupiah Money = 100
+
+// Zero is the zero amount.
+const Zero Money = 0
+
+// FromRupiah returns an amount of whole rupiah.
+func FromRupiah(rupiah int64) Money {
+	return Money(rupiah) * Rupiah
+}
+
+// FromFloat converts a float amount, rounding half-up to the sen.
+// Only use it at boundaries that still deliver floats.
+func FromFloat(f float64) Money {
+	return Money(math.Round(f * 100))
+}
+
+// Parse reads a decimal string such as "1500000", "-25000.5" or "0.125".
+// More than two decimals are rounded half-up to the sen.
+func Parse(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || strings.ContainsAny(s, "/") {
+		return 0, fmt.Errorf("invalid money amount %q", s)
+	}
+	sen := roundHalfUp(new(big.Rat).Mul(r, big.NewRat(100, 1)))
+	if !sen.IsInt64() {
+		return 0, fmt.Errorf("money amount %q out of range", s)
+	}
+	return Money(sen.Int64()), nil
+}
+
+// Float64 returns the amount in rupiah as a float, for display or ratios only.
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+// String formats the amount with two decimals, e.g. "1500000.50".
+func (m Money) String() string {
+	sign := ""
+	v := int64(m)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/100, v%100)
+}
+
+// RoundRupiah rounds half-up to the whole rupiah.
+func (m Money) RoundRupiah() Money {
+	return Money(roundHalfUp(big.NewRat(int64(m), 100)).Int64()) * Rupiah
+}
+
+// Abs returns the absolute amount.
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// Min returns the smaller of a and b.
+func Min(a, b Money) Money {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Max returns the larger of a and b.
+func Max(a, b Money) Money {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// MulPercent returns m * percent / 100 rounded half-up to the rupiah.
+// The percentage is read from its shortest decimal form, so rates such as
+// 2.5 (decimal(5,2) columns) are applied exactly.
+func (m Money) MulPercent(percent float64) Money {
+	p, _ := new(big.Rat).SetString(strconv.FormatFloat(percent, 'f', -1, 64))
+	r := new(big.Rat).Mul(big.NewRat(int64(m), 100), p)
+	r.Quo(r, big.NewRat(100, 1))
+	return Money(roundHalfUp(r).Int64()) * Rupiah
+}
+
+// MulPercentTimes returns m * percent * n / 100 rounded half-up to the rupiah,
+// such as a daily rate charged for n days. The rate is read like MulPercent
+// and multiplied by n exactly, before any rounding.
+func (m Money) MulPercentTimes(percent float64, n int64) Money {
+	p, _ := new(big.Rat).SetString(strconv.FormatFloat(percent, 'f', -1, 64))
+	r := new(big.Rat).Mul(big.NewRat(int64(m), 100), p)
+	r.Mul(r, big.NewRat(n, 100))
+	return Money(roundHalfUp(r).Int64()) * Rupiah
+}
+
+// MulFactor returns m * factor rounded half-up to the rupiah. Like
+// MulPercent the factor is read from its shortest decimal form.
+func (m Money) MulFactor(factor float64) Money {
+	f, _ := new(big.Rat).SetString(strconv.FormatFloat(factor, 'f', -1, 64))
+	r := new(big.Rat).Mul(big.NewRat(int64(m), 100), f)
+	return Money(roundHalfUp(r).Int64()) * Rupiah
+}
+
+// MulRatio returns m * num / den rounded half-up to the rupiah.
+// A zero denominator yields zero.
+func (m Money) MulRatio(num, den int64) Money {
+	if den == 0 {
+		return 0
+	}
+	r := new(big.Rat).Mul(big.NewRat(int64(m), 100), big.NewRat(num, den))
+	return Money(roundHalfUp(r).Int64()) * Rupiah
+}
+
+// Allocate splits m in proportion to weights[i] / total and returns one part
+// per weight in whole rupiah. The parts add up to m * sum(weights) / total
+// rounded half-up to the rupiah; the rounding remainder is handed out one
+// rupiah at a time to the largest fractional parts (ties go to the earlier
+// weight). A zero total yields zero parts. m must not be negative.
+func (m Money) Allocate(weights []Money, total Money) []Money {
+	parts := make([]Money, len(weights))
+	if total == 0 || m <= 0 {
+		return parts
+	}
+
+	type share struct {
+		index    int
+		fraction *big.Rat
+	}
+	shares := make([]share, 0, len(weights))
+	sumWeights := int64(0)
+	allocated := int64(0)
+	for i, w := range weights {
+		sumWeights += int64(w)
+		// exact share in rupiah
+		exact := new(big.Rat).Mul(big.NewRat(int64(m), 100), big.NewRat(int64(w), int64(total)))
+		floor := new(big.Int).Quo(exact.Num(), exact.Denom())
+		parts[i] = Money(floor.Int64()) * Rupiah
+		allocated += floor.Int64()
+		fraction := new(big.Rat).Sub(exact, new(big.Rat).SetInt(floor))
+		shares = append(shares, share{index: i, fraction: fraction})
+	}
+
+	target := new(big.Rat).Mul(big.NewRat(int64(m), 100), big.NewRat(sumWeights, int64(total)))
+	remainder := roundHalfUp(target).Int64() - allocated
+
+	sort.SliceStable(shares, func(a, b int) bool {
+		return shares[a].fraction.Cmp(shares[b].fraction) > 0
+	})
+	for i := 0; remainder > 0 && len(shares) > 0; i = (i + 1) % len(shares) {
+		parts[shares[i].index] += Rupiah
+		remainder--
+	}
+	return parts
+}
+
+// MarshalJSON writes the amount as a JSON number, e.g. 1500000 or 1500000.5.
+func (m Money) MarshalJSON() ([]byte, error) {
+	s := m.String()
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "" || s == "-" {
+		s = "0"
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalJSON reads a JSON number or a quoted decimal string without going
+// through float64.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// Value implements driver.Valuer; amounts are stored as decimal strings.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan implements sql.Scanner for numeric columns.
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+		return nil
+	case string:
+		parsed, err := Parse(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case []byte:
+		parsed, err := Parse(string(v))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case int64:
+		*m = FromRupiah(v)
+		return nil
+	case float64:
+		*m = FromFloat(v)
+		return nil
+	}
+	return errors.New("unsupported type for money amount")
+}
+
+// GormDataType makes money columns decimal(15,2) unless a tag says otherwise.
+func (Money) GormDataType() string {
+	return "decimal(15,2)"
+}
+
+// roundHalfUp rounds r to the nearest integer, halves away from zero.
+func roundHalfUp(r *big.Rat) *big.Int {
+	num := new(big.Int).Abs(r.Num())
+	den := r.Denom()
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if new(big.Int).Mul(rem, big.NewInt(2)).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		q.Neg(q)
+	}
+	return q
+}
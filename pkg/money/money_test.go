@@ -0,0 +1,267 @@
+package money
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		{in: "1500000", want: 150000000},
+		{in: "-25000.5", want: -2500050},
+		{in: " 10 ", want: 1000},
+		{in: "0.124", want: 12},
+		{in: "0.125", want: 13},
+		{in: "-0.125", want: -13},
+		{in: "1500000.999", want: 150000100},
+		{in: "92233720368547758.07", want: 9223372036854775807},
+		{in: "", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "12,5", wantErr: true},
+		{in: "1/2", wantErr: true},
+		{in: "92233720368547758.08", wantErr: true},
+		{in: "1e30", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Parse(%q) = %d, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestRoundHalfUp(t *testing.T) {
+	tests := []struct {
+		num, den int64
+		want     int64
+	}{
+		{5, 2, 3},
+		{-5, 2, -3},
+		{-1, 2, -1},
+		{-7, 3, -2},
+		{-8, 3, -3},
+		{7, 3, 2},
+		{0, 1, 0},
+	}
+	for _, tt := range tests {
+		if got := roundHalfUp(big.NewRat(tt.num, tt.den)).Int64(); got != tt.want {
+			t.Errorf("roundHalfUp(%d/%d) = %d, want %d", tt.num, tt.den, got, tt.want)
+		}
+	}
+}
+
+func TestMulPercent(t *testing.T) {
+	tests := []struct {
+		m       Money
+		percent float64
+		want    Money
+	}{
+		{FromRupiah(1000), 2.5, FromRupiah(25)},
+		{FromRupiah(1001), 2.5, FromRupiah(25)},
+		{FromRupiah(1020), 2.5, FromRupiah(26)},
+		{FromRupiah(-1020), 2.5, FromRupiah(-26)},
+		{FromRupiah(1000), 0.1, FromRupiah(1)},
+		{FromRupiah(1000), 0, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.m.MulPercent(tt.percent); got != tt.want {
+			t.Errorf("%s.MulPercent(%v) = %s, want %s", tt.m, tt.percent, got, tt.want)
+		}
+	}
+}
+
+func TestMulPercentTimes(t *testing.T) {
+	tests := []struct {
+		m       Money
+		percent float64
+		n       int64
+		want    Money
+	}{
+		{FromRupiah(500000), 0.1, 30, FromRupiah(15000)},
+		// 0.333 a day rounds to nothing, but three days together make 0.999
+		{FromRupiah(333), 0.1, 3, FromRupiah(1)},
+		{FromRupiah(1000), 2.5, 1, FromRupiah(25)},
+		{FromRupiah(1000), 0.1, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.m.MulPercentTimes(tt.percent, tt.n); got != tt.want {
+			t.Errorf("%s.MulPercentTimes(%v, %d) = %s, want %s", tt.m, tt.percent, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestMulRatio(t *testing.T) {
+	tests := []struct {
+		m        Money
+		num, den int64
+		want     Money
+	}{
+		{FromRupiah(100), 1, 3, FromRupiah(33)},
+		{FromRupiah(100), 2, 3, FromRupiah(67)},
+		{FromRupiah(1), 1, 2, FromRupiah(1)},
+		{FromRupiah(100), 1, 0, 0},
+		{FromRupiah(100), 0, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.m.MulRatio(tt.num, tt.den); got != tt.want {
+			t.Errorf("%s.MulRatio(%d, %d) = %s, want %s", tt.m, tt.num, tt.den, got, tt.want)
+		}
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       Money
+		weights []Money
+		total   Money
+		want    []Money
+	}{
+		{
+			name:    "ties go to the earlier weight",
+			m:       FromRupiah(100),
+			weights: []Money{1, 1, 1},
+			total:   3,
+			want:    []Money{FromRupiah(34), FromRupiah(33), FromRupiah(33)},
+		},
+		{
+			name:    "remainder goes to the largest fraction",
+			m:       FromRupiah(100),
+			weights: []Money{1, 2},
+			total:   3,
+			want:    []Money{FromRupiah(33), FromRupiah(67)},
+		},
+		{
+			name:    "several remainders",
+			m:       FromRupiah(10),
+			weights: []Money{1, 1, 1, 1, 1, 1},
+			total:   6,
+			want:    []Money{FromRupiah(2), FromRupiah(2), FromRupiah(2), FromRupiah(2), FromRupiah(1), FromRupiah(1)},
+		},
+		{
+			name:    "weights below the total add up to the rounded target",
+			m:       FromRupiah(100),
+			weights: []Money{1, 1},
+			total:   3,
+			want:    []Money{FromRupiah(34), FromRupiah(33)},
+		},
+		{
+			name:    "zero total",
+			m:       FromRupiah(100),
+			weights: []Money{1, 2},
+			total:   0,
+			want:    []Money{0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.m.Allocate(tt.weights, tt.total)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Allocate = %v, want %v", got, tt.want)
+			}
+			var sum, sumWeights int64
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Allocate = %v, want %v", got, tt.want)
+					break
+				}
+				sum += int64(got[i])
+				sumWeights += int64(tt.weights[i])
+			}
+			if tt.total == 0 {
+				return
+			}
+			target := FromRupiah(roundHalfUp(new(big.Rat).Mul(big.NewRat(int64(tt.m), 100), big.NewRat(sumWeights, int64(tt.total)))).Int64())
+			if Money(sum) != target {
+				t.Errorf("parts add up to %s, want %s", Money(sum), target)
+			}
+		})
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		m    Money
+		json string
+	}{
+		{0, "0"},
+		{150000000, "1500000"},
+		{150000050, "1500000.5"},
+		{-2500050, "-25000.5"},
+		{1, "0.01"},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.m)
+		if err != nil || string(data) != tt.json {
+			t.Errorf("Marshal(%d) = %s, %v, want %s", tt.m, data, err, tt.json)
+			continue
+		}
+		var got Money
+		if err := json.Unmarshal(data, &got); err != nil || got != tt.m {
+			t.Errorf("Unmarshal(%s) = %d, %v, want %d", data, got, err, tt.m)
+		}
+	}
+
+	var quoted Money
+	if err := json.Unmarshal([]byte(`"1500000.50"`), &quoted); err != nil || quoted != 150000050 {
+		t.Errorf("Unmarshal quoted = %d, %v", quoted, err)
+	}
+	unchanged := Money(700)
+	if err := json.Unmarshal([]byte("null"), &unchanged); err != nil || unchanged != 700 {
+		t.Errorf("Unmarshal null = %d, %v", unchanged, err)
+	}
+	if err := json.Unmarshal([]byte(`"abc"`), &quoted); err == nil {
+		t.Error("Unmarshal invalid string: want error")
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		src     interface{}
+		want    Money
+		wantErr bool
+	}{
+		{src: "1500000.50", want: 150000050},
+		{src: []byte("12.345"), want: 1235},
+		{src: int64(7), want: 700},
+		{src: float64(1.5), want: 150},
+		{src: nil, want: 0},
+		{src: "abc", wantErr: true},
+		{src: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		got := Money(99)
+		err := got.Scan(tt.src)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Scan(%v) = %d, want error", tt.src, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Scan(%v) = %d, %v, want %d", tt.src, got, err, tt.want)
+		}
+	}
+
+	for _, m := range []Money{0, 1, -2500050, 150000050} {
+		v, err := m.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Money
+		if err := got.Scan(v); err != nil || got != m {
+			t.Errorf("Scan(Value(%d)) = %d, %v", m, got, err)
+		}
+	}
+}